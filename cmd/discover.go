@@ -1,19 +1,26 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"path/filepath"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/valllabh/domain-scan/pkg/discovery"
 	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/logging"
+	"github.com/valllabh/domain-scan/pkg/output"
+	"github.com/valllabh/domain-scan/pkg/store"
 	"github.com/valllabh/domain-scan/pkg/utils"
+	"golang.org/x/term"
 )
 
 // DomainResult represents the structured output format for domains.json
@@ -29,8 +36,14 @@ var (
 	threads          int
 	outputFile       string
 	outputFormat     string
+	outputFilter     string
+	outputSort       string
+	outputFields     string
 	resultDir        string
 	quiet            bool
+	noColor          bool
+	dbPath           string
+	fullScreen       bool
 	debug            bool
 	logLevel         string
 	disablePassive   bool
@@ -39,11 +52,29 @@ var (
 	recursionDepth   int
 	maxDomains       int
 	sources          []string
+	listFile         string
+	ipPivot          bool
+	ipPivotPrefix    int
+	activeEnum       bool
+	crawlContent     bool
+	wordlist         string
+	proxyURL         string
+	headers          []string
+	userAgent        string
+	sniName          string
+	caBundle         string
+	redirectPolicy   string
+	maxRedirects     int
+	redirectDiscover bool
+	insecure         bool
+	verifyTLS        bool
+	notifyDryRunScan bool
+	resultLayout     string
 )
 
 // discoverCmd represents the discover command
 var discoverCmd = &cobra.Command{
-	Use:   "discover [domains...]",
+	Use:   "discover [domains|IPs|CIDRs...]",
 	Short: "Discover web assets for specified domains",
 	Long: `Discover performs comprehensive web asset discovery including:
 - Passive subdomain enumeration using subfinder
@@ -52,6 +83,11 @@ var discoverCmd = &cobra.Command{
 
 The results include all discovered subdomains and active web services.
 
+Targets can be given as arguments, read from a file with --list, or piped on
+stdin (one per line). IP addresses and CIDR ranges are accepted as seeds: TLS
+certificates served on those IPs are grabbed and their SAN domains become the
+starting domain set.
+
 Keywords are used to filter domains found in SSL certificates to exclude domains 
 from other organizations in shared certificates. For example, if a target domain 
 uses a third-party service that has other organizations' domains in the same 
@@ -63,14 +99,28 @@ certificate, keywords ensure only relevant domains are included in results.`,
   domain-scan discover example.com domain2.com
 
   # Additional keywords (combined with auto-extracted)
-  domain-scan discover example.com --keywords staging,prod
+  domain-scan discover example.com --keywords staging --keywords prod
 
   # Output to file in JSON format
   domain-scan discover example.com --output results.json --format json
 
+  # Stream one entry per line into jq, or write an HTML report
+  domain-scan discover example.com --format jsonl | jq -r 'select(.reachable) | .url'
+  domain-scan discover example.com --format html --output report.html
+
   # Multiple domains with custom settings
-  domain-scan discover example.com domain2.com --max-subdomains 500`,
-	Args: cobra.MinimumNArgs(1),
+  domain-scan discover example.com domain2.com --max-subdomains 500
+
+  # Read targets from a file or another tool's output
+  domain-scan discover --list targets.txt
+  subfinder -d example.com -silent | domain-scan discover
+
+  # Seed discovery from certificates served on a netblock
+  domain-scan discover 203.0.113.0/24
+
+  # Scan through a SOCKS5 proxy with an allow-listed User-Agent
+  domain-scan discover example.com --proxy socks5://127.0.0.1:1080 --user-agent "AcmeScanner/1.0" -H "X-Scan-Token: abc"`,
+	Args: cobra.ArbitraryArgs,
 	RunE: runDiscover,
 }
 
@@ -78,7 +128,8 @@ func init() {
 	rootCmd.AddCommand(discoverCmd)
 
 	// Discovery flags
-	discoverCmd.Flags().StringSliceVarP(&keywords, "keywords", "k", []string{}, "Additional keywords for filtering SSL certificate domains (auto-extracted from domains and combined with provided keywords)")
+	discoverCmd.Flags().StringVarP(&listFile, "list", "l", "", "File with targets (domains, IPs or CIDRs), one per line; use - for stdin")
+	discoverCmd.Flags().StringArrayVarP(&keywords, "keywords", "k", []string{}, "Additional keywords for filtering SSL certificate domains, repeatable or comma-separated (auto-extracted from domains and combined with provided keywords; supports =label, /regex/, ~brand and !exclusion, see 'keywords --help')")
 	discoverCmd.Flags().IntVar(&maxSubdomains, "max-subdomains", 0, "Maximum subdomains to scan for HTTP services")
 	discoverCmd.Flags().IntVar(&timeout, "timeout", 0, "Timeout in seconds")
 	discoverCmd.Flags().IntVar(&threads, "threads", 0, "Number of threads")
@@ -90,12 +141,37 @@ func init() {
 	discoverCmd.Flags().IntVar(&recursionDepth, "recursion-depth", 0, "Maximum recursion depth for certificate discovery (0 = unlimited, use with --no-recursive=false)")
 	discoverCmd.Flags().IntVar(&maxDomains, "max-domains", 0, "Maximum number of domains to discover (0 = unlimited, stops discovery when limit reached)")
 	discoverCmd.Flags().StringSliceVar(&sources, "sources", []string{}, "Specific subfinder sources to use (empty = all sources, see 'sources list' command)")
+	discoverCmd.Flags().BoolVar(&ipPivot, "ip-pivot", false, "Expand resolved IPs to their network and discover names via PTR lookups and TLS grabs")
+	discoverCmd.Flags().BoolVar(&activeEnum, "active", false, "Enable active enumeration: resolve permutations (dev-, -staging, number increments) of discovered names (DNS queries use the system resolver, not --proxy)")
+	discoverCmd.Flags().BoolVar(&crawlContent, "crawl", false, "Crawl live pages for hostnames referenced by links, JavaScript bundles, CSP and CORS headers")
+	discoverCmd.Flags().StringVar(&wordlist, "wordlist", "", "Wordlist file for active brute-force against discovered apex domains (requires --active)")
+	discoverCmd.Flags().IntVar(&ipPivotPrefix, "ip-pivot-prefix", 24, "IPv4 prefix length swept around each resolved IP (16-32, 32 = resolved IP only)")
+
+	// HTTP client flags
+	discoverCmd.Flags().StringVar(&proxyURL, "proxy", "", "HTTP or SOCKS5 proxy URL for passive sources and probing (e.g. socks5://127.0.0.1:1080); DNS lookups of --active and --ip-pivot are not proxied")
+	discoverCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Custom header sent to probed hosts in \"Name: value\" form (repeatable)")
+	discoverCmd.Flags().StringVar(&userAgent, "user-agent", "", "User-Agent sent to probed hosts")
+	discoverCmd.Flags().StringVar(&sniName, "sni", "", "TLS SNI name override for probed hosts")
+	discoverCmd.Flags().StringVar(&caBundle, "ca-bundle", "", "PEM file with additional trusted CA certificates")
+	discoverCmd.Flags().BoolVar(&insecure, "insecure", true, "Skip TLS verification of crawled pages")
+	discoverCmd.Flags().BoolVar(&verifyTLS, "verify-tls", false, "Flag live hosts whose certificate fails verification against the system roots and --ca-bundle (one extra request per HTTPS host)")
+	discoverCmd.Flags().StringVar(&redirectPolicy, "redirects", discovery.RedirectFollow, "Redirect policy for probed hosts: follow, same-host or none")
+	discoverCmd.Flags().IntVar(&maxRedirects, "max-redirects", discovery.DefaultMaxRedirects, "Maximum redirects followed per host")
+	discoverCmd.Flags().BoolVar(&redirectDiscover, "redirect-discovery", false, "Feed hosts that probed domains redirect to back into discovery when they match the keywords")
 
 	// Output flags
 	discoverCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
-	discoverCmd.Flags().StringVarP(&outputFormat, "format", "f", "text", "Output format (text, json)")
+	discoverCmd.Flags().StringVarP(&outputFormat, "format", "f", "text", fmt.Sprintf("Output format (%s)", strings.Join(output.Names(), ", ")))
+	discoverCmd.Flags().StringVar(&outputFilter, "filter", "", "Only output domains matching this expression (see 'query --help'), e.g. 'reachable && status >= 400'")
+	discoverCmd.Flags().StringVar(&outputSort, "sort", "", "Sort output by comma separated fields, - prefix for descending (e.g. -status,cert.expires)")
+	discoverCmd.Flags().StringVar(&outputFields, "fields", "", "Comma separated fields to output (e.g. domain,status,cert.expires)")
+	discoverCmd.Flags().StringVar(&dbPath, "db", "", "SQLite database to record the scan in, for 'domain-scan query' (default: database.path from config)")
+	discoverCmd.Flags().BoolVar(&notifyDryRunScan, "notify-dry-run", false, "Print notification payloads instead of sending them (see 'notify --help')")
 	discoverCmd.Flags().StringVar(&resultDir, "result-dir", "./result", "Directory to save results (creates {result-dir}/{first-domain}/domains.json)")
+	discoverCmd.Flags().StringVar(&resultLayout, "result-layout", layoutSingle, "Result directory layout: single ({first-domain}/domains.json) or per-seed ({seed}/domains.json per target plus index.json)")
 	discoverCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Quiet mode (suppress progress output)")
+	discoverCmd.Flags().BoolVar(&noColor, "no-color", false, "Plain line-by-line progress without colours or a status bar (also set by NO_COLOR)")
+	discoverCmd.Flags().BoolVar(&fullScreen, "tui", false, "Full-screen progress listing live hosts as they're verified (terminals only)")
 	discoverCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug logging for troubleshooting (deprecated, use --loglevel debug)")
 	discoverCmd.Flags().StringVar(&logLevel, "loglevel", "", "Log level (trace, debug, info, warn, error, silent)")
 
@@ -109,6 +185,21 @@ func init() {
 	_ = viper.BindPFlag("discovery.recursion_depth", discoverCmd.Flags().Lookup("recursion-depth"))
 	_ = viper.BindPFlag("discovery.max_domains", discoverCmd.Flags().Lookup("max-domains"))
 	_ = viper.BindPFlag("discovery.sources", discoverCmd.Flags().Lookup("sources"))
+	_ = viper.BindPFlag("discovery.enable_ip_pivot", discoverCmd.Flags().Lookup("ip-pivot"))
+	_ = viper.BindPFlag("discovery.ip_pivot_prefix", discoverCmd.Flags().Lookup("ip-pivot-prefix"))
+	_ = viper.BindPFlag("discovery.enable_active", discoverCmd.Flags().Lookup("active"))
+	_ = viper.BindPFlag("discovery.enable_content", discoverCmd.Flags().Lookup("crawl"))
+	_ = viper.BindPFlag("discovery.wordlist", discoverCmd.Flags().Lookup("wordlist"))
+	_ = viper.BindPFlag("discovery.proxy", discoverCmd.Flags().Lookup("proxy"))
+	_ = viper.BindPFlag("discovery.headers", discoverCmd.Flags().Lookup("header"))
+	_ = viper.BindPFlag("discovery.user_agent", discoverCmd.Flags().Lookup("user-agent"))
+	_ = viper.BindPFlag("discovery.sni", discoverCmd.Flags().Lookup("sni"))
+	_ = viper.BindPFlag("discovery.ca_bundle", discoverCmd.Flags().Lookup("ca-bundle"))
+	_ = viper.BindPFlag("discovery.insecure", discoverCmd.Flags().Lookup("insecure"))
+	_ = viper.BindPFlag("discovery.verify_tls", discoverCmd.Flags().Lookup("verify-tls"))
+	_ = viper.BindPFlag("discovery.redirects", discoverCmd.Flags().Lookup("redirects"))
+	_ = viper.BindPFlag("discovery.max_redirects", discoverCmd.Flags().Lookup("max-redirects"))
+	_ = viper.BindPFlag("discovery.enable_redirect_discovery", discoverCmd.Flags().Lookup("redirect-discovery"))
 	_ = viper.BindPFlag("keywords", discoverCmd.Flags().Lookup("keywords"))
 	_ = viper.BindPFlag("log_level", discoverCmd.Flags().Lookup("loglevel"))
 }
@@ -116,34 +207,148 @@ func init() {
 // runDiscover executes the domain discovery command with the provided arguments.
 // Orchestrates configuration loading, scanner setup, and result output.
 func runDiscover(cmd *cobra.Command, args []string) error {
+	// Collect targets from arguments, --list and stdin
+	targets, err := collectTargets(cmd, args)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no targets provided: pass domains as arguments, use --list, or pipe targets on stdin")
+	}
+	domainTargets, _, err := utils.SplitTargets(targets)
+	if err != nil {
+		return err
+	}
+
 	// Load configuration
 	config := loadDiscoveryConfig()
 
 	// Apply command-line overrides
 	applyFlagOverrides(cmd, config)
 
+	// Fail fast on an unknown output format, filter or result layout before scanning
+	view, err := newResultView(outputFilter, outputSort, outputFields, outputFormat)
+	if err != nil {
+		return err
+	}
+	if resultLayout != layoutSingle && resultLayout != layoutPerSeed {
+		return fmt.Errorf("unknown result layout %q: must be %s or %s", resultLayout, layoutSingle, layoutPerSeed)
+	}
+
+	// Validate the merged configuration (proxy, CA bundle, keywords) before scanning
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	// Create scanner
 	scanner := domainscan.New(config)
 
+	// Validate notifiers before scanning so a config mistake doesn't surface after a long scan
+	dispatcher, err := loadNotifier(cmd, notifyDryRunScan)
+	if err != nil {
+		return err
+	}
+
 	// Set progress callback for CLI (unless quiet mode)
-	if !quiet {
-		progressHandler := domainscan.NewCLIProgressHandler()
+	progressHandler, restoreTerminal := newProgressHandler()
+	defer restoreTerminal()
+	if progressHandler != nil {
 		scanner.SetProgressCallback(progressHandler)
 	}
 
 	// Create scan request
 	req := &domainscan.ScanRequest{
-		Domains:  args,
-		Keywords: keywords,
+		Domains:  targets,
+		Keywords: utils.SplitKeywords(keywords),
 		Timeout:  getTimeout(config),
 	}
 
 	// Combine all keyword sources efficiently
+	req.Keywords = combineKeywords(domainTargets, req.Keywords, config)
+
+	// Run discovery
+	ctx := context.Background()
+	startedAt := time.Now()
+	result, err := scanner.ScanWithOptions(ctx, req)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	// Record the scan before writing output so a failing formatter doesn't lose it
+	if path := databasePath(cmd, dbPath); path != "" {
+		if err := saveToDatabase(ctx, path, targets, req.Keywords, startedAt, result); err != nil {
+			return err
+		}
+	}
+
+	// Output results
+	err = outputResults(view, result)
+	if err != nil {
+		return err
+	}
+
+	// Load the previous result before it's overwritten, to tell which live hosts are new
+	var previous *domainscan.AssetDiscoveryResult
+	if dispatcher.Enabled() {
+		previous = loadPreviousResult(targets)
+	}
+
+	// Always save domains.json in the result directory
+	if err := saveResults(result, targets, startedAt); err != nil {
+		return err
+	}
+
+	if dispatcher.Enabled() {
+		sendNotifications(ctx, dispatcher, targets, result, previous)
+	}
+	return nil
+}
+
+// newProgressHandler picks the progress output for the current terminal: a live status bar
+// (or full-screen view with --tui) when stdout is an interactive terminal, and the plain
+// line-by-line handler when output is redirected, colours are disabled or TERM is dumb.
+// The returned function restores the terminal and must be called when the scan ends.
+func newProgressHandler() (domainscan.ProgressCallback, func()) {
+	if quiet {
+		return nil, func() {}
+	}
+	fd := int(os.Stdout.Fd()) // #nosec G115 - file descriptors fit in int
+	if noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" || !term.IsTerminal(fd) {
+		return domainscan.NewCLIProgressHandler(), func() {}
+	}
+
+	handler := domainscan.NewTerminalProgressHandler(os.Stdout, domainscan.TerminalProgressOptions{
+		FullScreen: fullScreen,
+		Size:       func() (int, int, error) { return term.GetSize(fd) },
+	})
+	// Log lines are printed above the status bar instead of running into it
+	logging.WrapOutput(handler.Print)
+
+	// Leave the alternate screen and show the cursor again when interrupted mid-scan
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		if _, ok := <-signals; ok {
+			handler.Close()
+			os.Exit(130)
+		}
+	}()
+	return handler, func() {
+		signal.Stop(signals)
+		close(signals)
+		handler.Close()
+		logging.WrapOutput(nil)
+	}
+}
+
+// combineKeywords merges keywords auto-extracted from the target domains with the given
+// keywords, adding the configured keywords when none were given on the command line
+func combineKeywords(domainTargets []string, given []string, config *domainscan.Config) []string {
 	allKeywordSources := [][]string{
-		utils.ExtractKeywordsFromDomains(req.Domains),
-		req.Keywords,
+		utils.ExtractKeywordsFromDomains(domainTargets),
+		given,
 	}
-	if len(keywords) == 0 {
+	if len(given) == 0 {
 		allKeywordSources = append(allKeywordSources, config.Keywords)
 	}
 
@@ -156,26 +361,58 @@ func runDiscover(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	req.Keywords = make([]string, 0, len(keywordMap))
+	combined := make([]string, 0, len(keywordMap))
 	for keyword := range keywordMap {
-		req.Keywords = append(req.Keywords, keyword)
+		combined = append(combined, keyword)
 	}
+	return combined
+}
 
-	// Run discovery
-	ctx := context.Background()
-	result, err := scanner.ScanWithOptions(ctx, req)
-	if err != nil {
-		return fmt.Errorf("discovery failed: %w", err)
+// collectTargets gathers scan targets from positional arguments, the --list file and stdin.
+// Stdin is read when --list is "-", or when no other targets are given and input is piped.
+// Invalid targets are rejected, with their line number for lists.
+func collectTargets(cmd *cobra.Command, args []string) ([]string, error) {
+	var targets []string
+	for _, arg := range args {
+		target, err := utils.ParseTarget(arg)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
 	}
 
-	// Output results
-	err = outputResults(result)
+	switch {
+	case listFile == "-":
+		return appendTargetsFrom(targets, cmd.InOrStdin())
+	case listFile != "":
+		file, err := os.Open(listFile) // #nosec G304 - user supplied target list
+		if err != nil {
+			return nil, fmt.Errorf("failed to open target list: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		return appendTargetsFrom(targets, file)
+	case len(targets) == 0 && isPipedStdin():
+		return appendTargetsFrom(targets, cmd.InOrStdin())
+	}
+	return targets, nil
+}
+
+// appendTargetsFrom parses targets from r and appends them to targets
+func appendTargetsFrom(targets []string, r io.Reader) ([]string, error) {
+	parsed, err := utils.ParseTargets(r)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("invalid target list: %w", err)
 	}
+	return append(targets, parsed...), nil
+}
 
-	// Always create domains.json in result directory
-	return createDomainsJSON(result, args[0])
+// isPipedStdin reports whether stdin is a pipe or file rather than a terminal
+func isPipedStdin() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
 }
 
 // loadDiscoveryConfig creates and loads configuration from viper settings.
@@ -190,8 +427,56 @@ func loadDiscoveryConfig() *domainscan.Config {
 	if viper.IsSet("discovery.threads") {
 		config.Discovery.Threads = viper.GetInt("discovery.threads")
 	}
+	if viper.IsSet("discovery.enable_ip_pivot") {
+		config.Discovery.EnableIPPivot = viper.GetBool("discovery.enable_ip_pivot")
+	}
+	if viper.IsSet("discovery.ip_pivot_prefix") {
+		config.Discovery.IPPivotPrefix = viper.GetInt("discovery.ip_pivot_prefix")
+	}
+	if viper.IsSet("discovery.enable_active") {
+		config.Discovery.EnableActive = viper.GetBool("discovery.enable_active")
+	}
+	if viper.IsSet("discovery.enable_content") {
+		config.Discovery.EnableContent = viper.GetBool("discovery.enable_content")
+	}
+	if viper.IsSet("discovery.wordlist") {
+		config.Discovery.Wordlist = viper.GetString("discovery.wordlist")
+	}
+	if viper.IsSet("discovery.permutation_words") {
+		config.Discovery.PermutationWords = viper.GetStringSlice("discovery.permutation_words")
+	}
+	if viper.IsSet("discovery.proxy") {
+		config.Discovery.Proxy = viper.GetString("discovery.proxy")
+	}
+	if viper.IsSet("discovery.headers") {
+		config.Discovery.Headers = viper.GetStringSlice("discovery.headers")
+	}
+	if viper.IsSet("discovery.user_agent") {
+		config.Discovery.UserAgent = viper.GetString("discovery.user_agent")
+	}
+	if viper.IsSet("discovery.sni") {
+		config.Discovery.SNI = viper.GetString("discovery.sni")
+	}
+	if viper.IsSet("discovery.ca_bundle") {
+		config.Discovery.CABundle = viper.GetString("discovery.ca_bundle")
+	}
+	if viper.IsSet("discovery.insecure") {
+		config.Discovery.Insecure = viper.GetBool("discovery.insecure")
+	}
+	if viper.IsSet("discovery.verify_tls") {
+		config.Discovery.VerifyTLS = viper.GetBool("discovery.verify_tls")
+	}
+	if viper.IsSet("discovery.redirects") {
+		config.Discovery.Redirects = viper.GetString("discovery.redirects")
+	}
+	if viper.IsSet("discovery.max_redirects") {
+		config.Discovery.MaxRedirects = viper.GetInt("discovery.max_redirects")
+	}
+	if viper.IsSet("discovery.enable_redirect_discovery") {
+		config.Discovery.EnableRedirectDiscovery = viper.GetBool("discovery.enable_redirect_discovery")
+	}
 	if viper.IsSet("keywords") {
-		config.Keywords = viper.GetStringSlice("keywords")
+		config.Keywords = utils.SplitKeywords(viper.GetStringSlice("keywords"))
 	}
 	if viper.IsSet("log_level") {
 		config.LogLevel = viper.GetString("log_level")
@@ -210,7 +495,7 @@ func applyFlagOverrides(cmd *cobra.Command, config *domainscan.Config) {
 		config.Discovery.Threads = threads
 	}
 	if cmd.Flags().Changed("keywords") {
-		config.Keywords = keywords
+		config.Keywords = utils.SplitKeywords(keywords)
 	}
 
 	// Discovery control flags
@@ -232,6 +517,53 @@ func applyFlagOverrides(cmd *cobra.Command, config *domainscan.Config) {
 	if cmd.Flags().Changed("sources") {
 		config.Discovery.Sources = sources
 	}
+	if cmd.Flags().Changed("ip-pivot") {
+		config.Discovery.EnableIPPivot = ipPivot
+	}
+	if cmd.Flags().Changed("ip-pivot-prefix") {
+		config.Discovery.IPPivotPrefix = ipPivotPrefix
+	}
+	if cmd.Flags().Changed("active") {
+		config.Discovery.EnableActive = activeEnum
+	}
+	if cmd.Flags().Changed("crawl") {
+		config.Discovery.EnableContent = crawlContent
+	}
+	if cmd.Flags().Changed("wordlist") {
+		config.Discovery.Wordlist = wordlist
+	}
+
+	// HTTP client flags
+	if cmd.Flags().Changed("proxy") {
+		config.Discovery.Proxy = proxyURL
+	}
+	if cmd.Flags().Changed("header") {
+		config.Discovery.Headers = headers
+	}
+	if cmd.Flags().Changed("user-agent") {
+		config.Discovery.UserAgent = userAgent
+	}
+	if cmd.Flags().Changed("sni") {
+		config.Discovery.SNI = sniName
+	}
+	if cmd.Flags().Changed("ca-bundle") {
+		config.Discovery.CABundle = caBundle
+	}
+	if cmd.Flags().Changed("insecure") {
+		config.Discovery.Insecure = insecure
+	}
+	if cmd.Flags().Changed("verify-tls") {
+		config.Discovery.VerifyTLS = verifyTLS
+	}
+	if cmd.Flags().Changed("redirects") {
+		config.Discovery.Redirects = redirectPolicy
+	}
+	if cmd.Flags().Changed("max-redirects") {
+		config.Discovery.MaxRedirects = maxRedirects
+	}
+	if cmd.Flags().Changed("redirect-discovery") {
+		config.Discovery.EnableRedirectDiscovery = redirectDiscover
+	}
 
 	// Handle legacy --debug flag and new --loglevel flag
 	if cmd.Flags().Changed("debug") && debug {
@@ -252,89 +584,35 @@ func getTimeout(config *domainscan.Config) time.Duration {
 }
 
 // outputResults formats and outputs discovery results to stdout or file.
-// Supports both text and JSON output formats with live domain highlighting.
-func outputResults(result *domainscan.AssetDiscoveryResult) error {
-	var output []byte
-	var err error
-
-	switch strings.ToLower(outputFormat) {
-	case "json":
-		output, err = json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal JSON: %w", err)
-		}
-	default: // text
-		var sb strings.Builder
-		// Write summary header
-		sb.WriteString(fmt.Sprintf("\nDiscovery Results:\n"))
-		sb.WriteString(fmt.Sprintf("  Discovered: %d domains (%d live, %d traced)\n\n",
-			result.Statistics.TotalSubdomains,
-			result.Statistics.ActiveServices,
-			result.Statistics.TracedDomains))
-
-		// Show live domains first
-		liveCount := 0
-		for _, entry := range result.Domains {
-			if entry.Reachable {
-				liveCount++
-				sb.WriteString(fmt.Sprintf("%s \033[32m[LIVE:%d]\033[0m\n", entry.Domain, entry.Status))
-			}
-		}
-
-		// Show traced domains
-		if result.Statistics.TracedDomains > 0 {
-			sb.WriteString(fmt.Sprintf("\n%d traced domains (not HTTP accessible):\n", result.Statistics.TracedDomains))
-			tracedShown := 0
-			for _, entry := range result.Domains {
-				if !entry.Reachable && tracedShown < 10 {
-					sb.WriteString(fmt.Sprintf("  %s\033[90m [TRACED]\033[0m\n", entry.Domain))
-					tracedShown++
-				}
-			}
-			if result.Statistics.TracedDomains > 10 {
-				sb.WriteString(fmt.Sprintf("  ... and %d more (see domains.json for full list)\n", result.Statistics.TracedDomains-10))
-			}
-		}
-		output = []byte(sb.String())
+// The --format flag selects any formatter registered in the output package; --filter,
+// --sort and --fields only shape the output, saved results keep every domain.
+func outputResults(view *resultView, result *domainscan.AssetDiscoveryResult) error {
+	var buf bytes.Buffer
+	if err := view.render(&buf, outputFormat, result); err != nil {
+		return err
 	}
 
 	if outputFile != "" {
-		return os.WriteFile(outputFile, output, 0600)
+		return os.WriteFile(outputFile, buf.Bytes(), 0600)
 	}
 
-	fmt.Print(string(output))
+	fmt.Print(buf.String())
 	return nil
 }
 
-// createDomainsJSON creates a structured domains.json file in the result directory.
-// Includes full domain information with sources and IPs
-func createDomainsJSON(result *domainscan.AssetDiscoveryResult, firstDomain string) error {
-	// Create result directory structure
-	domainDir := filepath.Join(resultDir, firstDomain)
-	if err := os.MkdirAll(domainDir, 0750); err != nil {
-		return fmt.Errorf("failed to create result directory: %w", err)
-	}
-
-	// Create domain result structure with full entries
-	domainResult := DomainResult{
-		Domains: result.Domains,
-	}
-
-	// Marshal to JSON
-	output, err := json.MarshalIndent(domainResult, "", "  ")
+// saveToDatabase records the scan in the SQLite database at path
+func saveToDatabase(ctx context.Context, path string, targets, keywords []string, startedAt time.Time, result *domainscan.AssetDiscoveryResult) error {
+	db, err := store.Open(path)
 	if err != nil {
-		return fmt.Errorf("failed to marshal domains JSON: %w", err)
+		return err
 	}
+	defer func() { _ = db.Close() }()
 
-	// Write to domains.json
-	domainsPath := filepath.Join(domainDir, "domains.json")
-	if err := os.WriteFile(domainsPath, output, 0600); err != nil {
-		return fmt.Errorf("failed to write domains.json: %w", err)
+	scanID, err := db.SaveScan(ctx, targets, keywords, startedAt, result)
+	if err != nil {
+		return fmt.Errorf("failed to save scan to database: %w", err)
 	}
-
-	// Print the full path as an end event
-	fmt.Printf("\nResults saved to: %s\n", domainsPath)
-
+	fmt.Fprintf(os.Stderr, "Scan %d saved to: %s\n", scanID, path)
 	return nil
 }
 
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCollectTargetsFromStdin(t *testing.T) {
+	defer func(previous string) { listFile = previous }(listFile)
+	listFile = "-"
+
+	cmd := &cobra.Command{}
+	cmd.SetIn(strings.NewReader("# piped from subfinder\napi.example.com\nhttps://www.example.com/login\n192.0.2.1,extra\n\n"))
+
+	targets, err := collectTargets(cmd, []string{"Example.com"})
+	if err != nil {
+		t.Fatalf("collectTargets() error: %v", err)
+	}
+	expected := []string{"example.com", "api.example.com", "www.example.com", "192.0.2.1"}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("Expected %v, got %v", expected, targets)
+	}
+}
+
+func TestCollectTargetsRejectsInvalid(t *testing.T) {
+	defer func(previous string) { listFile = previous }(listFile)
+
+	listFile = ""
+	if _, err := collectTargets(&cobra.Command{}, []string{"example.com", "exa mple.com"}); err == nil || !strings.Contains(err.Error(), "exa mple.com") {
+		t.Errorf("Expected an error naming the invalid argument, got %v", err)
+	}
+
+	listFile = "-"
+	cmd := &cobra.Command{}
+	cmd.SetIn(strings.NewReader("example.com\n# comment\n-bad-.example.com\n"))
+	if _, err := collectTargets(cmd, nil); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected an error with the line number of the invalid target, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+var explainResultFile string
+
+// explainCmd prints the discovery chain that led to a domain
+var explainCmd = &cobra.Command{
+	Use:   "explain <domain>",
+	Short: "Show how a domain was discovered",
+	Long: `Explain prints the provenance chain of a domain from a saved result: the seed
+the scan started from and every hop (passive enumeration, certificate SAN, IP pivot,
+permutation, ...) that led to the domain, with recursion depth and discovery time.`,
+	Example: `  # Why is this host in scope?
+  domain-scan explain dev.api.example.com -r result/example.com/domains.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	explainCmd.Flags().StringVarP(&explainResultFile, "result", "r", "", "Saved result file (domains.json or JSON output)")
+	_ = explainCmd.MarkFlagRequired("result")
+}
+
+// runExplain loads the saved result and prints the provenance chain of the domain
+func runExplain(cmd *cobra.Command, args []string) error {
+	result, err := domainscan.LoadResult(explainResultFile)
+	if err != nil {
+		return err
+	}
+
+	chain, err := result.Provenance(args[0])
+	if err != nil {
+		return err
+	}
+
+	for i, step := range chain {
+		indent := ""
+		if i > 0 {
+			indent = strings.Repeat("   ", i-1) + "└─ "
+		}
+		fmt.Printf("%s%s%s\n", indent, step.Domain, describeProvenance(step, i == 0))
+	}
+	return nil
+}
+
+// describeProvenance formats how a step was discovered
+func describeProvenance(step domainscan.ProvenanceStep, root bool) string {
+	src := step.Source
+	if src.Stage == "" {
+		if root {
+			return "  (no provenance recorded)"
+		}
+		return ""
+	}
+	if src.Type == "seed" || src.Name == "" {
+		return "  [seed]"
+	}
+
+	parts := []string{fmt.Sprintf("%s via %s (%s)", src.Stage, src.Name, src.Type)}
+	if src.Certificate != nil && src.Certificate.Subject != "" {
+		parts = append(parts, "cert "+src.Certificate.Subject)
+	}
+	parts = append(parts, fmt.Sprintf("depth %d", src.Depth))
+	if !src.DiscoveredAt.IsZero() {
+		parts = append(parts, src.DiscoveredAt.Format(time.RFC3339))
+	}
+	return "  [" + strings.Join(parts, ", ") + "]"
+}
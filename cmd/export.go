@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/output"
+)
+
+var (
+	exportResultFile string
+	exportFormat     string
+	exportOutput     string
+)
+
+// exportCmd converts a saved result into another output format
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a saved result to another format",
+	Long: `Export converts a saved discovery result (domains.json or --format json output)
+into any supported output format, including inputs for downstream tools:
+
+- urls:  live URLs one per line, e.g. as a nuclei target list
+- sarif: SARIF 2.1.0 log of certificate and subdomain takeover findings
+- graph: asset graph JSON (domains, IPs, certificates and their relationships)`,
+	Example: `  # Feed live URLs to nuclei
+  domain-scan export -r result/example.com/domains.json -f urls | nuclei
+
+  # Write certificate and takeover findings for code scanning
+  domain-scan export -r domains.json -f sarif -o findings.sarif
+
+  # Build an asset graph for an asset manager
+  domain-scan export -r domains.json -f graph -o graph.json`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportResultFile, "result", "r", "", "Saved result file (domains.json or JSON output)")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "urls", fmt.Sprintf("Export format (%s)", strings.Join(output.Names(), ", ")))
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file (default: stdout)")
+	_ = exportCmd.MarkFlagRequired("result")
+}
+
+// runExport loads the saved result and writes it in the requested format
+func runExport(cmd *cobra.Command, args []string) error {
+	result, err := domainscan.LoadResult(exportResultFile)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := output.Write(&buf, exportFormat, result); err != nil {
+		return err
+	}
+
+	if exportOutput != "" {
+		return os.WriteFile(exportOutput, buf.Bytes(), 0600)
+	}
+	fmt.Print(buf.String())
+	return nil
+}
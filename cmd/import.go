@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/ingest"
+	"github.com/valllabh/domain-scan/pkg/output"
+)
+
+var (
+	importInputFormat string
+	importFormat      string
+	importOutput      string
+	importVerify      bool
+	importSeed        bool
+)
+
+// importCmd converts the output of other enumeration tools into a domain-scan result
+var importCmd = &cobra.Command{
+	Use:   "import <file>...",
+	Short: "Import amass, subfinder, massdns or host list output",
+	Long: `Import converts the output of other enumeration tools into a domain-scan
+result, so it can be verified, merged, explained and exported like a native scan.
+
+Supported input formats (--input-format, detected per file by default):
+  amass      amass enum -json lines, or amass v4 "name (FQDN) --> ..." lines
+  subfinder  subfinder -oJ lines, or host[,ip] lines from -silent and -oI
+  massdns    massdns -o S or -o J output; only resolved names are imported
+  list       one host, URL or domain per line
+
+Every domain is recorded with an "import" source named after the tool and, when
+the tool reports it, its upstream source (e.g. "amass:crtsh" or "subfinder:crtsh").
+Imported domains are unverified until probed: use --verify to probe them now,
+or --seed to also use them as seeds for recursive certificate discovery.`,
+	Example: `  # Convert amass output into a result file
+  domain-scan import amass.json -o imported.json
+
+  # Import several tools' output and probe every domain
+  domain-scan import subs.txt massdns.txt --verify -o imported.json
+
+  # Expand imported names through certificate discovery
+  domain-scan import amass.json --seed -o expanded.json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&importInputFormat, "input-format", ingest.FormatAuto, fmt.Sprintf("Input format (%s)", strings.Join(ingest.Formats(), ", ")))
+	importCmd.Flags().StringVarP(&importFormat, "format", "f", "json", fmt.Sprintf("Output format (%s)", strings.Join(output.Names(), ", ")))
+	importCmd.Flags().StringVarP(&importOutput, "output", "o", "", "Output file (default: stdout)")
+	importCmd.Flags().BoolVar(&importVerify, "verify", false, "Probe the imported domains over HTTP/TLS")
+	importCmd.Flags().BoolVar(&importSeed, "seed", false, "Use the imported domains as seeds for recursive certificate discovery")
+	importCmd.MarkFlagsMutuallyExclusive("verify", "seed")
+}
+
+// runImport parses every file, optionally probes or expands the domains and writes the result
+func runImport(cmd *cobra.Command, args []string) error {
+	if _, err := output.Get(importFormat); err != nil {
+		return err
+	}
+
+	results := make([]*domainscan.AssetDiscoveryResult, 0, len(args))
+	for _, path := range args {
+		result, err := ingest.ParseFile(path, importInputFormat)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Imported %d domains from %s\n", len(result.Domains), path)
+		results = append(results, result)
+	}
+	imported := domainscan.MergeResults(results...)
+
+	if importVerify || importSeed {
+		config := loadDiscoveryConfig()
+		if err := config.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		ctx := context.Background()
+
+		if importVerify {
+			verified, err := domainscan.New(config).Verify(ctx, imported)
+			if err != nil {
+				return err
+			}
+			imported = verified.Result
+		} else {
+			seeds := make([]string, 0, len(imported.Domains))
+			for domain := range imported.Domains {
+				seeds = append(seeds, domain)
+			}
+			sort.Strings(seeds)
+
+			// The imported names already came from passive sources, so only certificates are followed
+			config.Discovery.EnablePassive = false
+			req := &domainscan.ScanRequest{
+				Domains:  seeds,
+				Keywords: combineKeywords(seeds, nil, config),
+				Timeout:  getTimeout(config),
+			}
+			scanned, err := domainscan.New(config).ScanWithOptions(ctx, req)
+			if err != nil {
+				return fmt.Errorf("discovery failed: %w", err)
+			}
+			imported = domainscan.MergeResults(imported, scanned)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := output.Write(&buf, importFormat, imported); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Imported %d domains, %d live\n", imported.Statistics.TotalSubdomains, imported.Statistics.ActiveServices)
+
+	if importOutput != "" {
+		return os.WriteFile(importOutput, buf.Bytes(), 0600)
+	}
+	fmt.Print(buf.String())
+	return nil
+}
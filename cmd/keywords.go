@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+var (
+	keywordsTargets []string
+	keywordsGiven   []string
+)
+
+// keywordsCmd explains how the keyword filter treats domain names
+var keywordsCmd = &cobra.Command{
+	Use:   "keywords <domain>...",
+	Short: "Explain why domains are accepted or rejected by the keywords",
+	Long: `Keywords decide which certificate SANs, redirect targets and crawled hosts are in
+scope. This command matches each domain against the keywords discover would use (extracted
+from --target domains plus --keywords, or the configured keywords) and explains the outcome.
+
+Keyword syntax:
+  example      substring anywhere in the domain (default)
+  =hp          whole label or hyphen/underscore separated token: hp.com, hp-cdn.net, not php.net
+  /^api\d+\./  regular expression, case-insensitive
+  ~paypal      brand match tolerating a typo and look-alike characters: paypa1, pаypal, pay-pal
+  !partner-    exclusion: matching domains are rejected even if another keyword matches`,
+	Example: `  # Would these SANs be kept when scanning example.com?
+  domain-scan keywords -t example.com shop.example.com partner-example.net
+
+  # Check exclusions and brand matching
+  domain-scan keywords -k '~paypal' -k '!/^test\d/' paypa1-login.com test1.paypal.com`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runKeywords,
+}
+
+func init() {
+	rootCmd.AddCommand(keywordsCmd)
+
+	keywordsCmd.Flags().StringSliceVarP(&keywordsTargets, "target", "t", []string{}, "Scan targets whose keywords are auto-extracted, as discover does")
+	keywordsCmd.Flags().StringArrayVarP(&keywordsGiven, "keywords", "k", []string{}, "Keywords to match, repeatable or comma-separated (default: keywords from config)")
+}
+
+// runKeywords prints the keywords in use and the match outcome of every domain
+func runKeywords(cmd *cobra.Command, args []string) error {
+	config := loadDiscoveryConfig()
+	domainTargets, _, err := utils.SplitTargets(keywordsTargets)
+	if err != nil {
+		return fmt.Errorf("invalid targets: %w", err)
+	}
+	keywords := utils.LoadKeywords(domainTargets, combineKeywords(nil, utils.SplitKeywords(keywordsGiven), config))
+	sort.Strings(keywords)
+	matcher, err := utils.NewMatcher(keywords)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Keywords: %v\n\n", keywords)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, arg := range args {
+		domain := utils.NormalizeTarget(arg)
+		if domain == "" {
+			_, _ = fmt.Fprintf(w, "reject\t%s\tnot a valid domain\n", arg)
+			continue
+		}
+		match := matcher.Explain(domain)
+		outcome := "reject"
+		if match.Accepted {
+			outcome = "accept"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", outcome, domain, match.Reason)
+	}
+	return w.Flush()
+}
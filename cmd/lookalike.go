@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/valllabh/domain-scan/pkg/discovery"
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/output"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+var (
+	lookalikeTLDs       []string
+	lookalikeTechniques []string
+	lookalikeFormat     string
+	lookalikeOutput     string
+)
+
+// lookalikeCmd finds registered typosquats of the given domains
+var lookalikeCmd = &cobra.Command{
+	Use:   "lookalike <domain>...",
+	Short: "Find registered lookalike and typosquat domains",
+	Long: `Lookalike generates variants of the registrable domain of each target, checks
+which are registered in DNS and probes the resolving ones over HTTP/TLS, reporting
+their status, redirects and certificates.
+
+Techniques (--techniques, all by default):
+  omission       exmple.com         repetition     exaample.com
+  transposition  exmaple.com        replacement    exanple.com (neighbouring key)
+  insertion      exampkle.com       homoglyph      examp1e.com, exarnple.com, еxample.com
+                                                   (Cyrillic е, as xn--xample-2of.com)
+  bitflip        axample.com        hyphenation    exam-ple.com
+  tld-swap       example.net, example.co.uk (every embedded TLD unless --tlds is given)
+
+A variant counts as registered when it resolves or has a name server delegation;
+suffixes that answer for any name are detected and ignored. Every lookalike is
+recorded with a "lookalike" source named after its technique, with the imitated
+domain as parent, so the result can be filtered, exported and merged like a scan.`,
+	Example: `  # Check every technique against the full TLD list
+  domain-scan lookalike example.com
+
+  # Typos and homoglyphs only, swapping to a few popular TLDs
+  domain-scan lookalike example.com --techniques omission,replacement,homoglyph,tld-swap --tlds com,net,org,io
+
+  # Save a JSON result for review
+  domain-scan lookalike example.com -f json -o lookalikes.json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runLookalike,
+}
+
+func init() {
+	rootCmd.AddCommand(lookalikeCmd)
+
+	lookalikeCmd.Flags().StringSliceVar(&lookalikeTLDs, "tlds", []string{}, "Suffixes used for TLD swaps (default: every embedded TLD)")
+	lookalikeCmd.Flags().StringSliceVar(&lookalikeTechniques, "techniques", []string{}, fmt.Sprintf("Techniques to use (%s)", strings.Join(discovery.LookalikeTechniques, ", ")))
+	lookalikeCmd.Flags().StringVarP(&lookalikeFormat, "format", "f", "text", fmt.Sprintf("Output format (%s)", strings.Join(output.Names(), ", ")))
+	lookalikeCmd.Flags().StringVarP(&lookalikeOutput, "output", "o", "", "Output file (default: stdout)")
+}
+
+// runLookalike checks the lookalikes of every target and writes the registered ones
+func runLookalike(cmd *cobra.Command, args []string) error {
+	if _, err := output.Get(lookalikeFormat); err != nil {
+		return err
+	}
+	domains, ips, err := utils.SplitTargets(args)
+	if err != nil {
+		return fmt.Errorf("invalid targets: %w", err)
+	}
+	if len(ips) > 0 {
+		return fmt.Errorf("lookalike checks need domains, got IP targets %v", ips)
+	}
+
+	config := loadDiscoveryConfig()
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	req := &domainscan.LookalikeRequest{
+		Domains:    domains,
+		Techniques: lookalikeTechniques,
+	}
+	if cmd.Flags().Changed("tlds") {
+		req.TLDs = lookalikeTLDs
+	}
+
+	checked, err := domainscan.New(config).Lookalikes(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := output.Write(&buf, lookalikeFormat, checked.Result); err != nil {
+		return err
+	}
+	resolving := 0
+	for _, entry := range checked.Result.Domains {
+		if entry.IP != "" {
+			resolving++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Checked %d lookalikes: %d registered, %d resolving, %d live\n",
+		checked.Candidates, len(checked.Result.Domains), resolving, checked.Result.Statistics.ActiveServices)
+
+	if lookalikeOutput != "" {
+		return os.WriteFile(lookalikeOutput, buf.Bytes(), 0600)
+	}
+	fmt.Print(buf.String())
+	return nil
+}
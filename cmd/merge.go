@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/output"
+)
+
+var (
+	mergeFormat string
+	mergeOutput string
+)
+
+// mergeCmd unions several saved results into one
+var mergeCmd = &cobra.Command{
+	Use:   "merge <result.json> <result.json>...",
+	Short: "Merge saved results into one",
+	Long: `Merge unions saved discovery results (domains.json or --format json output),
+for example scans run by different people with different sources and API keys.
+
+Each domain keeps every distinct discovery source, with duplicates recorded once
+at their earliest discovery time. Its HTTP and certificate data come from the
+most recently probed result. Statistics are recomputed for the merged result.`,
+	Example: `  # Combine two team members' scans
+  domain-scan merge alice/domains.json bob/domains.json -o combined.json
+
+  # Merge and list the live URLs
+  domain-scan merge result/*/domains.json -f urls`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runMerge,
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+
+	mergeCmd.Flags().StringVarP(&mergeFormat, "format", "f", "json", fmt.Sprintf("Output format (%s)", strings.Join(output.Names(), ", ")))
+	mergeCmd.Flags().StringVarP(&mergeOutput, "output", "o", "", "Output file (default: stdout)")
+}
+
+// runMerge loads every result, merges them and writes the merged result
+func runMerge(cmd *cobra.Command, args []string) error {
+	if _, err := output.Get(mergeFormat); err != nil {
+		return err
+	}
+
+	results := make([]*domainscan.AssetDiscoveryResult, 0, len(args))
+	for _, path := range args {
+		result, err := domainscan.LoadResult(path)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+	merged := domainscan.MergeResults(results...)
+
+	var buf bytes.Buffer
+	if err := output.Write(&buf, mergeFormat, merged); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Merged %d results: %d domains, %d live\n", len(results), merged.Statistics.TotalSubdomains, merged.Statistics.ActiveServices)
+
+	if mergeOutput != "" {
+		return os.WriteFile(mergeOutput, buf.Bytes(), 0600)
+	}
+	fmt.Print(buf.String())
+	return nil
+}
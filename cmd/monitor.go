@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/monitor"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+var (
+	monitorInterval time.Duration
+	monitorSinks    []string
+	monitorState    string
+	monitorRuns     int
+)
+
+// monitorCmd rescans targets on an interval and reports changes
+var monitorCmd = &cobra.Command{
+	Use:   "monitor [domains|IPs|CIDRs...]",
+	Short: "Rescan targets on an interval and report changes",
+	Long: `Monitor rescans a set of targets on an interval, compares each run with the
+previous one and emits change events as JSON:
+
+  new_host      a domain not seen in the previous run
+  host_live     a domain became reachable
+  host_dark     a domain stopped being reachable
+  cert_rotated  a domain serves a different certificate
+  ip_changed    a domain resolves to a different IP
+
+Events go to every --sink: "-" for stdout (JSON lines), an http(s) URL to POST
+{"changes": [...]} to a webhook, or a file path to append JSON lines to.
+
+Targets come from arguments, --list, or monitor.targets in the config file. The
+first run establishes the baseline; with --state the last result is saved so a
+restarted monitor compares against it instead. Scans use the discovery settings
+from the config file.`,
+	Example: `  # Hourly rescans, changes on stdout
+  domain-scan monitor example.com example.org
+
+  # Every 6 hours, to a file and a webhook, surviving restarts
+  domain-scan monitor --list targets.txt --interval 6h --state monitor.json \
+    --sink changes.jsonl --sink https://hooks.example.com/domain-scan`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runMonitor,
+}
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+
+	monitorCmd.Flags().StringVarP(&listFile, "list", "l", "", "File with targets (domains, IPs or CIDRs), one per line; use - for stdin")
+	monitorCmd.Flags().DurationVar(&monitorInterval, "interval", monitor.DefaultInterval, "Time between the start of consecutive scans")
+	monitorCmd.Flags().StringArrayVar(&monitorSinks, "sink", []string{"-"}, "Where to send change events: - (stdout), a file path or a webhook URL (repeatable)")
+	monitorCmd.Flags().StringVar(&monitorState, "state", "", "File keeping the last result so restarts compare against it")
+	monitorCmd.Flags().IntVar(&monitorRuns, "runs", 0, "Stop after this many scans (0 = run until interrupted)")
+
+	_ = viper.BindPFlag("monitor.interval", monitorCmd.Flags().Lookup("interval"))
+	_ = viper.BindPFlag("monitor.sinks", monitorCmd.Flags().Lookup("sink"))
+	_ = viper.BindPFlag("monitor.state", monitorCmd.Flags().Lookup("state"))
+}
+
+// runMonitor runs the monitor until interrupted
+func runMonitor(cmd *cobra.Command, args []string) error {
+	targets, err := collectTargets(cmd, args)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		for _, raw := range viper.GetStringSlice("monitor.targets") {
+			target, err := utils.ParseTarget(raw)
+			if err != nil {
+				return fmt.Errorf("invalid monitor.targets entry: %w", err)
+			}
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no targets provided: pass domains as arguments, use --list, or set monitor.targets in the config file")
+	}
+	domainTargets, _, err := utils.SplitTargets(targets)
+	if err != nil {
+		return err
+	}
+
+	config := loadDiscoveryConfig()
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	var sinks []monitor.Sink
+	for _, spec := range viper.GetStringSlice("monitor.sinks") {
+		sink, err := monitor.ParseSink(spec)
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	req := &domainscan.ScanRequest{
+		Domains:  targets,
+		Keywords: combineKeywords(domainTargets, nil, config),
+		Timeout:  config.Discovery.Timeout,
+	}
+	m := monitor.New(monitor.Options{
+		Interval: viper.GetDuration("monitor.interval"),
+		Scan: func(ctx context.Context) (*domainscan.AssetDiscoveryResult, error) {
+			return domainscan.New(config).ScanWithOptions(ctx, req)
+		},
+		Sinks:     sinks,
+		StateFile: viper.GetString("monitor.state"),
+		Runs:      monitorRuns,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "Monitoring %d targets every %s\n", len(targets), viper.GetDuration("monitor.interval"))
+	return m.Run(ctx)
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/notify"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+var (
+	notifyDryRun bool
+	notifyEvent  string
+)
+
+// notifyCmd groups notification commands
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage scan notifications",
+	Long: `Notifications are sent by discover when a scan finishes. They are configured in
+the notifications section of the config file:
+
+  notifications:
+    notifiers:
+      - type: slack          # webhook, slack or teams
+        url: https://hooks.slack.com/services/...
+        events: [new_live_hosts, findings]
+
+Events are scan_completed (every scan), new_live_hosts (hosts live now but not
+in the previous domains.json of the same result directory) and findings
+(expired, expiring or untrusted certificates and possible takeovers not in
+the previous domains.json).`,
+}
+
+// notifyTestCmd sends a sample event to the configured notifiers
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a sample notification to the configured notifiers",
+	Example: `  # Check that every notifier accepts a message
+  domain-scan notify test
+
+  # Print the findings payloads without sending them
+  domain-scan notify test --event findings --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runNotifyTest,
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+
+	notifyTestCmd.Flags().BoolVar(&notifyDryRun, "dry-run", false, "Print the payloads instead of sending them")
+	notifyTestCmd.Flags().StringVar(&notifyEvent, "event", notify.EventScanCompleted, "Event to send: scan_completed, new_live_hosts or findings")
+}
+
+// runNotifyTest sends a sample event built from a fabricated result
+func runNotifyTest(cmd *cobra.Command, args []string) error {
+	dispatcher, err := loadNotifier(cmd, notifyDryRun)
+	if err != nil {
+		return err
+	}
+	if !dispatcher.Enabled() {
+		return fmt.Errorf("no notifiers configured: add notifications.notifiers to the config file")
+	}
+
+	now := time.Now()
+	result := &domainscan.AssetDiscoveryResult{Domains: map[string]*domainscan.DomainEntry{
+		"example.com": {
+			Domain: "example.com", URL: "https://example.com", Status: 200, Reachable: true,
+		},
+		"expired.example.com": {
+			Domain: "expired.example.com", URL: "https://expired.example.com", Status: 200, Reachable: true,
+			Certificate: &types.CertificateInfo{Subject: "CN=expired.example.com", Issuer: "CN=Test CA", ExpiresOn: now.AddDate(0, 0, -1)},
+		},
+	}}
+	result.UpdateStatistics()
+
+	for _, event := range notify.Events([]string{"example.com"}, result, nil, now) {
+		if event.Type == notifyEvent {
+			if err := dispatcher.Notify(cmd.Context(), event); err != nil {
+				return err
+			}
+			if !notifyDryRun {
+				fmt.Fprintf(os.Stderr, "Sent %s notification\n", event.Type)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown event %q: must be %s, %s or %s", notifyEvent, notify.EventScanCompleted, notify.EventNewLiveHosts, notify.EventFindings)
+}
+
+// loadNotifier creates a dispatcher from the notifications config section; dryRun
+// forces dry-run mode when the flag was given
+func loadNotifier(cmd *cobra.Command, dryRun bool) (*notify.Dispatcher, error) {
+	var config notify.Config
+	if err := viper.UnmarshalKey("notifications", &config); err != nil {
+		return nil, fmt.Errorf("invalid notifications config: %w", err)
+	}
+	if cmd.Flags().Changed("dry-run") || cmd.Flags().Changed("notify-dry-run") {
+		config.DryRun = dryRun
+	}
+	dispatcher, err := notify.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notifications config: %w", err)
+	}
+	return dispatcher, nil
+}
+
+// sendNotifications delivers the events of a finished scan. Failures are reported
+// but don't fail the scan, whose results are already saved.
+func sendNotifications(ctx context.Context, dispatcher *notify.Dispatcher, targets []string, result, previous *domainscan.AssetDiscoveryResult) {
+	for _, event := range notify.Events(targets, result, previous, time.Now()) {
+		if err := dispatcher.Notify(ctx, event); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s notification failed: %v\n", event.Type, err)
+		}
+	}
+}
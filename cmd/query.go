@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/filter"
+	"github.com/valllabh/domain-scan/pkg/output"
+	"github.com/valllabh/domain-scan/pkg/store"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+var (
+	queryDB        string
+	queryResult    string
+	queryScans     bool
+	queryScanID    int64
+	queryLive      bool
+	queryExpiring  string
+	queryFirstSeen string
+	queryLastSeen  string
+	queryDomain    string
+	querySource    string
+	queryLimit     int
+	queryFormat    string
+	queryOutput    string
+	querySort      string
+	queryFields    string
+)
+
+// queryCmd queries domains stored by discover --db
+var queryCmd = &cobra.Command{
+	Use:   "query [expression]",
+	Short: "Query domains in a scan database or saved result",
+	Long: `Query searches the SQLite database written by "discover --db", or a saved result
+file with -r. In the database each domain is reported as observed by the latest scan
+that found it (or by --scan), and filters combine with AND. Times accept durations
+such as 12h, 30d or 2w, or dates (2006-01-02).
+
+The optional expression filters domains by their fields:
+
+  reachable && status >= 400 && cert.expires < 30d && source == "certificate-san"
+
+Comparisons: == != < <= > >= and =~ !~ (regular expressions). Combine them with
+&& || ! (or and, or, not) and parentheses. Strings are quoted, durations are written
+like 12h, 30d or 2w. A field on its own tests that it is true or set. List fields
+such as source match when any value matches. Fields:
+
+` + filterFieldHelp() + `
+
+--sort orders by fields (prefix - for descending) and --fields selects the columns
+to print, as a table (-f text), csv, json or jsonl.`,
+	Example: `  # Live hosts with certificates expiring in the next 30 days
+  domain-scan query --db scans.db --live --expiring 30d
+
+  # Hosts first seen this week, as JSON lines
+  domain-scan query --db scans.db --first-seen 7d -f jsonl
+
+  # List stored scans
+  domain-scan query --db scans.db --scans
+
+  # Broken live hosts with soon-expiring certificates from a saved result
+  domain-scan query -r result/example.com/domains.json 'reachable && status >= 400 && cert.expires < 30d'
+
+  # Certificates by expiry date
+  domain-scan query -r domains.json 'cert' --sort cert.expires --fields domain,cert.expires,cert.issuer`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runQuery,
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+
+	queryCmd.Flags().StringVar(&queryDB, "db", "", "SQLite database written by discover --db (default: database.path from config)")
+	queryCmd.Flags().StringVarP(&queryResult, "result", "r", "", "Saved result file (domains.json or JSON output) to query instead of the database")
+	queryCmd.Flags().BoolVar(&queryScans, "scans", false, "List stored scans instead of domains")
+	queryCmd.Flags().Int64Var(&queryScanID, "scan", 0, "Report domains as observed by this scan ID (default: latest observation)")
+	queryCmd.Flags().BoolVar(&queryLive, "live", false, "Only reachable domains")
+	queryCmd.Flags().StringVar(&queryExpiring, "expiring", "", "Only domains whose certificate expires within this duration, including expired ones (e.g. 30d)")
+	queryCmd.Flags().StringVar(&queryFirstSeen, "first-seen", "", "Only domains first seen within this duration or since this date (e.g. 7d)")
+	queryCmd.Flags().StringVar(&queryLastSeen, "seen", "", "Only domains seen within this duration or since this date")
+	queryCmd.Flags().StringVar(&queryDomain, "domain", "", "Only this domain and its subdomains")
+	queryCmd.Flags().StringVar(&querySource, "source", "", "Only domains found by this source (e.g. certificate-san, subfinder)")
+	queryCmd.Flags().IntVar(&queryLimit, "limit", 0, "Maximum number of domains (0 = unlimited)")
+	queryCmd.Flags().StringVarP(&queryFormat, "format", "f", "text", fmt.Sprintf("Output format (%s)", strings.Join(output.Names(), ", ")))
+	queryCmd.Flags().StringVarP(&queryOutput, "output", "o", "", "Output file (default: stdout)")
+	queryCmd.Flags().StringVar(&querySort, "sort", "", "Sort by comma separated fields, - prefix for descending (e.g. -status,cert.expires)")
+	queryCmd.Flags().StringVar(&queryFields, "fields", "", "Comma separated fields to print (e.g. domain,status,cert.expires)")
+	queryCmd.MarkFlagsMutuallyExclusive("result", "db")
+}
+
+// runQuery opens the database or result file and prints the matching scans or domains
+func runQuery(cmd *cobra.Command, args []string) error {
+	expression := ""
+	if len(args) > 0 {
+		expression = args[0]
+	}
+	view, err := newResultView(expression, querySort, queryFields, queryFormat)
+	if err != nil {
+		return err
+	}
+
+	if queryResult != "" {
+		for _, name := range []string{"scans", "scan", "live", "expiring", "first-seen", "seen", "domain", "source", "limit"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s only applies to the database: use an expression with -r", name)
+			}
+		}
+		result, err := domainscan.LoadResult(queryResult)
+		if err != nil {
+			return err
+		}
+		return writeQueryOutput(view, result)
+	}
+
+	path := databasePath(cmd, queryDB)
+	if path == "" {
+		return fmt.Errorf("no database given: use --db or set database.path in the config file")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	db, err := store.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+	if queryScans {
+		return printScans(ctx, db)
+	}
+
+	q := store.Query{ScanID: queryScanID, Live: queryLive, Domain: queryDomain, Source: querySource, Limit: queryLimit}
+	now := time.Now()
+	if queryExpiring != "" {
+		within, err := utils.ParseDuration(queryExpiring)
+		if err != nil {
+			return fmt.Errorf("invalid --expiring: %w", err)
+		}
+		q.ExpiresBefore = now.Add(within)
+	}
+	if q.FirstSeenSince, err = parseSince(queryFirstSeen, now); err != nil {
+		return fmt.Errorf("invalid --first-seen: %w", err)
+	}
+	if q.LastSeenSince, err = parseSince(queryLastSeen, now); err != nil {
+		return fmt.Errorf("invalid --seen: %w", err)
+	}
+
+	records, err := db.Query(ctx, q)
+	if err != nil {
+		return err
+	}
+	return writeQueryOutput(view, store.Result(records))
+}
+
+// writeQueryOutput renders the query result to --output or stdout
+func writeQueryOutput(view *resultView, result *domainscan.AssetDiscoveryResult) error {
+	var buf bytes.Buffer
+	if err := view.render(&buf, queryFormat, result); err != nil {
+		return err
+	}
+	if queryOutput != "" {
+		return os.WriteFile(queryOutput, buf.Bytes(), 0600)
+	}
+	fmt.Print(buf.String())
+	return nil
+}
+
+// printScans prints a table of stored scans
+func printScans(ctx context.Context, db *store.Store) error {
+	scans, err := db.Scans(ctx)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTARTED\tDURATION\tDOMAINS\tLIVE\tTARGETS")
+	for _, scan := range scans {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\n", scan.ID, scan.StartedAt.Local().Format(time.DateTime),
+			scan.FinishedAt.Sub(scan.StartedAt), scan.TotalDomains, scan.LiveDomains, strings.Join(scan.Targets, ","))
+	}
+	return w.Flush()
+}
+
+// parseSince converts a duration ("7d") or date ("2006-01-02") into a point in time before now.
+// An empty value returns the zero time.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return date, nil
+	}
+	ago, err := utils.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a duration like 7d or a date like 2006-01-02, got %q", value)
+	}
+	return now.Add(-ago), nil
+}
+
+// resultView filters, sorts and selects the fields of a result before it is written
+type resultView struct {
+	expr    *filter.Expr
+	sort    string
+	columns []string
+}
+
+// newResultView validates a filter expression, sort order and field selection for format.
+// Sorting or selecting fields prints rows, so format must then be a row format.
+func newResultView(expression, sortSpec, fieldSpec, format string) (*resultView, error) {
+	view := &resultView{sort: sortSpec}
+	if strings.TrimSpace(expression) != "" {
+		expr, err := filter.Parse(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+		view.expr = expr
+	}
+	if err := filter.Sort(nil, sortSpec, time.Now()); err != nil {
+		return nil, fmt.Errorf("invalid --sort: %w", err)
+	}
+	columns, err := filter.ParseFields(fieldSpec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --fields: %w", err)
+	}
+	view.columns = columns
+	if sortSpec != "" && len(view.columns) == 0 {
+		view.columns = filter.DefaultColumns
+	}
+
+	if !view.rows() {
+		_, err = output.Get(format)
+		return view, err
+	}
+	for _, name := range filter.RowFormats() {
+		if strings.EqualFold(format, name) {
+			return view, nil
+		}
+	}
+	return nil, fmt.Errorf("format %q can't be used with --sort or --fields: must be one of %s", format, strings.Join(filter.RowFormats(), ", "))
+}
+
+// rows reports whether the view prints selected fields rather than a full result
+func (v *resultView) rows() bool {
+	return len(v.columns) > 0
+}
+
+// render filters the result and writes it in format
+func (v *resultView) render(w io.Writer, format string, result *domainscan.AssetDiscoveryResult) error {
+	now := time.Now()
+	filtered := filter.Apply(result, v.expr, now)
+	if !v.rows() {
+		return output.Write(w, format, filtered)
+	}
+	entries := filter.Entries(filtered)
+	if err := filter.Sort(entries, v.sort, now); err != nil {
+		return err
+	}
+	return filter.WriteRows(w, format, entries, v.columns, now)
+}
+
+// filterFieldHelp lists the filter fields for command help
+func filterFieldHelp() string {
+	var sb strings.Builder
+	for _, name := range filter.Fields() {
+		fmt.Fprintf(&sb, "  %-15s %s\n", name, filter.Describe(name))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// databasePath returns the --db flag value when set, otherwise database.path from the config.
+// The key isn't bound to the flag because discover and query both define --db.
+func databasePath(cmd *cobra.Command, flagValue string) string {
+	if cmd.Flags().Changed("db") {
+		return flagValue
+	}
+	return viper.GetString("database.path")
+}
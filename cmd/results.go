@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+// Result directory layouts
+const (
+	layoutSingle  = "single"   // {result-dir}/{first-target}/domains.json with every domain
+	layoutPerSeed = "per-seed" // {result-dir}/{seed}/domains.json per seed plus {result-dir}/index.json
+)
+
+// RunIndex is the index.json summarising a run saved with the per-seed layout
+type RunIndex struct {
+	Targets    []string                  `json:"targets"`     // Targets of the run
+	StartedAt  time.Time                 `json:"started_at"`  // When the scan started
+	FinishedAt time.Time                 `json:"finished_at"` // When the results were saved
+	Statistics domainscan.DiscoveryStats `json:"statistics"`  // Statistics of the whole run
+	Seeds      []SeedIndex               `json:"seeds"`       // One entry per seed directory
+}
+
+// SeedIndex summarises the results of one seed in a RunIndex
+type SeedIndex struct {
+	Seed       string                    `json:"seed"`       // Target the domains were reached from
+	Path       string                    `json:"path"`       // domains.json relative to the result directory
+	Statistics domainscan.DiscoveryStats `json:"statistics"` // Statistics of the seed's domains
+	Shared     int                       `json:"shared"`     // Domains also reached from other seeds
+}
+
+// seedDir returns the result directory name for a seed, keeping CIDR slashes out of paths
+func seedDir(seed string) string {
+	return strings.ReplaceAll(seed, "/", "_")
+}
+
+// domainsJSONPath returns {result-dir}/{seed}/domains.json
+func domainsJSONPath(seed string) string {
+	return filepath.Join(resultDir, seedDir(seed), "domains.json")
+}
+
+// saveResults writes the result directory in the selected --result-layout
+func saveResults(result *domainscan.AssetDiscoveryResult, targets []string, startedAt time.Time) error {
+	if resultLayout != layoutPerSeed {
+		domainsPath := domainsJSONPath(targets[0])
+		if err := createDomainsJSON(result, domainsPath); err != nil {
+			return err
+		}
+		// Print the full path as an end event
+		fmt.Printf("\nResults saved to: %s\n", domainsPath)
+		return nil
+	}
+
+	groups := result.BySeed()
+	index := buildRunIndex(result, groups, targets, startedAt)
+	for _, seed := range index.Seeds {
+		group := groups[seed.Seed]
+		if group == nil {
+			group = &domainscan.AssetDiscoveryResult{Domains: map[string]*domainscan.DomainEntry{}}
+		}
+		if err := createDomainsJSON(group, filepath.Join(resultDir, seed.Path)); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal index JSON: %w", err)
+	}
+	indexPath := filepath.Join(resultDir, "index.json")
+	if err := os.WriteFile(indexPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write index.json: %w", err)
+	}
+
+	fmt.Printf("\nResults for %d seeds saved to: %s\n", len(index.Seeds), indexPath)
+	return nil
+}
+
+// buildRunIndex summarises a run per seed. Every target gets an entry, even when nothing
+// was found for it, followed by seeds outside the targets such as Unattributed.
+func buildRunIndex(result *domainscan.AssetDiscoveryResult, groups map[string]*domainscan.AssetDiscoveryResult, targets []string, startedAt time.Time) *RunIndex {
+	seeds := append([]string(nil), targets...)
+	var extra []string
+	for seed := range groups {
+		if !slices.Contains(targets, seed) {
+			extra = append(extra, seed)
+		}
+	}
+	sort.Strings(extra)
+	seeds = append(seeds, extra...)
+
+	index := &RunIndex{
+		Targets:    targets,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+		Statistics: result.Statistics,
+		Seeds:      []SeedIndex{},
+	}
+	for _, seed := range seeds {
+		entry := SeedIndex{Seed: seed, Path: filepath.Join(seedDir(seed), "domains.json")}
+		if group := groups[seed]; group != nil {
+			entry.Statistics = group.Statistics
+			for _, domain := range group.Domains {
+				if len(domain.Seeds) > 1 {
+					entry.Shared++
+				}
+			}
+		}
+		index.Seeds = append(index.Seeds, entry)
+	}
+	return index
+}
+
+// loadPreviousResult reads the domains.json files the current layout is about to overwrite
+// and merges them. Returns nil when there's no previous result.
+func loadPreviousResult(targets []string) *domainscan.AssetDiscoveryResult {
+	seeds := targets[:1]
+	if resultLayout == layoutPerSeed {
+		seeds = targets
+	}
+
+	var previous *domainscan.AssetDiscoveryResult
+	for _, seed := range seeds {
+		loaded, err := domainscan.LoadResult(domainsJSONPath(seed))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintf(os.Stderr, "Warning: ignoring previous result: %v\n", err)
+			}
+			continue
+		}
+		if previous == nil {
+			previous = loaded
+			continue
+		}
+		for domain, entry := range loaded.Domains {
+			previous.Domains[domain] = entry
+		}
+	}
+	if previous != nil {
+		previous.UpdateStatistics()
+	}
+	return previous
+}
+
+// createDomainsJSON creates a structured domains.json file in the result directory.
+// Includes full domain information with sources and IPs
+func createDomainsJSON(result *domainscan.AssetDiscoveryResult, domainsPath string) error {
+	// Create result directory structure
+	if err := os.MkdirAll(filepath.Dir(domainsPath), 0750); err != nil {
+		return fmt.Errorf("failed to create result directory: %w", err)
+	}
+
+	// Create domain result structure with full entries
+	domainResult := DomainResult{
+		Domains: result.Domains,
+	}
+
+	// Marshal to JSON
+	output, err := json.MarshalIndent(domainResult, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal domains JSON: %w", err)
+	}
+
+	// Write to domains.json
+	if err := os.WriteFile(domainsPath, output, 0600); err != nil {
+		return fmt.Errorf("failed to write domains.json: %w", err)
+	}
+	return nil
+}
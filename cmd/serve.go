@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/valllabh/domain-scan/pkg/server"
+)
+
+var (
+	serveListen        string
+	serveToken         string
+	serveMaxConcurrent int
+	serveMaxQueued     int
+	serveScanTimeout   time.Duration
+)
+
+// serveCmd runs the REST API server
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the asynchronous REST API server",
+	Long: `Serve exposes domain asset discovery as an asynchronous REST API.
+
+Endpoints:
+  POST   /scans               Queue a scan: {"domains": ["example.com"], "keywords": ["staging"]}
+  GET    /scans               List scans
+  GET    /scans/{id}          Scan status and progress
+  GET    /scans/{id}/results  Discovered domains (?offset=0&limit=100)
+  DELETE /scans/{id}          Cancel a queued or running scan, or remove a finished one
+  GET    /health              Health check (no authentication)
+
+Requests must carry the API token as "Authorization: Bearer <token>" or
+"X-API-Token: <token>". The token is read from --token, the server.token config
+key or DOMAIN_SCAN_API_TOKEN; when none is set a random token is generated and
+printed at startup. Scans use the discovery settings from the config file.`,
+	Example: `  # Start the server and queue a scan
+  domain-scan serve --listen :8080 --token s3cret
+  curl -H "Authorization: Bearer s3cret" -d '{"domains": ["example.com"]}' http://localhost:8080/scans`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveListen, "listen", ":8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "API token required by clients (default: $DOMAIN_SCAN_API_TOKEN or generated)")
+	serveCmd.Flags().IntVar(&serveMaxConcurrent, "max-concurrent", server.DefaultMaxConcurrent, "Maximum scans running at the same time")
+	serveCmd.Flags().IntVar(&serveMaxQueued, "max-queued", server.DefaultMaxQueued, "Maximum scans waiting for a free slot")
+	serveCmd.Flags().DurationVar(&serveScanTimeout, "scan-timeout", server.DefaultScanTimeout, "Maximum duration of a single scan")
+
+	_ = viper.BindPFlag("server.listen", serveCmd.Flags().Lookup("listen"))
+	_ = viper.BindPFlag("server.token", serveCmd.Flags().Lookup("token"))
+	_ = viper.BindPFlag("server.max_concurrent", serveCmd.Flags().Lookup("max-concurrent"))
+	_ = viper.BindPFlag("server.max_queued", serveCmd.Flags().Lookup("max-queued"))
+	_ = viper.BindPFlag("server.scan_timeout", serveCmd.Flags().Lookup("scan-timeout"))
+}
+
+// runServe starts the API server and shuts it down gracefully on SIGINT/SIGTERM
+func runServe(cmd *cobra.Command, args []string) error {
+	config := loadDiscoveryConfig()
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	token := viper.GetString("server.token")
+	if token == "" {
+		token = os.Getenv("DOMAIN_SCAN_API_TOKEN")
+	}
+	if token == "" {
+		generated, err := generateToken()
+		if err != nil {
+			return err
+		}
+		token = generated
+		fmt.Fprintf(os.Stderr, "No API token configured, generated: %s\n", token)
+	}
+
+	api := server.New(server.Options{
+		Token:         token,
+		MaxConcurrent: viper.GetInt("server.max_concurrent"),
+		MaxQueued:     viper.GetInt("server.max_queued"),
+		ScanTimeout:   viper.GetDuration("server.scan_timeout"),
+		Config:        config,
+	})
+
+	httpServer := &http.Server{
+		Addr:              viper.GetString("server.listen"),
+		Handler:           api.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(os.Stderr, "domain-scan API listening on %s\n", httpServer.Addr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	fmt.Fprintln(os.Stderr, "Shutting down, cancelling running scans...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return shutdownServer(shutdownCtx, httpServer, api)
+}
+
+// shutdownServer cancels running scans before stopping the HTTP server. Event streams
+// only end when their job does, so stopping the HTTP server first would wait on them until ctx expires.
+func shutdownServer(ctx context.Context, httpServer *http.Server, api *server.Server) error {
+	apiErr := api.Shutdown(ctx)
+	return errors.Join(apiErr, httpServer.Shutdown(ctx))
+}
+
+// generateToken returns a random API token
+func generateToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
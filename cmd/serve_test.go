@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/server"
+)
+
+func TestShutdownServerClosesEventStreams(t *testing.T) {
+	cancelled := make(chan struct{})
+	api := server.New(server.Options{
+		Scan: func(ctx context.Context, config *domainscan.Config, req *domainscan.ScanRequest, progress domainscan.ProgressCallback) (*domainscan.AssetDiscoveryResult, error) {
+			progress.OnStart(req.Domains, nil)
+			<-ctx.Done()
+			close(cancelled)
+			return nil, ctx.Err()
+		},
+	})
+	srv := httptest.NewServer(api.Handler())
+	defer srv.Close()
+
+	info, err := api.Submit(server.CreateScanRequest{Domains: []string{"example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Hold an SSE subscription open on the running job
+	resp, err := srv.Client().Get(srv.URL + "/scans/" + info.ID + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("event stream ended before the scan started: %v", err)
+		}
+		if strings.HasPrefix(line, "event: start") {
+			break
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownServer(ctx, srv.Config, api); err != nil {
+		t.Fatalf("shutdownServer() error: %v", err)
+	}
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("Running scan was not cancelled")
+	}
+	if ctx.Err() != nil {
+		t.Error("Shutdown waited for the event stream until the deadline")
+	}
+	if _, err := srv.Client().Get(srv.URL + "/health"); err == nil {
+		t.Error("HTTP server still accepting requests after shutdown")
+	}
+}
@@ -26,7 +26,7 @@ var (
 
 func init() {
 	rootCmd.AddCommand(testcertCmd)
-	testcertCmd.Flags().StringSliceVar(&testCertKeywords, "keywords", []string{}, "Keywords to filter certificate domains (optional)")
+	testcertCmd.Flags().StringArrayVar(&testCertKeywords, "keywords", []string{}, "Keywords to filter certificate domains, repeatable or comma-separated (optional)")
 	testcertCmd.Flags().IntSliceVar(&testCertPorts, "ports", []int{443, 8443}, "Ports to scan for certificates")
 }
 
@@ -36,7 +36,7 @@ func runTestCert(cmd *cobra.Command, args []string) error {
 	logger := logging.GetLogger()
 
 	// Extract keywords from domains if not provided
-	keywords := utils.LoadKeywords(args, testCertKeywords)
+	keywords := utils.LoadKeywords(args, utils.SplitKeywords(testCertKeywords))
 
 	fmt.Printf("Testing certificate discovery\n")
 	fmt.Printf("Domains: %v\n", args)
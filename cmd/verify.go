@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"golang.org/x/term"
+)
+
+var (
+	verifyResultFile string
+	verifyOutput     string
+	verifyInPlace    bool
+)
+
+// verifyCmd re-probes the entries of a saved result without enumerating again
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Re-verify a saved result without re-enumerating",
+	Long: `Verify re-runs only the HTTP/TLS probe on every domain of a saved result
+(domains.json or --format json output). No passive sources are queried and no
+new domains are extracted from certificates, so it is fast and needs no API keys.
+
+The updated result is written as JSON with a "changes" list naming every field
+that changed per domain: reachable, status, url, ip, redirect, certificate and
+certificate_trust. The file stays loadable by export, explain and verify. A
+summary of the changes is printed to stderr.`,
+	Example: `  # Refresh liveness of yesterday's scan
+  domain-scan verify -r result/example.com/domains.json -o verified.json
+
+  # Update the saved result in place
+  domain-scan verify -r result/example.com/domains.json --in-place`,
+	Args: cobra.NoArgs,
+	RunE: runVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVarP(&verifyResultFile, "result", "r", "", "Saved result file (domains.json or JSON output)")
+	verifyCmd.Flags().StringVarP(&verifyOutput, "output", "o", "", "Output file for the updated result (default: stdout)")
+	verifyCmd.Flags().BoolVar(&verifyInPlace, "in-place", false, "Overwrite the result file with the updated result")
+	_ = verifyCmd.MarkFlagRequired("result")
+	verifyCmd.MarkFlagsMutuallyExclusive("output", "in-place")
+}
+
+// verifiedResult is the JSON written by verify: a loadable result plus its changes
+type verifiedResult struct {
+	Domains    map[string]*domainscan.DomainEntry `json:"domains"`
+	Statistics domainscan.DiscoveryStats          `json:"statistics"`
+	Changes    []domainscan.EntryChange           `json:"changes"`
+}
+
+// runVerify loads the result, re-probes it and writes the updated result
+func runVerify(cmd *cobra.Command, args []string) error {
+	result, err := domainscan.LoadResult(verifyResultFile)
+	if err != nil {
+		return err
+	}
+
+	config := loadDiscoveryConfig()
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Verifying %d domains from %s\n", len(result.Domains), verifyResultFile)
+	verified, err := domainscan.New(config).Verify(context.Background(), result)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(verifiedResult{
+		Domains:    verified.Result.Domains,
+		Statistics: verified.Result.Statistics,
+		Changes:    verified.Changes,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal verified result: %w", err)
+	}
+	data = append(data, '\n')
+
+	printChanges(os.Stderr, verified)
+
+	switch {
+	case verifyInPlace:
+		return os.WriteFile(verifyResultFile, data, 0600)
+	case verifyOutput != "":
+		return os.WriteFile(verifyOutput, data, 0600)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// printChanges summarises the changed fields, colouring previous values red and current
+// values green when w is a terminal
+func printChanges(w io.Writer, verified *domainscan.VerifyResult) {
+	colour := false
+	if f, ok := w.(*os.File); ok {
+		colour = os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(f.Fd())) // #nosec G115 - file descriptors fit in int
+	}
+	paint := func(code, value string) string {
+		if value == "" {
+			value = "-"
+		}
+		if !colour {
+			return value
+		}
+		return "\033[" + code + "m" + value + "\033[0m"
+	}
+
+	stats := verified.Result.Statistics
+	fmt.Fprintf(w, "%d of %d domains changed, %d live\n", len(verified.Changes), stats.TotalSubdomains, stats.ActiveServices)
+	for _, change := range verified.Changes {
+		var fields []string
+		for _, field := range change.Fields {
+			fields = append(fields, fmt.Sprintf("%s %s → %s", field.Field, paint("31", field.Previous), paint("32", field.Current)))
+		}
+		fmt.Fprintf(w, "  %s: %s\n", change.Domain, strings.Join(fields, ", "))
+	}
+}
@@ -0,0 +1,282 @@
+package discovery
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// DefaultPermutationWords are inserted around existing labels to guess
+// environment and version naming conventions (e.g. api -> api-staging, dev-api)
+var DefaultPermutationWords = []string{
+	"dev", "development", "stg", "stage", "staging", "prod", "production",
+	"test", "qa", "uat", "preprod", "sandbox", "demo", "internal", "old", "new",
+	"v1", "v2", "api", "admin",
+}
+
+// MaxActiveCandidates caps how many names a single active enumeration run resolves
+const MaxActiveCandidates = 100000
+
+// Resolver resolves hostnames to addresses. *net.Resolver satisfies it.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+var numberPattern = regexp.MustCompile(`\d+`)
+
+// GeneratePermutations derives candidate subdomains from known domains by inserting words
+// before and after the leftmost label (with '-' and '.' separators) and by incrementing
+// and decrementing numbers in it. Known domains and duplicates are excluded.
+func GeneratePermutations(domains []string, words []string) []string {
+	candidates, _ := generatePermutations(domains, words)
+	return candidates
+}
+
+// GeneratePermutationParents is GeneratePermutations returning each candidate mapped
+// to the known domain it was derived from
+func GeneratePermutationParents(domains []string, words []string) map[string]string {
+	_, parents := generatePermutations(domains, words)
+	return parents
+}
+
+// generatePermutations returns the permutation candidates in generation order and their parents
+func generatePermutations(domains []string, words []string) ([]string, map[string]string) {
+	known := make(map[string]bool, len(domains))
+	var sorted []string
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		if !known[domain] {
+			known[domain] = true
+			sorted = append(sorted, domain)
+		}
+	}
+	// Sorted so the candidates kept under MaxActiveCandidates don't vary between runs
+	sort.Strings(sorted)
+
+	parents := make(map[string]string)
+	var candidates []string
+	var domain string
+	add := func(candidate string) {
+		if _, seen := parents[candidate]; seen || known[candidate] || len(candidates) >= MaxActiveCandidates {
+			return
+		}
+		parents[candidate] = domain
+		candidates = append(candidates, candidate)
+	}
+
+	for _, domain = range sorted {
+		label, parent, ok := strings.Cut(domain, ".")
+		if !ok || label == "" || label == "*" || utils.RegistrableDomain(domain) == domain {
+			continue // Only permute subdomains, never the registrable domain itself
+		}
+
+		for _, word := range words {
+			word = strings.ToLower(strings.TrimSpace(word))
+			if word == "" || word == label {
+				continue
+			}
+			add(word + "-" + label + "." + parent)
+			add(label + "-" + word + "." + parent)
+			add(word + "." + label + "." + parent)
+			add(word + label + "." + parent)
+			add(label + word + "." + parent)
+		}
+
+		for _, loc := range numberPattern.FindAllStringIndex(label, -1) {
+			n, err := strconv.Atoi(label[loc[0]:loc[1]])
+			if err != nil {
+				continue
+			}
+			for _, delta := range []int{-1, 1, 2} {
+				if n+delta < 0 {
+					continue
+				}
+				add(label[:loc[0]] + strconv.Itoa(n+delta) + label[loc[1]:] + "." + parent)
+			}
+		}
+	}
+
+	return candidates, parents
+}
+
+// GenerateBruteforce combines wordlist entries with the registrable domain of every known domain
+func GenerateBruteforce(domains []string, words []string) []string {
+	apexes := make(map[string]bool)
+	var sortedApexes []string
+	known := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		known[domain] = true
+		if apex := utils.RegistrableDomain(domain); !apexes[apex] {
+			apexes[apex] = true
+			sortedApexes = append(sortedApexes, apex)
+		}
+	}
+	sort.Strings(sortedApexes)
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, apex := range sortedApexes {
+		for _, word := range words {
+			word = strings.ToLower(strings.Trim(strings.TrimSpace(word), "."))
+			if word == "" {
+				continue
+			}
+			candidate := word + "." + apex
+			if known[candidate] || seen[candidate] {
+				continue
+			}
+			if len(candidates) >= MaxActiveCandidates {
+				return candidates
+			}
+			seen[candidate] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// LoadWordlist reads words from a file, one per line, skipping blanks and '#' comments
+func LoadWordlist(path string) ([]string, error) {
+	file, err := os.Open(path) // #nosec G304 - user supplied wordlist
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wordlist: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, word)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read wordlist: %w", err)
+	}
+	return words, nil
+}
+
+// ResolveCandidates resolves candidate names and returns those that exist.
+// Wildcard DNS is detected per parent zone by resolving a random label; candidates
+// whose addresses are all wildcard answers are discarded. A nil resolver uses the system resolver.
+func ResolveCandidates(ctx context.Context, candidates []string, resolver Resolver, threads int, timeout time.Duration, logger *gologger.Logger) []string {
+	if len(candidates) == 0 {
+		return nil
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	if threads <= 0 {
+		threads = 50
+	}
+
+	if logger != nil {
+		logger.Info().Msgf("Resolving %d active enumeration candidates", len(candidates))
+	}
+
+	lookup := func(host string) []string {
+		lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		addrs, err := resolver.LookupHost(lookupCtx, host)
+		if err != nil {
+			return nil
+		}
+		return addrs
+	}
+
+	// forEach calls fn for every item with at most threads lookups in flight
+	var mutex sync.Mutex
+	forEach := func(items []string, fn func(item string)) {
+		var wg sync.WaitGroup
+		semaphore := make(chan struct{}, threads)
+		for _, item := range items {
+			wg.Add(1)
+			go func(item string) {
+				defer wg.Done()
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+				fn(item)
+			}(item)
+		}
+		wg.Wait()
+	}
+
+	// Detect wildcard answers for every parent zone
+	var parents []string
+	wildcards := make(map[string]map[string]bool)
+	for _, candidate := range candidates {
+		_, parent, _ := strings.Cut(candidate, ".")
+		if _, listed := wildcards[parent]; !listed {
+			wildcards[parent] = nil
+			parents = append(parents, parent)
+		}
+	}
+	forEach(parents, func(parent string) {
+		answers := make(map[string]bool)
+		for _, addr := range lookup(randomLabel() + "." + parent) {
+			answers[addr] = true
+		}
+		if len(answers) > 0 && logger != nil {
+			logger.Debug().Msgf("Wildcard DNS detected for *.%s", parent)
+		}
+		mutex.Lock()
+		wildcards[parent] = answers
+		mutex.Unlock()
+	})
+
+	var resolved []string
+	forEach(candidates, func(candidate string) {
+		addrs := lookup(candidate)
+		if len(addrs) == 0 {
+			return
+		}
+		_, parent, _ := strings.Cut(candidate, ".")
+		if wildcard := wildcards[parent]; len(wildcard) > 0 {
+			onlyWildcard := true
+			for _, addr := range addrs {
+				if !wildcard[addr] {
+					onlyWildcard = false
+					break
+				}
+			}
+			if onlyWildcard {
+				return
+			}
+		}
+
+		mutex.Lock()
+		resolved = append(resolved, candidate)
+		mutex.Unlock()
+	})
+
+	if logger != nil {
+		logger.Info().Msgf("Active enumeration resolved %d of %d candidates", len(resolved), len(candidates))
+	}
+
+	return resolved
+}
+
+// randomLabel returns a label that is practically guaranteed not to exist
+func randomLabel() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "wildcard-check-domain-scan"
+	}
+	return "ds-" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,183 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeResolver answers lookups from a static table, with optional wildcard zones
+type fakeResolver struct {
+	records   map[string][]string
+	wildcards map[string][]string
+}
+
+// concurrencyResolver fails every lookup after a short delay, recording the most lookups in flight at once
+type concurrencyResolver struct {
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (c *concurrencyResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return nil, errors.New("no such host")
+}
+
+func (f *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addrs, ok := f.records[host]; ok {
+		return addrs, nil
+	}
+	_, parent, _ := strings.Cut(host, ".")
+	if addrs, ok := f.wildcards[parent]; ok {
+		return addrs, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func TestGeneratePermutations(t *testing.T) {
+	candidates := GeneratePermutations([]string{"api-v2.example.com", "example.com", "example.co.uk"}, []string{"dev"})
+	set := make(map[string]bool)
+	for _, c := range candidates {
+		set[c] = true
+	}
+
+	expected := []string{
+		"dev-api-v2.example.com",
+		"api-v2-dev.example.com",
+		"dev.api-v2.example.com",
+		"api-v3.example.com",
+		"api-v1.example.com",
+	}
+	for _, e := range expected {
+		if !set[e] {
+			t.Errorf("Expected candidate %s not generated", e)
+		}
+	}
+
+	// The registrable domain itself is never permuted
+	if set["dev-example.com"] || set["dev.example.com"] || set["dev-example.co.uk"] || set["example-dev.co.uk"] {
+		t.Error("Registrable domain should not be permuted")
+	}
+	if set["api-v2.example.com"] {
+		t.Error("Known domains should not be returned as candidates")
+	}
+}
+
+func TestGeneratePermutationsOrder(t *testing.T) {
+	domains := []string{"web1.example.net", "api.example.com", "mail.example.org"}
+	expected := GeneratePermutations(domains, []string{"dev"})
+	if expected[0] != "dev-api.example.com" {
+		t.Errorf("Expected candidates of the first domain in sorted order first, got %v", expected[0])
+	}
+	for i := 0; i < 10; i++ {
+		got := GeneratePermutations([]string{domains[2], domains[0], domains[1]}, []string{"dev"})
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("Expected the same candidate order on every run, got %v and %v", expected, got)
+		}
+	}
+}
+
+func TestGeneratePermutationParents(t *testing.T) {
+	parents := GeneratePermutationParents([]string{"api.example.com", "web1.example.net"}, []string{"dev"})
+
+	expected := map[string]string{
+		"dev-api.example.com": "api.example.com",
+		"apidev.example.com":  "api.example.com",
+		"web2.example.net":    "web1.example.net",
+		"devweb1.example.net": "web1.example.net",
+	}
+	for candidate, parent := range expected {
+		if parents[candidate] != parent {
+			t.Errorf("Expected parent of %s to be %s, got %q", candidate, parent, parents[candidate])
+		}
+	}
+}
+
+func TestGenerateBruteforce(t *testing.T) {
+	candidates := GenerateBruteforce([]string{"api.example.co.uk", "www.example.co.uk"}, []string{"mail", "www", ""})
+	sort.Strings(candidates)
+
+	expected := []string{"mail.example.co.uk"}
+	if len(candidates) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, candidates)
+	}
+	for i := range expected {
+		if candidates[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, candidates)
+		}
+	}
+}
+
+func TestLoadWordlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("# words\nmail\n\n  vpn  \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	words, err := LoadWordlist(path)
+	if err != nil {
+		t.Fatalf("LoadWordlist() returned error: %v", err)
+	}
+	if len(words) != 2 || words[0] != "mail" || words[1] != "vpn" {
+		t.Errorf("Expected [mail vpn], got %v", words)
+	}
+
+	if _, err := LoadWordlist(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Expected error for missing wordlist")
+	}
+}
+
+func TestResolveCandidatesWildcard(t *testing.T) {
+	resolver := &fakeResolver{
+		records: map[string][]string{
+			"dev.example.com":      {"192.0.2.10"},
+			"api.wild.example.com": {"192.0.2.99"}, // Distinct from wildcard answer
+		},
+		wildcards: map[string][]string{
+			"wild.example.com": {"192.0.2.1"},
+		},
+	}
+
+	candidates := []string{
+		"dev.example.com",
+		"missing.example.com",
+		"api.wild.example.com",
+		"anything.wild.example.com",
+	}
+	resolved := ResolveCandidates(context.Background(), candidates, resolver, 4, time.Second, nil)
+	sort.Strings(resolved)
+
+	expected := []string{"api.wild.example.com", "dev.example.com"}
+	if len(resolved) != len(expected) || resolved[0] != expected[0] || resolved[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, resolved)
+	}
+}
+
+func TestResolveCandidatesPoolsWildcardChecks(t *testing.T) {
+	// One candidate per zone, so most lookups are wildcard checks
+	var candidates []string
+	for i := 0; i < 8; i++ {
+		candidates = append(candidates, fmt.Sprintf("www.zone%d.example.com", i))
+	}
+	resolver := &concurrencyResolver{}
+	ResolveCandidates(context.Background(), candidates, resolver, 4, time.Second, nil)
+	if resolver.peak < 2 || resolver.peak > 4 {
+		t.Errorf("Expected wildcard checks to run concurrently, at most 4 at a time; peak was %d", resolver.peak)
+	}
+}
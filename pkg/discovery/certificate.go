@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/projectdiscovery/goflags"
 	"github.com/projectdiscovery/gologger"
@@ -31,6 +32,29 @@ func addSource(entry *types.DomainEntry, name string, sourceType string) {
 // If extractNewDomains is false, it will load certificate info but NOT extract new domains from SANs
 // Returns: domain entries, new subdomains, map of subdomain->parent certificate info, error
 func BulkCertificateAnalysisForScanner(ctx context.Context, targets []string, keywords []string, extractNewDomains bool, logger *gologger.Logger) ([]*types.DomainEntry, []string, map[string]*types.CertificateInfo, error) {
+	matcher, err := utils.NewMatcher(keywords)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	domainEntries, subdomains, sanOrigins, err := BulkCertificateAnalysisWithProbe(ctx, targets, matcher, extractNewDomains, nil, logger)
+	sanCertificateMap := make(map[string]*types.CertificateInfo, len(sanOrigins))
+	for san, origin := range sanOrigins {
+		sanCertificateMap[san] = origin.Certificate
+	}
+	return domainEntries, subdomains, sanCertificateMap, err
+}
+
+// SANOrigin records the probed host that presented the certificate a SAN was found in
+type SANOrigin struct {
+	Host        string                 // Bare domain or IP of the probed target
+	Certificate *types.CertificateInfo // Certificate presented by Host
+}
+
+// BulkCertificateAnalysisWithProbe is BulkCertificateAnalysisForScanner with proxy, header and TLS settings
+// applied to the httpx runner and SANs filtered by a keyword matcher. A nil probe keeps the default
+// httpx behaviour and a nil matcher accepts every SAN.
+// Returns: domain entries, new subdomains, map of subdomain->presenting host and certificate, error
+func BulkCertificateAnalysisWithProbe(ctx context.Context, targets []string, matcher *utils.Matcher, extractNewDomains bool, probe *ProbeOptions, logger *gologger.Logger) ([]*types.DomainEntry, []string, map[string]SANOrigin, error) {
 	var domainEntries []*types.DomainEntry
 	var subdomains []string
 	var resultMutex sync.Mutex
@@ -38,11 +62,11 @@ func BulkCertificateAnalysisForScanner(ctx context.Context, targets []string, ke
 	// Map to track domain entries by target
 	domainEntriesMap := make(map[string]*types.DomainEntry)
 
-	// Map to track which certificate each SAN came from
-	sanCertificateMap := make(map[string]*types.CertificateInfo)
+	// Map to track which host and certificate each SAN came from
+	sanOrigins := make(map[string]SANOrigin)
 
 	if len(targets) == 0 {
-		return domainEntries, subdomains, sanCertificateMap, nil
+		return domainEntries, subdomains, sanOrigins, nil
 	}
 
 	if logger != nil {
@@ -52,13 +76,19 @@ func BulkCertificateAnalysisForScanner(ctx context.Context, targets []string, ke
 
 	// Pre-populate all targets as non-live domains with passive source
 	// httpx will update these if they respond
+	probedAt := time.Now()
 	for _, target := range targets {
-		bareDomain := utils.ExtractBareDomain(target)
+		bareDomain := utils.NormalizeTarget(target)
+		if bareDomain == "" {
+			continue
+		}
 		domainEntriesMap[bareDomain] = &types.DomainEntry{
-			Domain:    bareDomain,
-			Status:    0,
-			Reachable: false,
-			Sources:   []types.Source{{Name: "traced", Type: "passive"}},
+			Domain:        bareDomain,
+			UnicodeDomain: utils.ToUnicode(bareDomain),
+			Status:        0,
+			Reachable:     false,
+			Sources:       []types.Source{{Name: "traced", Type: "passive"}},
+			ProbedAt:      probedAt,
 		}
 	}
 
@@ -70,9 +100,10 @@ func BulkCertificateAnalysisForScanner(ctx context.Context, targets []string, ke
 		Timeout:         10,
 		Threads:         50, // Use reasonable thread count instead of len(targets)
 		TLSGrab:         true,
-		FollowRedirects: true, // Enable redirect following to capture FinalURL
-		MaxRedirects:    10,   // Follow up to 10 redirects
+		OutputCName:     true,                         // Record CNAME chains for takeover detection
+		ChainInStdout:   true,                         // Keep every redirect hop; the policy is set by applyToHTTPX
 		InputTargetHost: goflags.StringSlice(targets), // Use all targets in bulk
+		DisableStdin:    true,                         // Never read targets from the caller's stdin
 		OnResult: func(result runner.Result) {
 			resultMutex.Lock()
 			defer resultMutex.Unlock()
@@ -81,18 +112,24 @@ func BulkCertificateAnalysisForScanner(ctx context.Context, targets []string, ke
 				logger.Debug().Msgf("Processing result for %s: status=%d, error=%v", result.URL, result.StatusCode, result.Err)
 			}
 
-			// Extract bare domain from URL to use as map key
-			bareDomain := utils.ExtractBareDomain(result.URL)
+			// Extract bare domain from URL to use as map key, falling back to the
+			// probed input when a failed result has no URL
+			bareDomain := utils.NormalizeTarget(result.URL)
+			if bareDomain == "" {
+				bareDomain = utils.NormalizeTarget(result.Input)
+			}
 
 			// Get existing domain entry for this domain (should always exist due to pre-population)
 			domainEntry, exists := domainEntriesMap[bareDomain]
 			if !exists {
 				// Fallback if domain wasn't pre-populated
 				domainEntry = &types.DomainEntry{
-					Domain:    bareDomain,
-					Status:    0,
-					Reachable: false,
-					Sources:   []types.Source{{Name: "traced", Type: "passive"}},
+					Domain:        bareDomain,
+					UnicodeDomain: utils.ToUnicode(bareDomain),
+					Status:        0,
+					Reachable:     false,
+					Sources:       []types.Source{{Name: "traced", Type: "passive"}},
+					ProbedAt:      probedAt,
 				}
 				domainEntriesMap[bareDomain] = domainEntry
 			}
@@ -107,33 +144,14 @@ func BulkCertificateAnalysisForScanner(ctx context.Context, targets []string, ke
 				if len(result.A) > 0 {
 					domainEntry.IP = result.A[0] // Use first IPv4 address
 				}
+				domainEntry.CNAMEs = result.CNAMEs
 
-				// Capture redirect information if domain redirects
-				isRedirectStatus := result.StatusCode >= 300 && result.StatusCode < 400
-				hasRedirectChain := len(result.ChainStatusCodes) > 0
-
-				// Check if there's a redirect by looking at FinalURL, redirect status, or chain
-				if isRedirectStatus || hasRedirectChain || (result.FinalURL != "" && result.FinalURL != result.URL) {
-					finalURL := result.FinalURL
-					if finalURL == "" || finalURL == result.URL {
-						// If FinalURL is empty/same, use Location header or mark as unknown
-						finalURL = result.Location
-					}
-
-					statusCodes := result.ChainStatusCodes
-					if len(statusCodes) == 0 && isRedirectStatus {
-						statusCodes = []int{result.StatusCode}
-					}
-
-					domainEntry.Redirect = &types.RedirectInfo{
-						IsRedirect:  true,
-						RedirectsTo: finalURL,
-						StatusCodes: statusCodes,
-					}
-
+				// Capture redirect information, including every hop, if domain redirects
+				if redirect := redirectInfo(result); redirect != nil {
+					domainEntry.Redirect = redirect
 					if logger != nil {
 						logger.Debug().Msgf("Redirect detected: %s -> %s (codes: %v, chainLen: %d)",
-							result.URL, finalURL, statusCodes, len(result.Chain))
+							result.URL, redirect.RedirectsTo, redirect.StatusCodes, len(redirect.Chain))
 					}
 				}
 
@@ -166,11 +184,22 @@ func BulkCertificateAnalysisForScanner(ctx context.Context, targets []string, ke
 				if extractNewDomains {
 					// Filter SubjectANs based on keywords and collect subdomains
 					for _, san := range result.TLSData.SubjectAN {
-						if utils.MatchesKeywords(san, keywords) {
-							subdomains = append(subdomains, san)
-							// Track which certificate this SAN came from
-							sanCertificateMap[san] = domainEntry.Certificate
+						if san = utils.NormalizeTarget(san); san == "" {
+							continue // Not a valid host name
+						}
+						match := matcher.Explain(san)
+						if !match.Accepted {
+							if logger != nil {
+								logger.Debug().Msgf("Rejected SAN %s of %s: %s", san, bareDomain, match.Reason)
+							}
+							continue
+						}
+						if logger != nil {
+							logger.Debug().Msgf("Accepted SAN %s of %s: %s", san, bareDomain, match.Reason)
 						}
+						subdomains = append(subdomains, san)
+						// Track which host and certificate this SAN came from
+						sanOrigins[san] = SANOrigin{Host: bareDomain, Certificate: domainEntry.Certificate}
 					}
 
 					if logger != nil {
@@ -185,12 +214,15 @@ func BulkCertificateAnalysisForScanner(ctx context.Context, targets []string, ke
 		},
 	}
 
+	// Apply proxy, custom headers, SNI override and redirect policy
+	probe.applyToHTTPX(opts)
+
 	// Validate options before creating runner
 	if err := opts.ValidateOptions(); err != nil {
 		if logger != nil {
 			logger.Error().Msgf("Failed to validate httpx options: %v", err)
 		}
-		return domainEntries, subdomains, sanCertificateMap, fmt.Errorf("failed to validate httpx options: %v", err)
+		return domainEntries, subdomains, sanOrigins, fmt.Errorf("failed to validate httpx options: %v", err)
 	}
 
 	// Create and run httpx runner
@@ -199,7 +231,7 @@ func BulkCertificateAnalysisForScanner(ctx context.Context, targets []string, ke
 		if logger != nil {
 			logger.Error().Msgf("Failed to create httpx runner: %v", err)
 		}
-		return domainEntries, subdomains, sanCertificateMap, fmt.Errorf("failed to create httpx runner: %v", err)
+		return domainEntries, subdomains, sanOrigins, fmt.Errorf("failed to create httpx runner: %v", err)
 	}
 	defer httpxRunner.Close()
 
@@ -215,10 +247,13 @@ func BulkCertificateAnalysisForScanner(ctx context.Context, targets []string, ke
 		domainEntries = append(domainEntries, entry)
 	}
 
+	// Flag certificates that fail strict verification when VerifyTLS is set
+	verifyCertificates(ctx, domainEntries, probe, logger)
+
 	if logger != nil {
 		logger.Info().Msgf("Bulk analysis completed: %d domain entries, %d subdomains",
 			len(domainEntries), len(subdomains))
 	}
 
-	return domainEntries, subdomains, sanCertificateMap, nil
+	return domainEntries, subdomains, sanOrigins, nil
 }
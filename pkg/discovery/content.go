@@ -0,0 +1,263 @@
+package discovery
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// Places in a crawled response where related hostnames are found
+const (
+	ContentHTML       = "html"       // Links and resources referenced by HTML pages
+	ContentJavaScript = "javascript" // URLs embedded in JavaScript bundles
+	ContentCSP        = "csp"        // Content-Security-Policy allow-lists
+	ContentCORS       = "cors"       // Access-Control-Allow-Origin header
+)
+
+const (
+	maxContentBytes   = 2 << 20 // Response body bytes read from each page or script
+	maxScriptsPerPage = 10      // Same-site JavaScript bundles fetched from each page
+)
+
+var (
+	// contentURLPattern matches absolute and protocol-relative URLs, including the escaped
+	// slashes of URLs embedded in JSON and JavaScript strings
+	contentURLPattern = regexp.MustCompile(`(?i)(?:(?:https?|wss?):)?(?:\\?/){2}([a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)+)`)
+	scriptSrcPattern  = regexp.MustCompile(`(?i)<script[^>]+src\s*=\s*["']?([^"'\s>]+)`)
+	hostnamePattern   = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)*\.[a-z][a-z0-9-]*[a-z0-9]$`)
+)
+
+// ContentOrigin records where a hostname was found while crawling
+type ContentOrigin struct {
+	Host     string // Domain whose page referenced the hostname
+	URL      string // Page or script the hostname was found in
+	Location string // One of the Content* constants
+}
+
+// CrawlContent fetches the given pages (domain -> URL) and extracts the hostnames they
+// reference through links, Content-Security-Policy and CORS headers and same-site
+// JavaScript bundles. Returns a map of hostname -> where it was first found; the
+// crawled domains themselves are excluded. Pages are fetched with up to threads
+// concurrent requests, each bounded by timeout.
+func CrawlContent(ctx context.Context, pages map[string]string, probe *ProbeOptions, threads int, timeout time.Duration, logger *gologger.Logger) map[string]ContentOrigin {
+	hosts := make(map[string]ContentOrigin)
+	if len(pages) == 0 {
+		return hosts
+	}
+	if threads <= 0 {
+		threads = 50
+	}
+	client, err := probe.HTTPClient(timeout)
+	if err != nil {
+		if logger != nil {
+			logger.Warning().Msgf("Skipping content discovery: %v", err)
+		}
+		return hosts
+	}
+
+	if logger != nil {
+		logger.Info().Msgf("Crawling %d pages for referenced hosts", len(pages))
+	}
+
+	domains := make([]string, 0, len(pages))
+	for domain := range pages {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	// Collect per page and merge in domain order so the recorded origin is deterministic
+	found := make([][]ContentOrigin, len(domains))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, threads)
+	for i, domain := range domains {
+		wg.Add(1)
+		go func(i int, domain string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			found[i] = crawlPage(ctx, client, domain, pages[domain], logger)
+		}(i, domain)
+	}
+	wg.Wait()
+
+	crawled := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		crawled[domain] = true
+	}
+	for i, domain := range domains {
+		for _, origin := range found[i] {
+			name := origin.Host
+			if crawled[name] {
+				continue
+			}
+			if _, exists := hosts[name]; !exists {
+				origin.Host = domain
+				hosts[name] = origin
+			}
+		}
+	}
+
+	if logger != nil {
+		logger.Info().Msgf("Content discovery completed: %d hosts found", len(hosts))
+	}
+
+	return hosts
+}
+
+// crawlPage fetches a page and the same-site scripts it loads, returning the referenced
+// hostnames in the Host field of each origin
+func crawlPage(ctx context.Context, client *http.Client, domain string, pageURL string, logger *gologger.Logger) []ContentOrigin {
+	header, body, err := fetchContent(ctx, client, pageURL)
+	if err != nil {
+		if logger != nil {
+			logger.Debug().Msgf("Content fetch failed for %s: %v", pageURL, err)
+		}
+		return nil
+	}
+
+	var found []ContentOrigin
+	add := func(names []string, sourceURL string, location string) {
+		for _, name := range names {
+			found = append(found, ContentOrigin{Host: name, URL: sourceURL, Location: location})
+		}
+	}
+	for _, policy := range append(header.Values("Content-Security-Policy"), header.Values("Content-Security-Policy-Report-Only")...) {
+		add(hostsFromCSP(policy), pageURL, ContentCSP)
+	}
+	add(hostsFromCORS(header.Get("Access-Control-Allow-Origin")), pageURL, ContentCORS)
+
+	if !strings.Contains(strings.ToLower(header.Get("Content-Type")), "html") {
+		add(hostsFromBody(body), pageURL, ContentJavaScript)
+		return found
+	}
+	add(hostsFromBody(body), pageURL, ContentHTML)
+
+	for _, scriptURL := range sameSiteScripts(pageURL, domain, body) {
+		_, script, err := fetchContent(ctx, client, scriptURL)
+		if err != nil {
+			if logger != nil {
+				logger.Debug().Msgf("Script fetch failed for %s: %v", scriptURL, err)
+			}
+			continue
+		}
+		add(hostsFromBody(script), scriptURL, ContentJavaScript)
+	}
+	return found
+}
+
+// fetchContent requests a URL and returns its headers and at most maxContentBytes of its body
+func fetchContent(ctx context.Context, client *http.Client, rawURL string) (http.Header, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxContentBytes))
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Header, body, nil
+}
+
+// hostsFromBody returns the hostnames of the absolute and protocol-relative URLs in an
+// HTML page or script
+func hostsFromBody(body []byte) []string {
+	var names []string
+	for _, match := range contentURLPattern.FindAllSubmatch(body, -1) {
+		names = append(names, string(match[1]))
+	}
+	return contentHostnames(names)
+}
+
+// hostsFromCSP returns the hosts allowed by a Content-Security-Policy, e.g. the
+// "*.example-cdn.com" and "https://api.example.com" sources of "script-src 'self' ..."
+func hostsFromCSP(policy string) []string {
+	var names []string
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, source := range fields[1:] {
+			if strings.HasPrefix(source, "'") || strings.HasSuffix(source, ":") {
+				continue // Keywords, nonces, hashes and scheme sources
+			}
+			names = append(names, source)
+		}
+	}
+	return contentHostnames(names)
+}
+
+// hostsFromCORS returns the host of an Access-Control-Allow-Origin value
+func hostsFromCORS(origin string) []string {
+	origin = strings.TrimSpace(origin)
+	if origin == "" || origin == "*" || origin == "null" {
+		return nil
+	}
+	return contentHostnames([]string{origin})
+}
+
+// sameSiteScripts returns the scripts loaded by an HTML page that are served from the
+// page's host or the registrable domain of the crawled domain, resolved against the page URL
+func sameSiteScripts(pageURL string, domain string, body []byte) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	site := utils.RegistrableDomain(domain)
+	seen := make(map[string]bool)
+	var scripts []string
+	for _, match := range scriptSrcPattern.FindAllSubmatch(body, -1) {
+		ref, err := url.Parse(string(match[1]))
+		if err != nil {
+			continue
+		}
+		script := base.ResolveReference(ref)
+		if script.Scheme != "http" && script.Scheme != "https" {
+			continue
+		}
+		host := strings.ToLower(script.Hostname())
+		if (host != strings.ToLower(base.Hostname()) && utils.RegistrableDomain(host) != site) || seen[script.String()] {
+			continue
+		}
+		seen[script.String()] = true
+		scripts = append(scripts, script.String())
+		if len(scripts) == maxScriptsPerPage {
+			break
+		}
+	}
+	return scripts
+}
+
+// contentHostnames normalises URLs, origins and CSP host sources to unique hostnames,
+// dropping wildcards' "*." prefix, IP addresses and strings that aren't hostnames
+func contentHostnames(values []string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, value := range values {
+		value = strings.ReplaceAll(value, `\/`, "/")
+		if idx := strings.Index(value, "//"); idx != -1 {
+			value = value[idx+2:]
+		}
+		name := utils.NormalizeTarget(strings.TrimPrefix(value, "*."))
+		if seen[name] || utils.IsIPTarget(name) || !hostnamePattern.MatchString(name) {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,69 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCrawlContent(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'self' 'nonce-abc' *.cdn.example-static.com https:; connect-src https://api.example.com wss://ws.example.com:8443")
+		w.Header().Set("Access-Control-Allow-Origin", "https://portal.example.com")
+		_, _ = w.Write([]byte(`<html><head>
+<link rel="stylesheet" href="https://assets.example.com/site.css">
+<script src="/static/app.js"></script>
+<script src="https://cdn.thirdparty.net/lib.js"></script>
+</head><body><a href="//blog.example.com/post">Blog</a> <a href="/about">About</a> <img src="https://10.0.0.5/x.png"></body></html>`))
+	})
+	mux.HandleFunc("/static/app.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		_, _ = w.Write([]byte(`var cfg={"graphql":"https:\/\/graphql.example-internal.com\/v1"};fetch("https://api.example.com/me")`))
+	})
+	mux.HandleFunc("/lib.js", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Third-party scripts must not be fetched")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	hosts := CrawlContent(context.Background(), map[string]string{"127.0.0.1": server.URL}, nil, 2, 5*time.Second, nil)
+
+	expected := map[string]ContentOrigin{
+		"cdn.example-static.com":       {Host: "127.0.0.1", URL: server.URL, Location: ContentCSP},
+		"api.example.com":              {Host: "127.0.0.1", URL: server.URL, Location: ContentCSP},
+		"ws.example.com":               {Host: "127.0.0.1", URL: server.URL, Location: ContentCSP},
+		"portal.example.com":           {Host: "127.0.0.1", URL: server.URL, Location: ContentCORS},
+		"assets.example.com":           {Host: "127.0.0.1", URL: server.URL, Location: ContentHTML},
+		"cdn.thirdparty.net":           {Host: "127.0.0.1", URL: server.URL, Location: ContentHTML},
+		"blog.example.com":             {Host: "127.0.0.1", URL: server.URL, Location: ContentHTML},
+		"graphql.example-internal.com": {Host: "127.0.0.1", URL: server.URL + "/static/app.js", Location: ContentJavaScript},
+	}
+	if !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("CrawlContent() =\n%v\nwant\n%v", hosts, expected)
+	}
+}
+
+func TestHostsFromCSP(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		expected []string
+	}{
+		{name: "keywords and schemes only", policy: "default-src 'self' data: blob:; img-src *", expected: nil},
+		{name: "wildcards ports and paths", policy: "script-src *.Example.com:443 https://static.example.net/js/; report-uri /csp", expected: []string{"example.com", "static.example.net"}},
+		{name: "duplicates across directives", policy: "script-src api.example.com; connect-src https://api.example.com", expected: []string{"api.example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hostsFromCSP(tt.policy); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("hostsFromCSP(%q) = %v, want %v", tt.policy, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package discovery
+
+import (
+	"context"
+	"strings"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// IPCertificateDiscovery grabs TLS certificates presented directly on IP addresses
+// and returns the SAN domain names they contain. Wildcard SANs are skipped.
+// Returns: unique SAN domains, map of SAN->presenting IP and certificate info, error
+func IPCertificateDiscovery(ctx context.Context, ips []string, probe *ProbeOptions, logger *gologger.Logger) ([]string, map[string]SANOrigin, error) {
+	if len(ips) == 0 {
+		return nil, map[string]SANOrigin{}, nil
+	}
+
+	if logger != nil {
+		logger.Info().Msgf("Grabbing TLS certificates from %d IP seeds", len(ips))
+	}
+
+	// No keyword filtering: certificates served from seeded IPs define the scope
+	_, sans, sanOrigins, err := BulkCertificateAnalysisWithProbe(ctx, ips, nil, true, probe, logger)
+	if err != nil {
+		return nil, sanOrigins, err
+	}
+
+	domains := ipSeedDomains(sans, sanOrigins)
+
+	if logger != nil {
+		logger.Info().Msgf("IP certificate discovery found %d domains", len(domains))
+	}
+
+	return domains, sanOrigins, nil
+}
+
+// ipSeedDomains normalises and deduplicates SANs, skipping wildcards and IP addresses.
+// Origins recorded under a SAN's raw spelling are copied to its normalised name.
+func ipSeedDomains(sans []string, sanOrigins map[string]SANOrigin) []string {
+	seen := make(map[string]bool)
+	var domains []string
+	for _, san := range sans {
+		domain := utils.NormalizeTarget(san)
+		if domain == "" || seen[domain] || utils.IsIPTarget(domain) || strings.Contains(domain, "*") {
+			continue
+		}
+		seen[domain] = true
+		domains = append(domains, domain)
+		if origin, ok := sanOrigins[san]; ok && domain != san {
+			sanOrigins[domain] = origin
+		}
+	}
+	return domains
+}
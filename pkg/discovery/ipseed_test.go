@@ -0,0 +1,27 @@
+package discovery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+func TestIPSeedDomains(t *testing.T) {
+	cert := &types.CertificateInfo{Subject: "www.example.com"}
+	sanOrigins := map[string]SANOrigin{
+		"WWW.Example.com.": {Host: "192.0.2.10", Certificate: cert},
+		"api.example.com":  {Host: "192.0.2.11"},
+	}
+	sans := []string{"WWW.Example.com.", "www.example.com", "*.example.com", "192.0.2.10", "api.example.com", "exa mple.com", ""}
+
+	domains := ipSeedDomains(sans, sanOrigins)
+
+	expected := []string{"www.example.com", "api.example.com"}
+	if !reflect.DeepEqual(domains, expected) {
+		t.Errorf("Expected %v, got %v", expected, domains)
+	}
+	if origin := sanOrigins["www.example.com"]; origin.Host != "192.0.2.10" || origin.Certificate != cert {
+		t.Errorf("Expected the origin to follow the normalised name, got %+v", origin)
+	}
+}
@@ -0,0 +1,336 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// Lookalike generation techniques
+const (
+	LookalikeOmission      = "omission"      // A character left out: exmple.com
+	LookalikeRepetition    = "repetition"    // A character typed twice: exaample.com
+	LookalikeTransposition = "transposition" // Adjacent characters swapped: exmaple.com
+	LookalikeReplacement   = "replacement"   // A character replaced by a neighbouring key: exanple.com
+	LookalikeInsertion     = "insertion"     // A neighbouring key typed next to a character: exampkle.com
+	LookalikeHomoglyph     = "homoglyph"     // Look-alike characters: examp1e.com, exarnple.com
+	LookalikeBitflip       = "bitflip"       // A single bit flipped in memory or transit: exampme.com
+	LookalikeHyphenation   = "hyphenation"   // A hyphen inserted: exam-ple.com
+	LookalikeTLDSwap       = "tld-swap"      // The same name under another suffix: example.net
+)
+
+// LookalikeTechniques lists every generation technique in generation order
+var LookalikeTechniques = []string{
+	LookalikeOmission, LookalikeRepetition, LookalikeTransposition, LookalikeReplacement,
+	LookalikeInsertion, LookalikeHomoglyph, LookalikeBitflip, LookalikeHyphenation, LookalikeTLDSwap,
+}
+
+// Lookalike is a generated variant of a domain
+type Lookalike struct {
+	Domain    string // Variant registrable domain
+	Original  string // Registrable domain it imitates
+	Technique string // One of the Lookalike* techniques
+}
+
+// qwertyNeighbours lists the keys surrounding each key of a QWERTY keyboard
+var qwertyNeighbours = map[rune]string{
+	'1': "2q", '2': "13wq", '3': "24ew", '4': "35re", '5': "46tr", '6': "57yt", '7': "68uy", '8': "79iu", '9': "80oi", '0': "9po",
+	'q': "12wa", 'w': "23qeas", 'e': "34wrsd", 'r': "45etdf", 't': "56ryfg", 'y': "67tugh", 'u': "78yihj", 'i': "89uojk", 'o': "90ipkl", 'p': "0ol",
+	'a': "qwsz", 's': "weadzx", 'd': "ersfxc", 'f': "rtdgcv", 'g': "tyfhvb", 'h': "yugjbn", 'j': "uihknm", 'k': "iojlm", 'l': "opk",
+	'z': "asx", 'x': "zsdc", 'c': "xdfv", 'v': "cfgb", 'b': "vghn", 'n': "bhjm", 'm': "njk",
+}
+
+// asciiHomoglyphs lists the ASCII spellings that read like a character or character pair
+var asciiHomoglyphs = map[string][]string{
+	"o": {"0"}, "0": {"o"}, "l": {"1", "i"}, "i": {"1", "l"}, "1": {"l", "i"},
+	"e": {"3"}, "s": {"5"}, "g": {"q", "9"}, "q": {"g"}, "b": {"6"}, "z": {"2"},
+	"m": {"rn"}, "w": {"vv"}, "d": {"cl"}, "rn": {"m"}, "vv": {"w"}, "cl": {"d"},
+}
+
+// unicodeConfusables lists the Cyrillic and Greek letters rendered like a Latin letter.
+// Variants using them are internationalised names and are emitted in punycode.
+var unicodeConfusables = map[rune][]rune{
+	'a': {'а', 'α'}, 'c': {'с'}, 'd': {'ԁ'}, 'e': {'е', 'ε'}, 'h': {'һ'}, 'i': {'і', 'ι'}, 'j': {'ј'},
+	'k': {'κ'}, 'o': {'о', 'ο'}, 'p': {'р', 'ρ'}, 'q': {'ԛ'}, 's': {'ѕ'}, 't': {'τ'}, 'v': {'ν'},
+	'w': {'ԝ'}, 'x': {'х'}, 'y': {'у'},
+}
+
+// latinConfusables maps each confusable back to its Latin letter, so the Cyrillic or Greek
+// letters of an internationalised name get Latin variants
+var latinConfusables = func() map[rune]rune {
+	latin := make(map[rune]rune)
+	for letter, confusables := range unicodeConfusables {
+		for _, confusable := range confusables {
+			latin[confusable] = letter
+		}
+	}
+	return latin
+}()
+
+var labelPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// GenerateLookalikes returns the typo, homoglyph, bit-flip, hyphenation and TLD-swap
+// variants of the registrable domain of domain. Variants change the label before the
+// public suffix, in its Unicode form for internationalised names; TLD swaps keep it and
+// use each of tlds instead. techniques limits the techniques used (empty = all). Every
+// variant is a valid, unique ASCII (punycode) domain other than the original.
+func GenerateLookalikes(domain string, tlds []string, techniques []string) []Lookalike {
+	original := utils.RegistrableDomain(utils.NormalizeTarget(domain))
+	asciiName, suffix, ok := strings.Cut(original, ".")
+	if !ok || asciiName == "" || suffix == "" {
+		return nil
+	}
+	name := asciiName
+	if unicode := utils.ToUnicode(asciiName); unicode != "" {
+		name = unicode
+	}
+	runes := []rune(name)
+
+	enabled := make(map[string]bool)
+	for _, technique := range techniques {
+		enabled[technique] = true
+	}
+	use := func(technique string) bool { return len(enabled) == 0 || enabled[technique] }
+
+	seen := map[string]bool{original: true}
+	var variants []Lookalike
+	add := func(technique string, label string, tld string) {
+		encoded, err := utils.ToASCII(label)
+		if err != nil || !labelPattern.MatchString(encoded) {
+			return // Invalid labels, including Unicode ones IDNA rejects
+		}
+		if encoded == label && len(label) >= 4 && label[2:4] == "--" {
+			return // The reserved "xn--"-style prefix on an ASCII label
+		}
+		candidate := encoded + "." + tld
+		if seen[candidate] {
+			return
+		}
+		seen[candidate] = true
+		variants = append(variants, Lookalike{Domain: candidate, Original: original, Technique: technique})
+	}
+	addLabel := func(technique string, label string) { add(technique, label, suffix) }
+	// replace returns the label with the runes in [i, j) replaced by with
+	replace := func(i int, j int, with string) string {
+		return string(runes[:i]) + with + string(runes[j:])
+	}
+
+	if use(LookalikeOmission) {
+		for i := range runes {
+			addLabel(LookalikeOmission, replace(i, i+1, ""))
+		}
+	}
+	if use(LookalikeRepetition) {
+		for i := range runes {
+			addLabel(LookalikeRepetition, replace(i, i, string(runes[i])))
+		}
+	}
+	if use(LookalikeTransposition) {
+		for i := 0; i < len(runes)-1; i++ {
+			addLabel(LookalikeTransposition, replace(i, i+2, string(runes[i+1])+string(runes[i])))
+		}
+	}
+	if use(LookalikeReplacement) {
+		for i, c := range runes {
+			for _, key := range qwertyNeighbours[c] {
+				addLabel(LookalikeReplacement, replace(i, i+1, string(key)))
+			}
+		}
+	}
+	if use(LookalikeInsertion) {
+		for i, c := range runes {
+			for _, key := range qwertyNeighbours[c] {
+				addLabel(LookalikeInsertion, replace(i, i, string(key)))
+				addLabel(LookalikeInsertion, replace(i+1, i+1, string(key)))
+			}
+		}
+	}
+	if use(LookalikeHomoglyph) {
+		glyphs := make([]string, 0, len(asciiHomoglyphs))
+		for glyph := range asciiHomoglyphs {
+			glyphs = append(glyphs, glyph)
+		}
+		sort.Strings(glyphs)
+		for _, glyph := range glyphs {
+			replacements := asciiHomoglyphs[glyph]
+			for i := 0; i+len(glyph) <= len(runes); i++ {
+				if string(runes[i:i+len(glyph)]) != glyph {
+					continue
+				}
+				for _, replacement := range replacements {
+					addLabel(LookalikeHomoglyph, replace(i, i+len(glyph), replacement))
+				}
+			}
+		}
+		for i, c := range runes {
+			for _, confusable := range unicodeConfusables[c] {
+				addLabel(LookalikeHomoglyph, replace(i, i+1, string(confusable)))
+			}
+			if latin, ok := latinConfusables[c]; ok {
+				addLabel(LookalikeHomoglyph, replace(i, i+1, string(latin)))
+			}
+		}
+	}
+	if use(LookalikeBitflip) {
+		for i, c := range runes {
+			if c >= 0x80 {
+				continue // Flipping a bit of a multi-byte character rarely yields a valid one
+			}
+			for bit := 0; bit < 8; bit++ {
+				flipped := rune(byte(c) ^ (1 << bit))
+				if (flipped >= 'a' && flipped <= 'z') || (flipped >= '0' && flipped <= '9') || flipped == '-' {
+					addLabel(LookalikeBitflip, replace(i, i+1, string(flipped)))
+				}
+			}
+		}
+	}
+	if use(LookalikeHyphenation) {
+		for i := 1; i < len(runes); i++ {
+			addLabel(LookalikeHyphenation, replace(i, i, "-"))
+		}
+	}
+	if use(LookalikeTLDSwap) {
+		for _, tld := range tlds {
+			tld = strings.Trim(strings.ToLower(strings.TrimSpace(tld)), ".")
+			if tld != "" && tld != suffix {
+				add(LookalikeTLDSwap, name, tld)
+			}
+		}
+	}
+	return variants
+}
+
+// DNSResolver resolves the address and name server records used to tell whether a
+// domain is registered. *net.Resolver satisfies it.
+type DNSResolver interface {
+	Resolver
+	LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+}
+
+// Registration is the DNS footprint of a registered domain
+type Registration struct {
+	IPs         []string // A and AAAA answers; empty when the domain doesn't resolve
+	NameServers []string // Delegated name servers
+}
+
+// CheckRegistrations looks up every domain and returns the registered ones: those with
+// address records or a name server delegation. Suffixes answering for any name
+// (wildcard TLDs) are detected with a random label, and answers identical to the
+// wildcard's are ignored. A nil resolver uses the system resolver.
+func CheckRegistrations(ctx context.Context, domains []string, resolver DNSResolver, threads int, timeout time.Duration, logger *gologger.Logger) map[string]Registration {
+	registered := make(map[string]Registration)
+	if len(domains) == 0 {
+		return registered
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	if threads <= 0 {
+		threads = 50
+	}
+
+	if logger != nil {
+		logger.Info().Msgf("Checking registration of %d lookalike domains", len(domains))
+	}
+
+	lookup := func(domain string) Registration {
+		lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		var registration Registration
+		if addrs, err := resolver.LookupHost(lookupCtx, domain); err == nil {
+			registration.IPs = addrs
+		}
+		if servers, err := resolver.LookupNS(lookupCtx, domain); err == nil {
+			for _, server := range servers {
+				registration.NameServers = append(registration.NameServers, strings.ToLower(strings.TrimSuffix(server.Host, ".")))
+			}
+		}
+		return registration
+	}
+
+	// forEach calls fn for every item with at most threads lookups in flight
+	var mutex sync.Mutex
+	forEach := func(items []string, fn func(item string)) {
+		var wg sync.WaitGroup
+		semaphore := make(chan struct{}, threads)
+		for _, item := range items {
+			wg.Add(1)
+			go func(item string) {
+				defer wg.Done()
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+				fn(item)
+			}(item)
+		}
+		wg.Wait()
+	}
+
+	// Detect suffixes that answer for every name
+	var suffixes []string
+	wildcards := make(map[string]Registration)
+	for _, domain := range domains {
+		_, suffix, _ := strings.Cut(domain, ".")
+		if _, listed := wildcards[suffix]; !listed {
+			wildcards[suffix] = Registration{}
+			suffixes = append(suffixes, suffix)
+		}
+	}
+	forEach(suffixes, func(suffix string) {
+		wildcard := lookup(randomLabel() + "." + suffix)
+		if (len(wildcard.IPs) > 0 || len(wildcard.NameServers) > 0) && logger != nil {
+			logger.Debug().Msgf("Wildcard DNS detected for *.%s", suffix)
+		}
+		mutex.Lock()
+		wildcards[suffix] = wildcard
+		mutex.Unlock()
+	})
+
+	forEach(domains, func(domain string) {
+		registration := lookup(domain)
+		_, suffix, _ := strings.Cut(domain, ".")
+		wildcard := wildcards[suffix]
+		if sameSet(registration.IPs, wildcard.IPs) {
+			registration.IPs = nil
+		}
+		if sameSet(registration.NameServers, wildcard.NameServers) {
+			registration.NameServers = nil
+		}
+		if len(registration.IPs) == 0 && len(registration.NameServers) == 0 {
+			return
+		}
+
+		mutex.Lock()
+		registered[domain] = registration
+		mutex.Unlock()
+	})
+
+	if logger != nil {
+		logger.Info().Msgf("Lookalike check found %d of %d domains registered", len(registered), len(domains))
+	}
+
+	return registered
+}
+
+// sameSet reports whether two non-empty lists hold the same values
+func sameSet(a []string, b []string) bool {
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+	values := make(map[string]bool, len(b))
+	for _, value := range b {
+		values[value] = true
+	}
+	for _, value := range a {
+		if !values[value] {
+			return false
+		}
+	}
+	return true
+}
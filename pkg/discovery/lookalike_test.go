@@ -0,0 +1,170 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+func TestGenerateLookalikes(t *testing.T) {
+	variants := GenerateLookalikes("www.Example.com", []string{"com", "net", "co.uk"}, nil)
+	byDomain := make(map[string]string)
+	for _, variant := range variants {
+		if variant.Original != "example.com" {
+			t.Errorf("Expected example.com as the imitated domain, got %+v", variant)
+		}
+		if _, duplicate := byDomain[variant.Domain]; duplicate {
+			t.Errorf("Duplicate variant %s", variant.Domain)
+		}
+		byDomain[variant.Domain] = variant.Technique
+	}
+
+	expected := map[string]string{
+		"exmple.com":    LookalikeOmission,
+		"exaample.com":  LookalikeRepetition,
+		"exmaple.com":   LookalikeTransposition,
+		"exanple.com":   LookalikeReplacement,
+		"exampkle.com":  LookalikeInsertion,
+		"examp1e.com":   LookalikeHomoglyph,
+		"exarnple.com":  LookalikeHomoglyph,
+		"axample.com":   LookalikeBitflip,
+		"exam-ple.com":  LookalikeHyphenation,
+		"example.net":   LookalikeTLDSwap,
+		"example.co.uk": LookalikeTLDSwap,
+	}
+	for domain, technique := range expected {
+		if byDomain[domain] != technique {
+			t.Errorf("Expected %s via %s, got %q", domain, technique, byDomain[domain])
+		}
+	}
+	for _, invalid := range []string{"example.com", "-example.com", "example-.com", "example.com.com"} {
+		if _, exists := byDomain[invalid]; exists {
+			t.Errorf("Unexpected variant %s", invalid)
+		}
+	}
+}
+
+func TestGenerateLookalikesIDN(t *testing.T) {
+	byDomain := make(map[string]string)
+	for _, variant := range GenerateLookalikes("Bücher.de", []string{"at"}, nil) {
+		if variant.Original != "xn--bcher-kva.de" {
+			t.Errorf("Expected xn--bcher-kva.de as the imitated domain, got %+v", variant)
+		}
+		label, _, _ := strings.Cut(variant.Domain, ".")
+		if strings.HasPrefix(label, "xn--") && utils.ToUnicode(variant.Domain) == "" {
+			t.Errorf("Variant %s is not valid punycode", variant.Domain)
+		} else if !strings.HasPrefix(label, "xn--") && strings.Contains(label, "--") {
+			t.Errorf("Variant %s edits the punycode form instead of the Unicode label", variant.Domain)
+		}
+		byDomain[variant.Domain] = variant.Technique
+	}
+
+	punycode := func(domain string) string {
+		ascii, err := utils.ToASCII(domain)
+		if err != nil {
+			t.Fatalf("ToASCII(%q) returned error: %v", domain, err)
+		}
+		return ascii
+	}
+	expected := map[string]string{
+		"bcher.de":             LookalikeOmission,
+		punycode("büchr.de"):   LookalikeOmission,
+		punycode("büccher.de"): LookalikeRepetition,
+		punycode("bücehr.de"):  LookalikeTransposition,
+		punycode("büсher.de"):  LookalikeHomoglyph, // Cyrillic с
+		punycode("bü-cher.de"): LookalikeHyphenation,
+		"xn--bcher-kva.at":     LookalikeTLDSwap,
+		punycode("bücher.de"):  "",
+	}
+	for domain, technique := range expected {
+		if byDomain[domain] != technique {
+			t.Errorf("Expected %s via %q, got %q", domain, technique, byDomain[domain])
+		}
+	}
+
+	// Latin letters get Cyrillic and Greek confusables, and confusables get their Latin letter
+	latin := make(map[string]bool)
+	for _, variant := range GenerateLookalikes("example.com", nil, []string{LookalikeHomoglyph}) {
+		latin[variant.Domain] = true
+	}
+	if !latin[punycode("еxample.com")] || !latin[punycode("exαmple.com")] {
+		t.Errorf("Expected Cyrillic and Greek homoglyphs of example.com, got %v", latin)
+	}
+	mixed := make(map[string]bool)
+	for _, variant := range GenerateLookalikes("pаypal.com", nil, []string{LookalikeHomoglyph}) {
+		mixed[variant.Domain] = true
+	}
+	if !mixed["paypal.com"] {
+		t.Errorf("Expected the all-Latin spelling of a mixed-script name, got %v", mixed)
+	}
+}
+
+func TestGenerateLookalikesTechniques(t *testing.T) {
+	variants := GenerateLookalikes("example.com", []string{"net", "org"}, []string{LookalikeTLDSwap})
+	var domains []string
+	for _, variant := range variants {
+		domains = append(domains, variant.Domain)
+	}
+	if expected := []string{"example.net", "example.org"}; !reflect.DeepEqual(domains, expected) {
+		t.Errorf("Expected only TLD swaps %v, got %v", expected, domains)
+	}
+	if variants := GenerateLookalikes("localhost", nil, nil); variants != nil {
+		t.Errorf("Expected no variants without a known suffix, got %v", variants)
+	}
+}
+
+// fakeDNSResolver adds name server records to fakeResolver
+type fakeDNSResolver struct {
+	fakeResolver
+	nameServers map[string][]string
+}
+
+func (f *fakeDNSResolver) LookupNS(ctx context.Context, name string) ([]*net.NS, error) {
+	hosts, ok := f.nameServers[name]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	var servers []*net.NS
+	for _, host := range hosts {
+		servers = append(servers, &net.NS{Host: host + "."})
+	}
+	return servers, nil
+}
+
+func TestCheckRegistrations(t *testing.T) {
+	resolver := &fakeDNSResolver{
+		fakeResolver: fakeResolver{
+			records: map[string][]string{
+				"exmple.com": {"192.0.2.10"},
+				"example.ws": {"192.0.2.99"},
+				"examp1e.ws": {"192.0.2.20"},
+			},
+			wildcards: map[string][]string{"ws": {"192.0.2.99"}},
+		},
+		nameServers: map[string][]string{"exanple.com": {"NS1.Parking.example"}},
+	}
+
+	registered := CheckRegistrations(context.Background(), []string{"exmple.com", "exanple.com", "exmaple.com", "example.ws", "examp1e.ws"}, resolver, 4, time.Second, nil)
+
+	var domains []string
+	for domain := range registered {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	if expected := []string{"examp1e.ws", "exanple.com", "exmple.com"}; !reflect.DeepEqual(domains, expected) {
+		t.Errorf("Expected registered %v, got %v", expected, domains)
+	}
+	if ns := registered["exanple.com"].NameServers; !reflect.DeepEqual(ns, []string{"ns1.parking.example"}) {
+		t.Errorf("Expected the delegation to be recorded, got %v", ns)
+	}
+	if len(registered["exanple.com"].IPs) != 0 {
+		t.Error("Expected a delegated but unresolved domain without addresses")
+	}
+}
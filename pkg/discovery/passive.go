@@ -7,6 +7,7 @@ import (
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/subfinder/v2/pkg/resolve"
 	"github.com/projectdiscovery/subfinder/v2/pkg/runner"
+	"github.com/valllabh/domain-scan/pkg/utils"
 )
 
 // PassiveDiscoveryWithLogger performs passive subdomain discovery using subfinder SDK with logging
@@ -16,6 +17,12 @@ func PassiveDiscoveryWithLogger(ctx context.Context, domains []string, logger *g
 
 // PassiveDiscoveryWithOptions performs passive subdomain discovery with configurable sources
 func PassiveDiscoveryWithOptions(ctx context.Context, domains []string, sources []string, logger *gologger.Logger) ([]string, error) {
+	return PassiveDiscoveryWithProbe(ctx, domains, sources, nil, logger)
+}
+
+// PassiveDiscoveryWithProbe performs passive subdomain discovery with configurable sources,
+// routing source API requests through the probe proxy when one is configured
+func PassiveDiscoveryWithProbe(ctx context.Context, domains []string, sources []string, probe *ProbeOptions, logger *gologger.Logger) ([]string, error) {
 	// Use a map to track unique subdomains and avoid duplicates
 	uniqueSubdomains := make(map[string]bool)
 
@@ -30,21 +37,23 @@ func PassiveDiscoveryWithOptions(ctx context.Context, domains []string, sources
 
 	// Create subfinder options with ResultCallback for memory-efficient progress reporting
 	options := &runner.Options{
-		Threads:            10,         // Reasonable default for concurrent enumeration
-		Timeout:            30,         // 30 second timeout per source
-		MaxEnumerationTime: 10,         // 10 minute max per domain
-		Resolvers:          []string{}, // Use default resolvers
+		Threads:            10,                // Reasonable default for concurrent enumeration
+		Timeout:            30,                // 30 second timeout per source
+		MaxEnumerationTime: 10,                // 10 minute max per domain
+		Resolvers:          []string{},        // Use default resolvers
 		All:                len(sources) == 0, // Use all sources if none specified
-		Sources:            sources,    // Specific sources to use
-		Verbose:            false,      // Disable verbose logging
-		RemoveWildcard:     false,      // Don't remove wildcards (faster)
-		CaptureSources:     false,      // Don't capture source information
+		Sources:            sources,           // Specific sources to use
+		Verbose:            false,             // Disable verbose logging
+		RemoveWildcard:     false,             // Don't remove wildcards (faster)
+		CaptureSources:     false,             // Don't capture source information
+		Proxy:              probeProxy(probe), // Route source requests through proxy if configured
 		ResultCallback: func(result *resolve.HostEntry) {
-			// Only add if not already seen (deduplication)
-			if !uniqueSubdomains[result.Host] {
-				uniqueSubdomains[result.Host] = true
+			// Only add if not already seen (deduplication), comparing case and IDN normalised names
+			host := utils.NormalizeTarget(result.Host)
+			if host != "" && !uniqueSubdomains[host] {
+				uniqueSubdomains[host] = true
 				if logger != nil {
-					logger.Debug().Msgf("Found subdomain: %s (total unique: %d)", result.Host, len(uniqueSubdomains))
+					logger.Debug().Msgf("Found subdomain: %s (total unique: %d)", host, len(uniqueSubdomains))
 				}
 			}
 		},
@@ -98,3 +107,11 @@ func PassiveDiscoveryWithOptions(ctx context.Context, domains []string, sources
 
 	return subdomains, nil
 }
+
+// probeProxy returns the proxy URL from probe options, or empty for direct connections
+func probeProxy(probe *ProbeOptions) string {
+	if probe == nil {
+		return ""
+	}
+	return probe.Proxy
+}
@@ -0,0 +1,265 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/httpx/runner"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// ProbeOptions controls how outbound connections are made during discovery.
+// The zero value keeps the default behaviour: direct connections, httpx default headers.
+type ProbeOptions struct {
+	Proxy     string   // HTTP or SOCKS5 proxy URL (e.g. "http://proxy:8080", "socks5://127.0.0.1:1080")
+	Headers   []string // Extra request headers in "Name: value" form
+	UserAgent string   // User-Agent sent to probed hosts
+	SNI       string   // TLS SNI override for probed hosts
+	CABundle  string   // PEM file with additional trusted CA certificates
+	Insecure  bool     // Skip TLS verification in HTTP clients built from these options (content crawl)
+	VerifyTLS bool     // Re-check certificates of live HTTPS hosts against the trust store (one extra request each)
+
+	Redirects    string // Redirect policy: RedirectFollow (default), RedirectSameHost or RedirectNone
+	MaxRedirects int    // Maximum redirects followed per host (0 = DefaultMaxRedirects)
+}
+
+// Redirect policies for probed hosts
+const (
+	RedirectFollow   = "follow"    // Follow redirects to any host
+	RedirectSameHost = "same-host" // Only follow redirects that stay on the probed host
+	RedirectNone     = "none"      // Record the first response without following it
+)
+
+// DefaultMaxRedirects is the number of redirects followed per host when none is configured
+const DefaultMaxRedirects = 10
+
+// Validate checks that the proxy URL, headers and CA bundle are usable
+func (o *ProbeOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if o.Proxy != "" {
+		proxyURL, err := url.Parse(o.Proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("unsupported proxy scheme %q: must be http, https or socks5", proxyURL.Scheme)
+		}
+		if proxyURL.Host == "" {
+			return fmt.Errorf("invalid proxy URL %q: missing host", o.Proxy)
+		}
+	}
+	switch o.Redirects {
+	case "", RedirectFollow, RedirectSameHost, RedirectNone:
+	default:
+		return fmt.Errorf("invalid redirect policy %q: must be %s, %s or %s", o.Redirects, RedirectFollow, RedirectSameHost, RedirectNone)
+	}
+	if o.MaxRedirects < 0 {
+		return fmt.Errorf("invalid max redirects %d: must not be negative", o.MaxRedirects)
+	}
+	for _, header := range o.Headers {
+		if name, _, ok := strings.Cut(header, ":"); !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid header %q: must be in \"Name: value\" form", header)
+		}
+	}
+	if o.CABundle != "" {
+		if _, err := o.certPool(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// customHeaders returns the headers to send to probed hosts, including the User-Agent override
+func (o *ProbeOptions) customHeaders() []string {
+	headers := make([]string, 0, len(o.Headers)+1)
+	for _, header := range o.Headers {
+		if o.UserAgent != "" && strings.HasPrefix(strings.ToLower(header), "user-agent:") {
+			continue // Explicit user agent wins over a User-Agent header
+		}
+		headers = append(headers, header)
+	}
+	if o.UserAgent != "" {
+		headers = append(headers, "User-Agent: "+o.UserAgent)
+	}
+	return headers
+}
+
+// applyToHTTPX copies proxy, header, SNI and redirect settings onto httpx runner options
+func (o *ProbeOptions) applyToHTTPX(opts *runner.Options) {
+	opts.FollowRedirects = true
+	opts.FollowHostRedirects = false
+	opts.MaxRedirects = DefaultMaxRedirects
+	if o == nil {
+		return
+	}
+	opts.Proxy = o.Proxy
+	opts.CustomHeaders = append(opts.CustomHeaders, o.customHeaders()...)
+	opts.SniName = o.SNI
+
+	switch o.Redirects {
+	case RedirectSameHost:
+		opts.FollowRedirects = false
+		opts.FollowHostRedirects = true
+	case RedirectNone:
+		opts.FollowRedirects = false
+	}
+	if o.MaxRedirects > 0 {
+		opts.MaxRedirects = o.MaxRedirects
+	}
+}
+
+// certPool returns the system roots extended with the configured CA bundle
+func (o *ProbeOptions) certPool() (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if o.CABundle == "" {
+		return pool, nil
+	}
+	pem, err := os.ReadFile(o.CABundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", o.CABundle)
+	}
+	return pool, nil
+}
+
+// TLSConfig builds a TLS client configuration honouring SNI, CA bundle and insecure settings
+func (o *ProbeOptions) TLSConfig() (*tls.Config, error) {
+	if o == nil {
+		return &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS10}, nil // #nosec G402 - probing must accept any certificate
+	}
+	pool, err := o.certPool()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		RootCAs:            pool,
+		ServerName:         o.SNI,
+		InsecureSkipVerify: o.Insecure, // #nosec G402 - user controlled toggle
+		MinVersion:         tls.VersionTLS10,
+	}, nil
+}
+
+// HTTPClient builds an HTTP client honouring every probe option.
+// Redirects are not followed so callers see the response of the probed host itself.
+func (o *ProbeOptions) HTTPClient(timeout time.Duration) (*http.Client, error) {
+	tlsConfig, err := o.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := &http.Transport{
+		TLSClientConfig:   tlsConfig,
+		DisableKeepAlives: true,
+	}
+	var headers []string
+	if o != nil {
+		if o.Proxy != "" {
+			proxyURL, err := url.Parse(o.Proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy URL: %w", err)
+			}
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+		headers = o.customHeaders()
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &headerTransport{base: transport, headers: headers},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}, nil
+}
+
+// headerTransport adds the configured custom headers to every outgoing request
+type headerTransport struct {
+	base    http.RoundTripper
+	headers []string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.headers) > 0 {
+		req = req.Clone(req.Context())
+		for _, header := range t.headers {
+			name, value, _ := strings.Cut(header, ":")
+			name = strings.TrimSpace(name)
+			value = strings.TrimSpace(value)
+			if strings.EqualFold(name, "Host") {
+				req.Host = value
+				continue
+			}
+			req.Header.Set(name, value)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// verifyCertificates re-checks presented certificates of live HTTPS entries against
+// the configured trust store when VerifyTLS is set. httpx always accepts any certificate
+// so SANs can be harvested and only reports the leaf, so this takes a HEAD request per
+// host; entries whose certificate fails strict verification are marked untrusted.
+func verifyCertificates(ctx context.Context, entries []*types.DomainEntry, probe *ProbeOptions, logger *gologger.Logger) {
+	if probe == nil || !probe.VerifyTLS {
+		return
+	}
+	strict := *probe
+	strict.Insecure = false
+	client, err := strict.HTTPClient(10 * time.Second)
+	if err != nil {
+		if logger != nil {
+			logger.Warning().Msgf("Skipping certificate verification: %v", err)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 20)
+	for _, entry := range entries {
+		if entry.Certificate == nil || !strings.HasPrefix(entry.URL, "https://") {
+			continue
+		}
+		wg.Add(1)
+		go func(entry *types.DomainEntry) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, entry.URL, nil)
+			if err != nil {
+				return
+			}
+			resp, err := client.Do(req)
+			if err == nil {
+				_ = resp.Body.Close()
+				return
+			}
+			var verifyErr *tls.CertificateVerificationError
+			if errors.As(err, &verifyErr) {
+				entry.Certificate.Untrusted = true
+				if logger != nil {
+					logger.Debug().Msgf("Untrusted certificate for %s: %v", entry.Domain, verifyErr.Err)
+				}
+			}
+		}(entry)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,115 @@
+package discovery
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+func TestProbeOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		probe       *ProbeOptions
+		expectError bool
+	}{
+		{name: "nil options", probe: nil},
+		{name: "empty options", probe: &ProbeOptions{}},
+		{name: "http proxy", probe: &ProbeOptions{Proxy: "http://proxy.internal:3128"}},
+		{name: "socks5 proxy", probe: &ProbeOptions{Proxy: "socks5://127.0.0.1:1080"}},
+		{name: "unsupported proxy scheme", probe: &ProbeOptions{Proxy: "ftp://proxy:21"}, expectError: true},
+		{name: "proxy without host", probe: &ProbeOptions{Proxy: "http://"}, expectError: true},
+		{name: "valid header", probe: &ProbeOptions{Headers: []string{"X-Token: abc"}}},
+		{name: "header without colon", probe: &ProbeOptions{Headers: []string{"X-Token"}}, expectError: true},
+		{name: "missing CA bundle", probe: &ProbeOptions{CABundle: "/nonexistent/ca.pem"}, expectError: true},
+		{name: "redirect policy", probe: &ProbeOptions{Redirects: RedirectSameHost, MaxRedirects: 5}},
+		{name: "unknown redirect policy", probe: &ProbeOptions{Redirects: "sometimes"}, expectError: true},
+		{name: "negative max redirects", probe: &ProbeOptions{MaxRedirects: -1}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.probe.Validate()
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProbeOptionsHTTPClientHeaders(t *testing.T) {
+	var gotUserAgent, gotToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.Header.Get("User-Agent")
+		gotToken = r.Header.Get("X-Scan-Token")
+	}))
+	defer server.Close()
+
+	probe := &ProbeOptions{
+		Headers:   []string{"X-Scan-Token: abc", "User-Agent: ignored"},
+		UserAgent: "AcmeScanner/1.0",
+	}
+	client, err := probe.HTTPClient(5 * time.Second)
+	if err != nil {
+		t.Fatalf("HTTPClient() returned error: %v", err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if gotUserAgent != "AcmeScanner/1.0" {
+		t.Errorf("Expected User-Agent AcmeScanner/1.0, got %q", gotUserAgent)
+	}
+	if gotToken != "abc" {
+		t.Errorf("Expected X-Scan-Token abc, got %q", gotToken)
+	}
+}
+
+func TestVerifyCertificates(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// Write the test server certificate as a CA bundle
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caPath, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		probe           *ProbeOptions
+		expectUntrusted bool
+	}{
+		{name: "verification is opt-in", probe: &ProbeOptions{}},
+		{name: "untrusted without CA bundle", probe: &ProbeOptions{VerifyTLS: true}, expectUntrusted: true},
+		{name: "insecure client still verifies", probe: &ProbeOptions{VerifyTLS: true, Insecure: true}, expectUntrusted: true},
+		{name: "trusted with CA bundle", probe: &ProbeOptions{VerifyTLS: true, CABundle: caPath}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &types.DomainEntry{
+				Domain:      "127.0.0.1",
+				URL:         server.URL,
+				Reachable:   true,
+				Certificate: &types.CertificateInfo{Subject: "test"},
+			}
+			verifyCertificates(context.Background(), []*types.DomainEntry{entry}, tt.probe, nil)
+			if entry.Certificate.Untrusted != tt.expectUntrusted {
+				t.Errorf("Expected Untrusted=%t, got %t", tt.expectUntrusted, entry.Certificate.Untrusted)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package discovery
+
+import (
+	"net/url"
+
+	"github.com/projectdiscovery/httpx/runner"
+	"github.com/valllabh/domain-scan/pkg/types"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// redirectInfo builds the redirect information of an httpx result: the final URL, the
+// status codes and every hop of the chain. Returns nil when the response didn't redirect.
+// When redirects aren't followed, the chain holds the redirect response and its Location.
+func redirectInfo(result runner.Result) *types.RedirectInfo {
+	isRedirectStatus := result.StatusCode >= 300 && result.StatusCode < 400
+
+	var hops []types.RedirectHop
+	for _, item := range result.Chain {
+		hops = append(hops, newRedirectHop(item.RequestURL, item.StatusCode, item.Location))
+	}
+	if len(hops) == 0 && isRedirectStatus {
+		hops = append(hops, newRedirectHop(result.URL, result.StatusCode, result.Location))
+	}
+
+	hasRedirectChain := len(result.ChainStatusCodes) > 0 || len(hops) > 1
+	if !isRedirectStatus && !hasRedirectChain && (result.FinalURL == "" || result.FinalURL == result.URL) {
+		return nil
+	}
+
+	finalURL := result.FinalURL
+	if finalURL == "" || finalURL == result.URL {
+		// If FinalURL is empty/same, use the Location header of the redirect
+		finalURL = resolveLocation(result.URL, result.Location)
+	}
+
+	statusCodes := result.ChainStatusCodes
+	if len(statusCodes) == 0 {
+		for _, hop := range hops {
+			statusCodes = append(statusCodes, hop.Status)
+		}
+	}
+
+	return &types.RedirectInfo{
+		IsRedirect:  true,
+		RedirectsTo: finalURL,
+		StatusCodes: statusCodes,
+		Chain:       hops,
+	}
+}
+
+// newRedirectHop records a response of a redirect chain, resolving a relative Location
+func newRedirectHop(requestURL string, status int, location string) types.RedirectHop {
+	return types.RedirectHop{
+		URL:      requestURL,
+		Status:   status,
+		Host:     utils.NormalizeTarget(requestURL),
+		Location: resolveLocation(requestURL, location),
+	}
+}
+
+// resolveLocation resolves a Location header against the URL that returned it
+func resolveLocation(base string, location string) string {
+	if location == "" {
+		return ""
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return location
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return location
+	}
+	return baseURL.ResolveReference(ref).String()
+}
+
+// RedirectHosts returns the hosts a domain redirected to, in chain order and without
+// duplicates: every hop and Location of the chain and the final URL, except the domain itself
+func RedirectHosts(domain string, redirect *types.RedirectInfo) []string {
+	if redirect == nil || !redirect.IsRedirect {
+		return nil
+	}
+	seen := map[string]bool{domain: true, "": true}
+	var hosts []string
+	add := func(rawURL string) {
+		host := utils.NormalizeTarget(rawURL)
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	for _, hop := range redirect.Chain {
+		add(hop.URL)
+		add(hop.Location)
+	}
+	add(redirect.RedirectsTo)
+	return hosts
+}
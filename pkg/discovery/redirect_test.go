@@ -0,0 +1,113 @@
+package discovery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/projectdiscovery/httpx/common/httpx"
+	"github.com/projectdiscovery/httpx/runner"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+func TestRedirectInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   runner.Result
+		expected *types.RedirectInfo
+	}{
+		{
+			name:     "no redirect",
+			result:   runner.Result{URL: "https://www.example.com", StatusCode: 200},
+			expected: nil,
+		},
+		{
+			name: "followed chain across hosts",
+			result: runner.Result{
+				URL:              "http://example.com",
+				StatusCode:       200,
+				FinalURL:         "https://www.example.org/home",
+				ChainStatusCodes: []int{301, 302, 200},
+				Chain: []httpx.ChainItem{
+					{RequestURL: "http://example.com", StatusCode: 301, Location: "https://example.com/"},
+					{RequestURL: "https://example.com/", StatusCode: 302, Location: "https://WWW.example.org/home"},
+					{RequestURL: "https://www.example.org/home", StatusCode: 200},
+				},
+			},
+			expected: &types.RedirectInfo{
+				IsRedirect:  true,
+				RedirectsTo: "https://www.example.org/home",
+				StatusCodes: []int{301, 302, 200},
+				Chain: []types.RedirectHop{
+					{URL: "http://example.com", Status: 301, Host: "example.com", Location: "https://example.com/"},
+					{URL: "https://example.com/", Status: 302, Host: "example.com", Location: "https://WWW.example.org/home"},
+					{URL: "https://www.example.org/home", Status: 200, Host: "www.example.org"},
+				},
+			},
+		},
+		{
+			name:   "unfollowed redirect with relative location",
+			result: runner.Result{URL: "https://shop.example.com/cart", StatusCode: 302, Location: "/login?next=cart"},
+			expected: &types.RedirectInfo{
+				IsRedirect:  true,
+				RedirectsTo: "https://shop.example.com/login?next=cart",
+				StatusCodes: []int{302},
+				Chain: []types.RedirectHop{
+					{URL: "https://shop.example.com/cart", Status: 302, Host: "shop.example.com", Location: "https://shop.example.com/login?next=cart"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redirectInfo(tt.result)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("redirectInfo() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRedirectHosts(t *testing.T) {
+	redirect := &types.RedirectInfo{
+		IsRedirect:  true,
+		RedirectsTo: "https://login.example-sso.com/auth",
+		Chain: []types.RedirectHop{
+			{URL: "http://example.com", Status: 301, Location: "https://www.example.com/"},
+			{URL: "https://www.example.com/", Status: 302, Location: "https://login.example-sso.com/auth"},
+			{URL: "https://login.example-sso.com/auth", Status: 200},
+		},
+	}
+	expected := []string{"www.example.com", "login.example-sso.com"}
+	if got := RedirectHosts("example.com", redirect); !reflect.DeepEqual(got, expected) {
+		t.Errorf("RedirectHosts() = %v, want %v", got, expected)
+	}
+	if got := RedirectHosts("example.com", nil); got != nil {
+		t.Errorf("Expected no hosts without a redirect, got %v", got)
+	}
+}
+
+func TestApplyRedirectPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		probe         *ProbeOptions
+		follow        bool
+		followHost    bool
+		expectedLimit int
+	}{
+		{name: "nil options follow", probe: nil, follow: true, expectedLimit: DefaultMaxRedirects},
+		{name: "default policy follows", probe: &ProbeOptions{}, follow: true, expectedLimit: DefaultMaxRedirects},
+		{name: "same host", probe: &ProbeOptions{Redirects: RedirectSameHost, MaxRedirects: 3}, followHost: true, expectedLimit: 3},
+		{name: "none", probe: &ProbeOptions{Redirects: RedirectNone}, expectedLimit: DefaultMaxRedirects},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &runner.Options{}
+			tt.probe.applyToHTTPX(opts)
+			if opts.FollowRedirects != tt.follow || opts.FollowHostRedirects != tt.followHost || opts.MaxRedirects != tt.expectedLimit {
+				t.Errorf("Got follow=%t followHost=%t max=%d", opts.FollowRedirects, opts.FollowHostRedirects, opts.MaxRedirects)
+			}
+		})
+	}
+}
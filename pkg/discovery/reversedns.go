@@ -0,0 +1,77 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// PTRResolver resolves the PTR records of an address. *net.Resolver satisfies it.
+type PTRResolver interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+}
+
+// ReverseDNSLookup performs PTR lookups for the given IPs. A nil resolver uses the system
+// resolver. Returns a map of discovered hostname -> IP it was found on. Lookups run with
+// up to threads concurrent queries, each bounded by timeout.
+func ReverseDNSLookup(ctx context.Context, ips []string, resolver PTRResolver, threads int, timeout time.Duration, logger *gologger.Logger) map[string]string {
+	names := make(map[string]string)
+	if len(ips) == 0 {
+		return names
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	if threads <= 0 {
+		threads = 50
+	}
+
+	if logger != nil {
+		logger.Info().Msgf("Starting reverse DNS lookups for %d IPs", len(ips))
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, threads)
+	for _, ip := range ips {
+		wg.Add(1)
+		go func(ip string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			ptrs, err := resolver.LookupAddr(lookupCtx, ip)
+			if err != nil {
+				return
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			for _, ptr := range ptrs {
+				name := utils.NormalizeTarget(ptr)
+				if name == "" || utils.IsIPTarget(name) {
+					continue
+				}
+				if _, exists := names[name]; !exists {
+					names[name] = ip
+				}
+				if logger != nil {
+					logger.Debug().Msgf("PTR %s -> %s", ip, name)
+				}
+			}
+		}(ip)
+	}
+	wg.Wait()
+
+	if logger != nil {
+		logger.Info().Msgf("Reverse DNS lookups completed: %d names found", len(names))
+	}
+
+	return names
+}
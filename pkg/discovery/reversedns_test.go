@@ -0,0 +1,40 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakePTRResolver answers PTR lookups from a static table
+type fakePTRResolver map[string][]string
+
+func (r fakePTRResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	if names, ok := r[addr]; ok {
+		return names, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func TestReverseDNSLookup(t *testing.T) {
+	resolver := fakePTRResolver{
+		"192.0.2.1": {"Mail.Example.com.", "mx.example.com."},
+		"192.0.2.2": {"mail.example.com.", "192.0.2.2.", "bad host."},
+	}
+
+	names := ReverseDNSLookup(context.Background(), []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"}, resolver, 2, time.Second, nil)
+
+	expected := map[string]string{"mail.example.com": "192.0.2.1", "mx.example.com": "192.0.2.1"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	// mail.example.com is reported by both IPs; either is a valid origin
+	if names["mx.example.com"] != "192.0.2.1" || (names["mail.example.com"] != "192.0.2.1" && names["mail.example.com"] != "192.0.2.2") {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+	if got := ReverseDNSLookup(context.Background(), nil, resolver, 2, time.Second, nil); !reflect.DeepEqual(got, map[string]string{}) {
+		t.Errorf("Expected no names without IPs, got %v", got)
+	}
+}
@@ -0,0 +1,312 @@
+package domainscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+	"golang.org/x/net/websocket"
+)
+
+// Progress event types published by ProgressBroadcaster
+const (
+	EventStart            = "start"
+	EventProgress         = "progress"
+	EventEnd              = "end"
+	EventStageStart       = "stage_start"
+	EventStageEnd         = "stage_end"
+	EventDomainDiscovered = "domain_discovered"
+	EventDomainVerified   = "domain_verified"
+	EventError            = "error"
+	EventDepthChange      = "depth"
+)
+
+// DefaultEventHistory is the number of past events replayed to late subscribers
+const DefaultEventHistory = 1000
+
+// sseKeepAlive is how often an idle SSE stream receives a comment to keep proxies from closing it
+const sseKeepAlive = 15 * time.Second
+
+// ProgressEvent is a single progress update delivered to subscribers
+type ProgressEvent struct {
+	ID           int64           `json:"id"`                      // Sequence number, starting at 1
+	Type         string          `json:"type"`                    // Event type, e.g. "start", "progress", "end"
+	Time         time.Time       `json:"time"`                    // When the event was published
+	Domains      []string        `json:"domains,omitempty"`       // Seed domains (start)
+	Keywords     []string        `json:"keywords,omitempty"`      // Keywords in use (start)
+	TotalDomains int             `json:"total_domains,omitempty"` // Domains discovered so far
+	LiveDomains  int             `json:"live_domains,omitempty"`  // Live domains so far
+	Statistics   *DiscoveryStats `json:"statistics,omitempty"`    // Final statistics (end)
+	Stage        string          `json:"stage,omitempty"`         // Discovery stage (stage and error events)
+	Depth        int             `json:"depth,omitempty"`         // Recursion depth (stage and depth events)
+	Targets      int             `json:"targets,omitempty"`       // Targets in the stage batch
+	Found        int             `json:"found,omitempty"`         // Domains found by the stage batch (stage_end)
+	Elapsed      time.Duration   `json:"elapsed,omitempty"`       // Duration of the stage batch (stage_end)
+	Domain       string          `json:"domain,omitempty"`        // Discovered domain (domain_discovered)
+	Source       *types.Source   `json:"source,omitempty"`        // Source that found the domain (domain_discovered)
+	Entry        *DomainEntry    `json:"entry,omitempty"`         // Snapshot of the probed domain (domain_verified)
+	Error        string          `json:"error,omitempty"`         // Error message (error)
+}
+
+// ProgressBroadcaster is an ExtendedProgressCallback that fans progress events out to any
+// number of subscribers, over Go channels, Server-Sent Events or WebSocket. Recent events
+// are kept so subscribers joining mid-scan (or reconnecting) replay what they missed.
+type ProgressBroadcaster struct {
+	mu          sync.Mutex
+	history     []ProgressEvent
+	maxHistory  int
+	nextID      int64
+	subscribers map[chan ProgressEvent]struct{}
+	closed      bool
+}
+
+// NewProgressBroadcaster creates a broadcaster keeping up to maxHistory events for replay.
+// A maxHistory of 0 uses DefaultEventHistory.
+func NewProgressBroadcaster(maxHistory int) *ProgressBroadcaster {
+	if maxHistory <= 0 {
+		maxHistory = DefaultEventHistory
+	}
+	return &ProgressBroadcaster{
+		maxHistory:  maxHistory,
+		subscribers: make(map[chan ProgressEvent]struct{}),
+	}
+}
+
+// OnStart publishes a start event
+func (b *ProgressBroadcaster) OnStart(domains []string, keywords []string) {
+	b.Publish(ProgressEvent{Type: EventStart, Domains: domains, Keywords: keywords})
+}
+
+// OnProgress publishes a progress event
+func (b *ProgressBroadcaster) OnProgress(totalDomains, liveDomains int) {
+	b.Publish(ProgressEvent{Type: EventProgress, TotalDomains: totalDomains, LiveDomains: liveDomains})
+}
+
+// OnEnd publishes an end event and closes all subscriptions
+func (b *ProgressBroadcaster) OnEnd(result *AssetDiscoveryResult) {
+	event := ProgressEvent{Type: EventEnd}
+	if result != nil {
+		stats := result.Statistics
+		event.Statistics = &stats
+		event.TotalDomains = stats.TotalSubdomains
+		event.LiveDomains = stats.ActiveServices
+	}
+	b.Publish(event)
+	b.Close()
+}
+
+// OnStageStart publishes a stage_start event
+func (b *ProgressBroadcaster) OnStageStart(stage StageInfo) {
+	b.Publish(ProgressEvent{Type: EventStageStart, Stage: stage.Stage, Depth: stage.Depth, Targets: stage.Targets})
+}
+
+// OnStageEnd publishes a stage_end event
+func (b *ProgressBroadcaster) OnStageEnd(stage StageInfo, found int, elapsed time.Duration) {
+	b.Publish(ProgressEvent{Type: EventStageEnd, Stage: stage.Stage, Depth: stage.Depth, Targets: stage.Targets, Found: found, Elapsed: elapsed})
+}
+
+// OnDomainDiscovered publishes a domain_discovered event
+func (b *ProgressBroadcaster) OnDomainDiscovered(domain string, source types.Source) {
+	b.Publish(ProgressEvent{Type: EventDomainDiscovered, Domain: domain, Source: &source, Depth: source.Depth})
+}
+
+// OnDomainVerified publishes a domain_verified event with a snapshot of the entry,
+// since the scanner keeps updating the entry after the event
+func (b *ProgressBroadcaster) OnDomainVerified(entry *DomainEntry) {
+	snapshot := *entry
+	snapshot.Sources = append([]types.Source(nil), entry.Sources...)
+	b.Publish(ProgressEvent{Type: EventDomainVerified, Domain: entry.Domain, Entry: &snapshot})
+}
+
+// OnError publishes an error event
+func (b *ProgressBroadcaster) OnError(stage string, err error) {
+	b.Publish(ProgressEvent{Type: EventError, Stage: stage, Error: err.Error()})
+}
+
+// OnDepthChange publishes a depth event
+func (b *ProgressBroadcaster) OnDepthChange(depth int) {
+	b.Publish(ProgressEvent{Type: EventDepthChange, Depth: depth})
+}
+
+// Publish assigns the next sequence number to event, records it for replay and delivers it
+// to every subscriber. Subscribers that fall too far behind are disconnected; they can
+// resubscribe from the last event they saw. Events published after Close are dropped.
+func (b *ProgressBroadcaster) Publish(event ProgressEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.nextID++
+	event.ID = b.nextID
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	b.history = append(b.history, event)
+	if len(b.history) > b.maxHistory {
+		b.history = b.history[len(b.history)-b.maxHistory:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving every retained event with an ID greater than afterID,
+// followed by live events. The channel is closed when the scan ends, when the subscriber
+// falls behind, or when unsubscribe is called.
+func (b *ProgressBroadcaster) Subscribe(afterID int64) (events <-chan ProgressEvent, unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []ProgressEvent
+	for _, event := range b.history {
+		if event.ID > afterID {
+			replay = append(replay, event)
+		}
+	}
+
+	ch := make(chan ProgressEvent, len(replay)+256)
+	for _, event := range replay {
+		ch <- event
+	}
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// History returns a copy of the retained events
+func (b *ProgressBroadcaster) History() []ProgressEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]ProgressEvent(nil), b.history...)
+}
+
+// Close ends all subscriptions. Late subscribers still receive the retained history.
+func (b *ProgressBroadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// ServeHTTP streams events as Server-Sent Events. Replay starts after the Last-Event-ID
+// header (sent automatically by reconnecting EventSource clients) or the "after" query parameter.
+func (b *ProgressBroadcaster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	afterID := replayPosition(r)
+	controller := http.NewResponseController(w)
+	// Streams outlive the server's write timeout
+	_ = controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	_ = controller.Flush()
+
+	events, unsubscribe := b.Subscribe(afterID)
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// WebSocketHandler streams events as JSON WebSocket messages, replaying events after the
+// "after" query parameter. Origin checks are left to the caller's authentication.
+func (b *ProgressBroadcaster) WebSocketHandler() http.Handler {
+	return websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			defer func() { _ = conn.Close() }()
+			events, unsubscribe := b.Subscribe(replayPosition(conn.Request()))
+			defer unsubscribe()
+
+			// Reads only detect the client going away; incoming messages are ignored
+			gone := make(chan struct{})
+			go func() {
+				defer close(gone)
+				var discard []byte
+				for websocket.Message.Receive(conn, &discard) == nil {
+				}
+			}()
+
+			for {
+				select {
+				case <-gone:
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					if err := websocket.JSON.Send(conn, event); err != nil {
+						return
+					}
+				}
+			}
+		},
+	}
+}
+
+// replayPosition returns the last event ID a subscriber has already seen
+func replayPosition(r *http.Request) int64 {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("after")
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
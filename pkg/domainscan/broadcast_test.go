@@ -0,0 +1,169 @@
+package domainscan
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+	"golang.org/x/net/websocket"
+)
+
+// collect drains a subscription into a slice of event types
+func collect(events <-chan ProgressEvent) []string {
+	var kinds []string
+	for event := range events {
+		kinds = append(kinds, event.Type)
+	}
+	return kinds
+}
+
+func TestProgressBroadcasterReplay(t *testing.T) {
+	b := NewProgressBroadcaster(0)
+	b.OnStart([]string{"example.com"}, []string{"example"})
+
+	// Early subscriber sees the replayed start and everything after it
+	early, _ := b.Subscribe(0)
+	b.OnProgress(3, 1)
+	// Resuming after the first event skips it
+	resumed, _ := b.Subscribe(1)
+	b.OnEnd(&AssetDiscoveryResult{Statistics: DiscoveryStats{TotalSubdomains: 3, ActiveServices: 1}})
+
+	if got := strings.Join(collect(early), ","); got != "start,progress,end" {
+		t.Errorf("Early subscriber got %s", got)
+	}
+	if got := strings.Join(collect(resumed), ","); got != "progress,end" {
+		t.Errorf("Resumed subscriber got %s", got)
+	}
+
+	// Late subscribers get the full history and a closed channel
+	late, _ := b.Subscribe(0)
+	if got := strings.Join(collect(late), ","); got != "start,progress,end" {
+		t.Errorf("Late subscriber got %s", got)
+	}
+
+	history := b.History()
+	if len(history) != 3 || history[2].Statistics == nil || history[2].Statistics.TotalSubdomains != 3 {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
+
+func TestProgressBroadcasterStageEvents(t *testing.T) {
+	b := NewProgressBroadcaster(0)
+	var _ ExtendedProgressCallback = b
+
+	stage := StageInfo{Stage: StageCertificate, Depth: 2, Targets: 7}
+	b.OnDepthChange(2)
+	b.OnStageStart(stage)
+	b.OnDomainDiscovered("dev.example.com", types.Source{Name: "certificate-san", Type: "certificate", Depth: 2})
+	entry := &DomainEntry{Domain: "dev.example.com", Reachable: true}
+	b.OnDomainVerified(entry)
+	entry.Reachable = false // Later scanner updates must not leak into published events
+	b.OnError(StageCertificate, errors.New("tls handshake timeout"))
+	b.OnStageEnd(stage, 1, time.Second)
+
+	history := b.History()
+	var kinds []string
+	for _, event := range history {
+		kinds = append(kinds, event.Type)
+	}
+	expected := "depth,stage_start,domain_discovered,domain_verified,error,stage_end"
+	if got := strings.Join(kinds, ","); got != expected {
+		t.Fatalf("Expected %s, got %s", expected, got)
+	}
+	if history[1].Targets != 7 || history[1].Depth != 2 {
+		t.Errorf("Unexpected stage_start event: %+v", history[1])
+	}
+	if history[2].Source == nil || history[2].Source.Name != "certificate-san" {
+		t.Errorf("Unexpected domain_discovered event: %+v", history[2])
+	}
+	if history[3].Entry == nil || !history[3].Entry.Reachable {
+		t.Errorf("domain_verified should carry a snapshot of the entry: %+v", history[3].Entry)
+	}
+	if history[4].Error != "tls handshake timeout" || history[5].Found != 1 {
+		t.Errorf("Unexpected error/stage_end events: %+v %+v", history[4], history[5])
+	}
+}
+
+func TestProgressBroadcasterHistoryLimit(t *testing.T) {
+	b := NewProgressBroadcaster(2)
+	for i := 0; i < 5; i++ {
+		b.OnProgress(i, 0)
+	}
+	history := b.History()
+	if len(history) != 2 || history[0].ID != 4 || history[1].ID != 5 {
+		t.Errorf("Expected the last two events, got %+v", history)
+	}
+}
+
+func TestProgressBroadcasterUnsubscribe(t *testing.T) {
+	b := NewProgressBroadcaster(0)
+	events, unsubscribe := b.Subscribe(0)
+	unsubscribe()
+	unsubscribe() // Safe to call twice
+	b.OnProgress(1, 0)
+	if _, ok := <-events; ok {
+		t.Error("Expected closed channel after unsubscribe")
+	}
+}
+
+func TestProgressBroadcasterSSE(t *testing.T) {
+	b := NewProgressBroadcaster(0)
+	b.OnStart([]string{"example.com"}, nil)
+	b.OnProgress(2, 1)
+	b.OnEnd(&AssetDiscoveryResult{})
+
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %s", ct)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "id:") || strings.HasPrefix(line, "event:") {
+			lines = append(lines, line)
+		}
+	}
+	expected := "id: 2,event: progress,id: 3,event: end"
+	if got := strings.Join(lines, ","); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestProgressBroadcasterWebSocket(t *testing.T) {
+	b := NewProgressBroadcaster(0)
+	b.OnStart([]string{"example.com"}, nil)
+
+	srv := httptest.NewServer(b.WebSocketHandler())
+	defer srv.Close()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/?after=0", "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var event ProgressEvent
+	if err := websocket.JSON.Receive(conn, &event); err != nil || event.Type != EventStart {
+		t.Fatalf("Expected replayed start event, got %+v (err %v)", event, err)
+	}
+
+	b.OnProgress(4, 2)
+	if err := websocket.JSON.Receive(conn, &event); err != nil || event.Type != EventProgress || event.TotalDomains != 4 {
+		t.Fatalf("Expected live progress event, got %+v (err %v)", event, err)
+	}
+}
@@ -3,6 +3,9 @@ package domainscan
 import (
 	"errors"
 	"time"
+
+	"github.com/valllabh/domain-scan/pkg/discovery"
+	"github.com/valllabh/domain-scan/pkg/utils"
 )
 
 // Config represents the configuration for domain scanning
@@ -22,6 +25,32 @@ type DiscoveryConfig struct {
 	RecursionDepth   int           `yaml:"recursion_depth" json:"recursion_depth"`
 	MaxDomains       int           `yaml:"max_domains" json:"max_domains"` // 0 means unlimited
 	Sources          []string      `yaml:"sources" json:"sources"` // Subfinder sources to use
+
+	// IP pivoting expands resolved IPs to their network and looks for related names
+	EnableIPPivot bool `yaml:"enable_ip_pivot" json:"enable_ip_pivot"` // PTR lookups and TLS grabs on neighbouring IPs
+	IPPivotPrefix int  `yaml:"ip_pivot_prefix" json:"ip_pivot_prefix"` // IPv4 prefix length to sweep (24 = /24, 32 = resolved IP only)
+
+	// Active enumeration resolves permutations and wordlist guesses (sends DNS queries)
+	EnableActive     bool     `yaml:"enable_active" json:"enable_active"`                 // Permutation and brute-force stage
+	Wordlist         string   `yaml:"wordlist" json:"wordlist,omitempty"`                   // Wordlist file for brute-force (empty = permutations only)
+	PermutationWords []string `yaml:"permutation_words" json:"permutation_words,omitempty"` // Words inserted around labels (empty = built-in list)
+
+	// Content discovery crawls live pages for hostnames in links, scripts, CSP and CORS headers
+	EnableContent bool `yaml:"enable_content" json:"enable_content"`
+
+	// HTTP client settings applied to subfinder and httpx
+	Proxy     string   `yaml:"proxy" json:"proxy,omitempty"`           // HTTP or SOCKS5 proxy URL
+	Headers   []string `yaml:"headers" json:"headers,omitempty"`       // Extra headers for probed hosts ("Name: value")
+	UserAgent string   `yaml:"user_agent" json:"user_agent,omitempty"` // User-Agent for probed hosts
+	SNI       string   `yaml:"sni" json:"sni,omitempty"`               // TLS SNI override
+	CABundle  string   `yaml:"ca_bundle" json:"ca_bundle,omitempty"`   // PEM file with additional trusted CAs
+	Insecure  bool     `yaml:"insecure" json:"insecure"`               // Skip TLS verification of crawled pages
+	VerifyTLS bool     `yaml:"verify_tls" json:"verify_tls"`           // Flag live hosts whose certificate fails verification
+
+	// Redirect handling of probed hosts
+	Redirects               string `yaml:"redirects" json:"redirects"`                                 // follow, same-host or none
+	MaxRedirects            int    `yaml:"max_redirects" json:"max_redirects"`                         // Maximum redirects followed per host
+	EnableRedirectDiscovery bool   `yaml:"enable_redirect_discovery" json:"enable_redirect_discovery"` // Feed keyword-matching redirect hosts back into discovery
 }
 
 
@@ -37,6 +66,14 @@ func DefaultConfig() *Config {
 			RecursionDepth:   0, // 0 means unlimited
 			MaxDomains:       0, // 0 means unlimited
 			Sources:          []string{}, // Empty means all sources
+			EnableIPPivot:    false,
+			IPPivotPrefix:    24,
+			EnableActive:     false,
+			PermutationWords: []string{},
+			Headers:          []string{},
+			Insecure:         true, // Accept any certificate so SANs can be harvested
+			Redirects:        discovery.RedirectFollow,
+			MaxRedirects:     discovery.DefaultMaxRedirects,
 		},
 		Keywords: []string{},
 		LogLevel: "info",
@@ -51,6 +88,11 @@ func (c *Config) Validate() error {
 	if c.Discovery.Threads <= 0 {
 		c.Discovery.Threads = 50
 	}
+	if c.Discovery.IPPivotPrefix == 0 {
+		c.Discovery.IPPivotPrefix = 24
+	} else if c.Discovery.IPPivotPrefix < 16 || c.Discovery.IPPivotPrefix > 32 {
+		return errors.New("invalid IP pivot prefix: must be between 16 and 32")
+	}
 
 	// Validate log level
 	validLogLevels := map[string]bool{
@@ -63,5 +105,31 @@ func (c *Config) Validate() error {
 		return errors.New("invalid log level: must be one of trace, debug, info, warn, error, silent")
 	}
 
+	// Validate keyword syntax (regex keywords must compile)
+	if err := utils.ValidateKeywords(c.Keywords); err != nil {
+		return err
+	}
+
+	// Validate HTTP client settings
+	if err := c.Discovery.probeOptions().Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// probeOptions converts the HTTP client settings into discovery probe options
+func (d *DiscoveryConfig) probeOptions() *discovery.ProbeOptions {
+	return &discovery.ProbeOptions{
+		Proxy:     d.Proxy,
+		Headers:   d.Headers,
+		UserAgent: d.UserAgent,
+		SNI:       d.SNI,
+		CABundle:  d.CABundle,
+		Insecure:  d.Insecure,
+		VerifyTLS: d.VerifyTLS,
+
+		Redirects:    d.Redirects,
+		MaxRedirects: d.MaxRedirects,
+	}
+}
@@ -90,3 +90,52 @@ func TestConfigValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigValidateHTTPClientSettings(t *testing.T) {
+	config := DefaultConfig()
+	config.Discovery.Proxy = "socks5://127.0.0.1:1080"
+	config.Discovery.Headers = []string{"X-Scan-Token: abc"}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() returned error for valid proxy settings: %v", err)
+	}
+
+	config.Discovery.Proxy = "ftp://proxy.internal"
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() should reject unsupported proxy scheme")
+	}
+
+	config.Discovery.Proxy = ""
+	config.Discovery.Headers = []string{"missing-colon"}
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() should reject malformed header")
+	}
+}
+
+func TestConfigValidateIPPivotPrefix(t *testing.T) {
+	config := DefaultConfig()
+	config.Discovery.IPPivotPrefix = 0
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() returned error: %v", err)
+	}
+	if config.Discovery.IPPivotPrefix != 24 {
+		t.Errorf("Expected IPPivotPrefix to default to 24, got %d", config.Discovery.IPPivotPrefix)
+	}
+
+	config.Discovery.IPPivotPrefix = 8
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() should reject IP pivot prefixes wider than /16")
+	}
+}
+
+func TestConfigValidateKeywords(t *testing.T) {
+	config := DefaultConfig()
+	config.Keywords = []string{"=hp", "~paypal", "!partner-", `/^api\d+\./`}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() returned error for valid keywords: %v", err)
+	}
+
+	config.Keywords = []string{"/[unclosed/"}
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() should reject an invalid regex keyword")
+	}
+}
@@ -0,0 +1,95 @@
+package domainscan
+
+import (
+	"context"
+	"sort"
+
+	"github.com/valllabh/domain-scan/pkg/discovery"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// contentScanWithTracking crawls the pages of live domains for hostnames referenced by links,
+// JavaScript bundles, CSP and CORS headers and feeds keyword-matching names back into discovery.
+// Repeats until no new names are found since fed-back domains serve pages of their own.
+func (s *Scanner) contentScanWithTracking(ctx context.Context, matcher *utils.Matcher, outputDomains map[string]*DomainEntry, processedDomains map[string]bool) {
+	for ctx.Err() == nil {
+		if s.config.Discovery.MaxDomains > 0 && len(outputDomains) >= s.config.Discovery.MaxDomains {
+			s.logInfo("Max domains limit reached (%d), skipping content discovery", s.config.Discovery.MaxDomains)
+			return
+		}
+
+		// Crawl the page a live domain ends up on, following its redirect when there is one
+		pages := make(map[string]string)
+		var domains []string
+		for domain, entry := range outputDomains {
+			if entry.Reachable && entry.URL != "" {
+				domains = append(domains, domain)
+			}
+		}
+		for _, domain := range s.filterUnprocessedDomains(domains, processedDomains, "content") {
+			entry := outputDomains[domain]
+			pages[domain] = entry.URL
+			if entry.Redirect != nil && entry.Redirect.IsRedirect && entry.Redirect.RedirectsTo != "" {
+				pages[domain] = entry.Redirect.RedirectsTo
+			}
+		}
+		if len(pages) == 0 {
+			return
+		}
+
+		// The stage starts one level below the shallowest crawled domain
+		stageDepth := -1
+		for domain := range pages {
+			if depth := depthOf(domain, outputDomains) + 1; stageDepth < 0 || depth < stageDepth {
+				stageDepth = depth
+			}
+		}
+		stageEnd := s.stageStart(StageContent, stageDepth, len(pages))
+		found := discovery.CrawlContent(ctx, pages, s.config.Discovery.probeOptions(), s.config.Discovery.Threads, s.config.Discovery.Timeout, s.logger)
+		newByDepth := s.recordContentNames(found, matcher, outputDomains)
+
+		total := 0
+		depths := make([]int, 0, len(newByDepth))
+		for depth, domains := range newByDepth {
+			depths = append(depths, depth)
+			total += len(domains)
+		}
+		s.logInfo("Content discovery found %d new domains", total)
+		stageEnd(total)
+		if total == 0 {
+			return
+		}
+		sort.Ints(depths)
+		for _, depth := range depths {
+			if ctx.Err() != nil {
+				return
+			}
+			s.scanFeedbackDomains(ctx, newByDepth[depth], matcher, outputDomains, processedDomains, depth)
+		}
+	}
+}
+
+// recordContentNames records the keyword-matching hostnames found in page content, one level
+// below the domain whose page referenced them. Returns the new domains grouped by discovery depth.
+func (s *Scanner) recordContentNames(found map[string]discovery.ContentOrigin, matcher *utils.Matcher, outputDomains map[string]*DomainEntry) map[int][]string {
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	newByDepth := make(map[int][]string)
+	for _, name := range names {
+		origin := found[name]
+		if utils.IsIPTarget(name) || !matcher.Matches(name) {
+			continue
+		}
+		depth := depthOf(origin.Host, outputDomains) + 1
+		source := newDiscoverySource("content-"+origin.Location, "content", "content", origin.Host, seedOf(origin.Host, outputDomains), depth)
+		if s.recordDiscovery(name, source, outputDomains) {
+			s.logInfo("Found %s in %s content of %s", name, origin.Location, origin.URL)
+			newByDepth[depth] = append(newByDepth[depth], name)
+		}
+	}
+	return newByDepth
+}
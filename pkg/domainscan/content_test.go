@@ -0,0 +1,43 @@
+package domainscan
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/valllabh/domain-scan/pkg/discovery"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+func TestRecordContentNames(t *testing.T) {
+	config := DefaultConfig()
+	config.LogLevel = "silent"
+	scanner := New(config)
+
+	outputDomains := map[string]*DomainEntry{
+		"example.com": {Domain: "example.com",
+			Sources: []types.Source{{Name: "input", Type: "seed", Seed: "example.com", Stage: "seed"}}},
+		"app.example.com": {Domain: "app.example.com",
+			Sources: []types.Source{{Name: "certificate-san", Type: "certificate", Stage: "certificate", Parent: "www.example.com", Seed: "example.com", Depth: 2}}},
+	}
+	found := map[string]discovery.ContentOrigin{
+		"cdn.example.com":        {Host: "example.com", URL: "https://example.com/", Location: discovery.ContentHTML},
+		"api.example.com":        {Host: "app.example.com", URL: "https://app.example.com/main.js", Location: discovery.ContentJavaScript},
+		"tracker.thirdparty.net": {Host: "example.com", URL: "https://example.com/", Location: discovery.ContentHTML},
+		"192.0.2.1":              {Host: "example.com", URL: "https://example.com/", Location: discovery.ContentCSP},
+	}
+
+	newByDepth := scanner.recordContentNames(found, newTestMatcher(t, "example"), outputDomains)
+
+	// Names sit one level below the domain whose page referenced them
+	expected := map[int][]string{1: {"cdn.example.com"}, 3: {"api.example.com"}}
+	if !reflect.DeepEqual(newByDepth, expected) {
+		t.Errorf("Expected new domains by depth %v, got %v", expected, newByDepth)
+	}
+	api := outputDomains["api.example.com"].Sources
+	if len(api) != 1 || api[0].Name != "content-"+discovery.ContentJavaScript || api[0].Parent != "app.example.com" || api[0].Seed != "example.com" || api[0].Depth != 3 {
+		t.Errorf("Unexpected content source: %+v", api)
+	}
+	if _, exists := outputDomains["tracker.thirdparty.net"]; exists {
+		t.Error("Expected names not matching keywords to be skipped")
+	}
+}
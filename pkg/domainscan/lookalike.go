@@ -0,0 +1,131 @@
+package domainscan
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/discovery"
+	"github.com/valllabh/domain-scan/pkg/types"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// LookalikeRequest configures a lookalike domain check
+type LookalikeRequest struct {
+	Domains    []string              // Domains whose registrable domains are imitated
+	TLDs       []string              // Suffixes used for TLD swaps (nil = the embedded TLD list)
+	Techniques []string              // Generation techniques (empty = all, see discovery.LookalikeTechniques)
+	Resolver   discovery.DNSResolver // Resolver for registration checks (nil = system resolver)
+}
+
+// LookalikeResult holds the registered lookalikes and how many variants were checked
+type LookalikeResult struct {
+	Result     *AssetDiscoveryResult `json:"result"`
+	Candidates int                   `json:"candidates"` // Variants generated and looked up
+}
+
+// Lookalikes generates typo, homoglyph, bit-flip, hyphenation and TLD-swap variants of the
+// registrable domains of req.Domains, keeps the registered ones and probes those that
+// resolve over HTTP/TLS. Every entry has a "lookalike" source named after its technique,
+// with the imitated domain as parent and seed.
+func (s *Scanner) Lookalikes(ctx context.Context, req *LookalikeRequest) (*LookalikeResult, error) {
+	if req == nil || len(req.Domains) == 0 {
+		return nil, NewError(ErrInvalidConfig, "no domains provided", nil)
+	}
+	known := make(map[string]bool, len(discovery.LookalikeTechniques))
+	for _, technique := range discovery.LookalikeTechniques {
+		known[technique] = true
+	}
+	for _, technique := range req.Techniques {
+		if !known[technique] {
+			return nil, NewError(ErrInvalidConfig, fmt.Sprintf("unknown lookalike technique %q", technique), nil)
+		}
+	}
+	tlds := req.TLDs
+	if tlds == nil {
+		tlds = utils.TLDs()
+	}
+
+	variants := make(map[string]discovery.Lookalike)
+	var originals []string
+	for _, domain := range req.Domains {
+		generated := discovery.GenerateLookalikes(domain, tlds, req.Techniques)
+		if len(generated) == 0 {
+			s.logWarn("No lookalikes generated for %s", domain)
+			continue
+		}
+		originals = append(originals, generated[0].Original)
+		for _, variant := range generated {
+			if _, exists := variants[variant.Domain]; !exists {
+				variants[variant.Domain] = variant
+			}
+		}
+	}
+	// The imitated domains are not lookalikes of each other
+	for _, original := range originals {
+		delete(variants, original)
+	}
+	candidates := make([]string, 0, len(variants))
+	for domain := range variants {
+		candidates = append(candidates, domain)
+	}
+	sort.Strings(candidates)
+
+	if s.progress != nil {
+		s.progress.OnStart(originals, nil)
+	}
+
+	stageEnd := s.stageStart(StageLookalike, 0, len(candidates))
+	registered := discovery.CheckRegistrations(ctx, candidates, req.Resolver, s.config.Discovery.Threads, s.config.Discovery.Timeout, s.logger)
+	stageEnd(len(registered))
+
+	checkedAt := time.Now()
+	outputDomains := make(map[string]*DomainEntry, len(registered))
+	var resolving []string
+	for domain, registration := range registered {
+		variant := variants[domain]
+		entry := &DomainEntry{Domain: domain, UnicodeDomain: utils.ToUnicode(domain), Sources: []types.Source{}}
+		source := newDiscoverySource(variant.Technique, "lookalike", StageLookalike, variant.Original, variant.Original, 0)
+		source.DiscoveredAt = checkedAt
+		addDiscoverySource(entry, source)
+		if len(registration.IPs) > 0 {
+			entry.IP = registration.IPs[0]
+			resolving = append(resolving, domain)
+		}
+		outputDomains[domain] = entry
+		s.logInfo("Registered lookalike %s of %s (%s)", domain, variant.Original, variant.Technique)
+	}
+	sort.Strings(resolving)
+
+	if len(resolving) > 0 {
+		probeEnd := s.stageStart(StageHTTP, 0, len(resolving))
+		entries, _, _, err := discovery.BulkCertificateAnalysisWithProbe(ctx, resolving, nil, false, s.config.Discovery.probeOptions(), s.logger)
+		if err != nil {
+			s.logWarn("Lookalike probing error: %v", err)
+			s.reportError(StageHTTP, err)
+		}
+		s.mergeProbeResults(entries, outputDomains, "Lookalike")
+		probeEnd(0)
+
+		// Keep the resolved address of lookalikes that didn't answer over HTTP
+		for _, domain := range resolving {
+			if entry := outputDomains[domain]; entry.IP == "" {
+				entry.IP = registered[domain].IPs[0]
+			}
+		}
+	}
+
+	result := &AssetDiscoveryResult{
+		Domains:    outputDomains,
+		Statistics: DiscoveryStats{},
+		Errors:     []error{},
+	}
+	result.TagSeeds(originals)
+	result.UpdateStatistics()
+
+	if s.progress != nil {
+		s.progress.OnEnd(result)
+	}
+	return &LookalikeResult{Result: result, Candidates: len(candidates)}, nil
+}
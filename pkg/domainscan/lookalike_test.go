@@ -0,0 +1,72 @@
+package domainscan
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/valllabh/domain-scan/pkg/discovery"
+)
+
+// delegationResolver answers name server lookups from a static table and resolves nothing
+type delegationResolver struct {
+	nameServers map[string]string
+}
+
+func (r *delegationResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return nil, errors.New("no such host")
+}
+
+func (r *delegationResolver) LookupNS(ctx context.Context, name string) ([]*net.NS, error) {
+	if host, ok := r.nameServers[name]; ok {
+		return []*net.NS{{Host: host}}, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func TestLookalikes(t *testing.T) {
+	config := DefaultConfig()
+	config.LogLevel = "silent"
+	scanner := New(config)
+
+	resolver := &delegationResolver{nameServers: map[string]string{
+		"example.net": "ns1.registrar.example.",
+		"example.com": "ns1.example.com.", // Imitated domain, never reported
+	}}
+	checked, err := scanner.Lookalikes(context.Background(), &LookalikeRequest{
+		Domains:    []string{"www.example.com", "example.org"},
+		TLDs:       []string{"com", "net", "org", "io"},
+		Techniques: []string{discovery.LookalikeTLDSwap},
+		Resolver:   resolver,
+	})
+	if err != nil {
+		t.Fatalf("Lookalikes() error: %v", err)
+	}
+
+	// example.com and example.org imitate each other, leaving .net and .io
+	if checked.Candidates != 2 {
+		t.Errorf("Expected 2 candidates, got %d", checked.Candidates)
+	}
+	if len(checked.Result.Domains) != 1 {
+		t.Fatalf("Expected only example.net to be registered, got %v", checked.Result.Domains)
+	}
+	entry := checked.Result.Domains["example.net"]
+	if entry == nil || len(entry.Sources) != 1 {
+		t.Fatalf("Expected example.net with one source, got %+v", entry)
+	}
+	source := entry.Sources[0]
+	if source.Name != discovery.LookalikeTLDSwap || source.Type != "lookalike" || source.Stage != StageLookalike || source.Parent != "example.com" {
+		t.Errorf("Unexpected lookalike source: %+v", source)
+	}
+	if entry.Reachable || entry.IP != "" {
+		t.Errorf("Expected an unresolved registration, got %+v", entry)
+	}
+	if checked.Result.Statistics.TotalSubdomains != 1 {
+		t.Errorf("Expected statistics to count the lookalike, got %+v", checked.Result.Statistics)
+	}
+
+	if _, err := scanner.Lookalikes(context.Background(), &LookalikeRequest{Domains: []string{"example.com"}, Techniques: []string{"anagram"}}); err == nil {
+		t.Error("Expected an unknown technique to be rejected")
+	}
+}
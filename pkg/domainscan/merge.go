@@ -0,0 +1,95 @@
+package domainscan
+
+import (
+	"sort"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// MergeResults unions several results into a new one. Sources are deduplicated like
+// during a scan (same name, type, parent and certificate), keeping the earliest
+// discovery. The HTTP/TLS fields of each domain come from its most recently probed
+// entry, and an unprobed entry never replaces a probed one; on ties later results win.
+// Seeds are unioned and statistics are recomputed. The input results are not modified.
+func MergeResults(results ...*AssetDiscoveryResult) *AssetDiscoveryResult {
+	merged := &AssetDiscoveryResult{
+		Domains:    make(map[string]*DomainEntry),
+		Statistics: DiscoveryStats{},
+		Errors:     []error{},
+	}
+
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		for name, entry := range result.Domains {
+			existing, exists := merged.Domains[name]
+			if !exists {
+				copied := *entry
+				copied.Sources = append([]types.Source(nil), entry.Sources...)
+				copied.Seeds = append([]string(nil), entry.Seeds...)
+				merged.Domains[name] = &copied
+				continue
+			}
+
+			if fresher(entry, existing) {
+				existing.URL = entry.URL
+				existing.Status = entry.Status
+				existing.Reachable = entry.Reachable
+				existing.IP = entry.IP
+				existing.CNAMEs = entry.CNAMEs
+				existing.Redirect = entry.Redirect
+				existing.Certificate = entry.Certificate
+				existing.ProbedAt = entry.ProbedAt
+			}
+			for _, source := range entry.Sources {
+				addDiscoverySource(existing, source)
+			}
+			existing.Seeds = unionSeeds(existing.Seeds, entry.Seeds)
+		}
+	}
+
+	merged.UpdateStatistics()
+	return merged
+}
+
+// fresher reports whether entry's HTTP/TLS fields should replace those of existing: the
+// later probe wins, and a probed entry always beats an unprobed one. Two unprobed entries
+// (e.g. from results saved before probe times were recorded) compare their latest source
+// discovery. Ties go to entry.
+func fresher(entry *DomainEntry, existing *DomainEntry) bool {
+	switch {
+	case !entry.ProbedAt.IsZero() && !existing.ProbedAt.IsZero():
+		return !entry.ProbedAt.Before(existing.ProbedAt)
+	case !entry.ProbedAt.IsZero() || !existing.ProbedAt.IsZero():
+		return !entry.ProbedAt.IsZero()
+	default:
+		return !latestDiscovery(entry).Before(latestDiscovery(existing))
+	}
+}
+
+// latestDiscovery returns the latest discovery time of an entry's sources
+func latestDiscovery(entry *DomainEntry) time.Time {
+	var latest time.Time
+	for _, src := range entry.Sources {
+		if src.DiscoveredAt.After(latest) {
+			latest = src.DiscoveredAt
+		}
+	}
+	return latest
+}
+
+// unionSeeds returns the sorted union of two seed lists
+func unionSeeds(a []string, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var seeds []string
+	for _, seed := range append(append([]string(nil), a...), b...) {
+		if !seen[seed] {
+			seen[seed] = true
+			seeds = append(seeds, seed)
+		}
+	}
+	sort.Strings(seeds)
+	return seeds
+}
@@ -0,0 +1,95 @@
+package domainscan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+func TestMergeResults(t *testing.T) {
+	day1 := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	cert := &types.CertificateInfo{Subject: "api.example.com", Issuer: "R3", IssuedOn: day1}
+	renewed := &types.CertificateInfo{Subject: "api.example.com", Issuer: "R3", IssuedOn: day2}
+
+	a := &AssetDiscoveryResult{Domains: map[string]*DomainEntry{
+		"example.com": {Domain: "example.com", Reachable: true, Status: 200, ProbedAt: day2, Seeds: []string{"example.com"},
+			Sources: []types.Source{{Name: "input", Type: "seed", Seed: "example.com", DiscoveredAt: day2}}},
+		"api.example.com": {Domain: "api.example.com", Reachable: true, Status: 200, Certificate: renewed, ProbedAt: day2, Seeds: []string{"example.com"},
+			Sources: []types.Source{
+				{Name: "subfinder", Type: "passive", Parent: "example.com", Seed: "example.com", DiscoveredAt: day2},
+				{Name: "certificate-san", Type: "certificate", Parent: "example.com", Certificate: renewed, DiscoveredAt: day2},
+			}},
+	}}
+	b := &AssetDiscoveryResult{Domains: map[string]*DomainEntry{
+		"example.com": {Domain: "example.com", Reachable: false, ProbedAt: day1, Seeds: []string{"example.com"}},
+		"api.example.com": {Domain: "api.example.com", Reachable: true, Status: 503, Certificate: cert, ProbedAt: day1, Seeds: []string{"example.org"},
+			Sources: []types.Source{
+				{Name: "subfinder", Type: "passive", Parent: "example.com", Seed: "example.com", DiscoveredAt: day1},
+				{Name: "certificate-san", Type: "certificate", Parent: "example.com", Certificate: cert, DiscoveredAt: day1},
+				{Name: "chaos", Type: "passive", Parent: "example.com", DiscoveredAt: day1},
+			}},
+		"dev.example.com": {Domain: "dev.example.com", Sources: []types.Source{{Name: "chaos", Type: "passive", DiscoveredAt: day1}}},
+	}}
+
+	merged := MergeResults(a, b)
+
+	if merged.Statistics.TotalSubdomains != 3 || merged.Statistics.ActiveServices != 2 {
+		t.Errorf("Expected recomputed statistics of 3 domains and 2 live, got %+v", merged.Statistics)
+	}
+
+	// Fresher probe data wins regardless of argument order
+	root := merged.Domains["example.com"]
+	if !root.Reachable || root.Status != 200 || !root.ProbedAt.Equal(day2) {
+		t.Errorf("Expected the fresher probe of example.com to win: %+v", root)
+	}
+	api := merged.Domains["api.example.com"]
+	if api.Status != 200 || api.Certificate != renewed {
+		t.Errorf("Expected the fresher probe of api.example.com to win: %+v", api)
+	}
+
+	// Same discovery is recorded once with the earliest time; different certificates stay distinct
+	if len(api.Sources) != 4 {
+		t.Fatalf("Expected 4 distinct sources, got %d: %+v", len(api.Sources), api.Sources)
+	}
+	if !api.Sources[0].DiscoveredAt.Equal(day1) {
+		t.Errorf("Expected the earliest subfinder discovery to be kept, got %s", api.Sources[0].DiscoveredAt)
+	}
+	if len(api.Seeds) != 2 || api.Seeds[0] != "example.com" || api.Seeds[1] != "example.org" {
+		t.Errorf("Expected the union of seeds, got %v", api.Seeds)
+	}
+
+	// Inputs are left untouched
+	if len(a.Domains["api.example.com"].Sources) != 2 || a.Domains["api.example.com"].Seeds[0] != "example.com" || len(a.Domains) != 2 {
+		t.Error("Expected the input results to be left unchanged")
+	}
+
+	// Without probe times the latest source discovery decides; ties go to the later result
+	older := &AssetDiscoveryResult{Domains: map[string]*DomainEntry{
+		"www.example.com": {Domain: "www.example.com", Status: 200, Reachable: true, Sources: []types.Source{{Name: "subfinder", Type: "passive", DiscoveredAt: day2}}},
+	}}
+	newer := &AssetDiscoveryResult{Domains: map[string]*DomainEntry{
+		"www.example.com": {Domain: "www.example.com", Status: 404, Reachable: true, Sources: []types.Source{{Name: "subfinder", Type: "passive", DiscoveredAt: day2}}},
+	}}
+	if got := MergeResults(older, newer).Domains["www.example.com"].Status; got != 404 {
+		t.Errorf("Expected the later result to win a tie, got status %d", got)
+	}
+	// An unprobed entry, even one discovered later, never replaces a probed one
+	probed := &AssetDiscoveryResult{Domains: map[string]*DomainEntry{
+		"www.example.com": {Domain: "www.example.com", URL: "https://www.example.com", Status: 200, Reachable: true, ProbedAt: day1,
+			Sources: []types.Source{{Name: "subfinder", Type: "passive", DiscoveredAt: day1}}},
+	}}
+	unprobed := &AssetDiscoveryResult{Domains: map[string]*DomainEntry{
+		"www.example.com": {Domain: "www.example.com", Sources: []types.Source{{Name: "chaos", Type: "passive", DiscoveredAt: day2}}},
+	}}
+	for _, results := range [][]*AssetDiscoveryResult{{probed, unprobed}, {unprobed, probed}} {
+		www := MergeResults(results...).Domains["www.example.com"]
+		if www.Status != 200 || !www.Reachable || !www.ProbedAt.Equal(day1) || len(www.Sources) != 2 {
+			t.Errorf("Expected the probed entry's fields to be kept, got %+v", www)
+		}
+	}
+	if got := MergeResults(nil, older).Statistics.TotalSubdomains; got != 1 {
+		t.Errorf("Expected nil results to be skipped, got %d domains", got)
+	}
+}
@@ -1,5 +1,11 @@
 package domainscan
 
+import (
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
 // ProgressCallback provides optional progress updates for long-running operations
 type ProgressCallback interface {
 	// OnStart is called when domain asset discovery begins
@@ -11,3 +17,47 @@ type ProgressCallback interface {
 	// OnEnd is called when the entire scan finishes
 	OnEnd(result *AssetDiscoveryResult)
 }
+
+// Discovery stages reported through ExtendedProgressCallback
+const (
+	StagePassive     = "passive"     // Subfinder enumeration
+	StageCertificate = "certificate" // TLS analysis and SAN extraction
+	StageHTTP        = "http"        // HTTP verification without certificate discovery
+	StageIPSeed      = "ip-seed"     // Certificate grabs on IP and CIDR seeds
+	StageIPPivot     = "ip-pivot"    // PTR lookups and TLS grabs on neighbouring IPs
+	StageActive      = "active"      // Permutation and brute-force resolution
+	StageContent     = "content"     // Hostnames referenced by live pages, scripts and headers
+	StageLookalike   = "lookalike"   // Registration checks of lookalike domains
+)
+
+// StageInfo describes a batch of work within a discovery stage
+type StageInfo struct {
+	Stage   string `json:"stage"`   // One of the Stage* constants
+	Depth   int    `json:"depth"`   // Recursion depth of the batch
+	Targets int    `json:"targets"` // Number of domains (or IPs) processed in the batch
+}
+
+// ExtendedProgressCallback is an optional extension of ProgressCallback with stage-level
+// events. Scanners detect it via type assertion, so plain ProgressCallback
+// implementations keep working unchanged.
+type ExtendedProgressCallback interface {
+	ProgressCallback
+
+	// OnStageStart is called when a batch of a discovery stage begins
+	OnStageStart(stage StageInfo)
+
+	// OnStageEnd is called when a batch finishes with the number of domains it found
+	OnStageEnd(stage StageInfo, found int, elapsed time.Duration)
+
+	// OnDomainDiscovered is called when a domain is first added to the results
+	OnDomainDiscovered(domain string, source types.Source)
+
+	// OnDomainVerified is called after a domain has been probed over HTTP/TLS
+	OnDomainVerified(entry *DomainEntry)
+
+	// OnError is called for non-fatal errors; the scan continues
+	OnError(stage string, err error)
+
+	// OnDepthChange is called when recursion reaches a new depth for the first time
+	OnDepthChange(depth int)
+}
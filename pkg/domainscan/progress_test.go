@@ -0,0 +1,87 @@
+package domainscan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// recordingProgress records extended progress events as short strings
+type recordingProgress struct {
+	events []string
+}
+
+func (r *recordingProgress) OnStart(domains []string, keywords []string) {}
+func (r *recordingProgress) OnProgress(totalDomains, liveDomains int)    {}
+func (r *recordingProgress) OnEnd(result *AssetDiscoveryResult)          {}
+func (r *recordingProgress) OnStageStart(stage StageInfo) {
+	r.events = append(r.events, "start:"+stage.Stage)
+}
+func (r *recordingProgress) OnStageEnd(stage StageInfo, found int, elapsed time.Duration) {
+	r.events = append(r.events, "end:"+stage.Stage)
+}
+func (r *recordingProgress) OnDomainDiscovered(domain string, source types.Source) {
+	r.events = append(r.events, "discovered:"+domain)
+}
+func (r *recordingProgress) OnDomainVerified(entry *DomainEntry) {
+	r.events = append(r.events, "verified:"+entry.Domain)
+}
+func (r *recordingProgress) OnError(stage string, err error) {
+	r.events = append(r.events, "error:"+stage)
+}
+func (r *recordingProgress) OnDepthChange(depth int) {
+	r.events = append(r.events, "depth")
+}
+
+// plainProgress implements only the base ProgressCallback
+type plainProgress struct {
+	progressCalls int
+}
+
+func (p *plainProgress) OnStart(domains []string, keywords []string) {}
+func (p *plainProgress) OnProgress(totalDomains, liveDomains int)    { p.progressCalls++ }
+func (p *plainProgress) OnEnd(result *AssetDiscoveryResult)          {}
+
+// emitScanEvents drives the scanner's progress hooks the way a scan batch does
+func emitScanEvents(s *Scanner) {
+	outputDomains := make(map[string]*DomainEntry)
+	processed := make(map[string]bool)
+	source := newDiscoverySource("subfinder", "passive", "passive", "example.com", "example.com", 0)
+
+	s.reportDepth(0, processed)
+	s.reportDepth(0, processed)
+	stageEnd := s.stageStart(StagePassive, 0, 1)
+	s.recordDiscovery("api.example.com", source, outputDomains)
+	s.recordDiscovery("api.example.com", source, outputDomains)
+	stageEnd(1)
+	s.reportError(StageCertificate, errors.New("boom"))
+	s.mergeDomainEntries([]*DomainEntry{{Domain: "api.example.com", Reachable: true}}, outputDomains, "Added")
+}
+
+func TestExtendedProgressEvents(t *testing.T) {
+	recorder := &recordingProgress{}
+	scanner := &Scanner{config: DefaultConfig()}
+	scanner.SetProgressCallback(recorder)
+
+	emitScanEvents(scanner)
+
+	expected := "depth,start:passive,discovered:api.example.com,end:passive,error:certificate,verified:api.example.com"
+	if got := strings.Join(recorder.events, ","); got != expected {
+		t.Errorf("Expected events %s, got %s", expected, got)
+	}
+}
+
+func TestPlainProgressCallbackStillWorks(t *testing.T) {
+	plain := &plainProgress{}
+	scanner := &Scanner{config: DefaultConfig()}
+	scanner.SetProgressCallback(plain)
+
+	emitScanEvents(scanner)
+
+	if plain.progressCalls != 1 {
+		t.Errorf("Expected 1 OnProgress call, got %d", plain.progressCalls)
+	}
+}
@@ -0,0 +1,76 @@
+package domainscan
+
+import (
+	"fmt"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// ProvenanceStep is one hop of a discovery chain: a domain and the source that found it
+type ProvenanceStep struct {
+	Domain string       `json:"domain"`
+	Source types.Source `json:"source"`
+}
+
+// Provenance returns the discovery chain that led from a seed to domain, seed first.
+// Each step follows the earliest provenance source of an entry to its parent. The chain
+// ends at a seed, at a parent outside the result (e.g. an IP seed), or at an entry
+// without provenance such as results saved by older versions.
+func (r *AssetDiscoveryResult) Provenance(domain string) ([]ProvenanceStep, error) {
+	domain = utils.NormalizeTarget(domain)
+	if _, exists := r.Domains[domain]; !exists {
+		return nil, fmt.Errorf("domain %s not found in result", domain)
+	}
+
+	var chain []ProvenanceStep
+	visited := make(map[string]bool)
+	current := domain
+	for current != "" && !visited[current] {
+		visited[current] = true
+
+		entry, exists := r.Domains[current]
+		if !exists {
+			// Parent outside the result, such as the IP a certificate was grabbed from
+			chain = append(chain, ProvenanceStep{Domain: current, Source: types.Source{Stage: "seed", Seed: current}})
+			break
+		}
+		source, ok := discoveryOrigin(entry)
+		if !ok {
+			chain = append(chain, ProvenanceStep{Domain: current})
+			break
+		}
+		chain = append(chain, ProvenanceStep{Domain: current, Source: source})
+		if source.Type == "seed" {
+			break
+		}
+		current = source.Parent
+	}
+
+	// Reverse so the chain reads from seed to domain
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// discoveryOrigin picks the source that first discovered an entry: a seed source if present,
+// otherwise the earliest source with provenance whose parent is another host
+func discoveryOrigin(entry *DomainEntry) (types.Source, bool) {
+	var origin types.Source
+	found := false
+	for _, src := range entry.Sources {
+		if src.Type == "seed" {
+			return src, true
+		}
+		if src.Stage == "" || src.Parent == "" || src.Parent == entry.Domain {
+			continue
+		}
+		if !found || src.DiscoveredAt.Before(origin.DiscoveredAt) ||
+			(src.DiscoveredAt.Equal(origin.DiscoveredAt) && src.Depth < origin.Depth) {
+			origin = src
+			found = true
+		}
+	}
+	return origin, found
+}
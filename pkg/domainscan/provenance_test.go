@@ -0,0 +1,115 @@
+package domainscan
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+func TestProvenance(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := &AssetDiscoveryResult{Domains: map[string]*DomainEntry{
+		"example.com": {
+			Domain:  "example.com",
+			Sources: []types.Source{{Name: "input", Type: "seed", Seed: "example.com", Stage: "seed", DiscoveredAt: start}},
+		},
+		"api.example.com": {
+			Domain: "api.example.com",
+			Sources: []types.Source{
+				{Name: "httpx", Type: "http"},
+				{Name: "subfinder", Type: "passive", Parent: "example.com", Seed: "example.com", Stage: "passive", DiscoveredAt: start.Add(time.Second)},
+			},
+		},
+		"dev.example.com": {
+			Domain: "dev.example.com",
+			Sources: []types.Source{
+				{Name: "certificate-san", Type: "certificate", Parent: "dev.example.com", Stage: "certificate", DiscoveredAt: start},
+				{Name: "certificate-san", Type: "certificate", Parent: "api.example.com", Seed: "example.com", Stage: "certificate", Depth: 1, DiscoveredAt: start.Add(3 * time.Second)},
+				{Name: "subfinder", Type: "passive", Parent: "example.com", Seed: "example.com", Stage: "passive", DiscoveredAt: start.Add(5 * time.Second)},
+			},
+		},
+		"shop.example.net": {
+			Domain:  "shop.example.net",
+			Sources: []types.Source{{Name: "ip-certificate", Type: "certificate", Parent: "192.0.2.10", Seed: "192.0.2.10", Stage: "seed", DiscoveredAt: start}},
+		},
+		"legacy.example.com": {
+			Domain:  "legacy.example.com",
+			Sources: []types.Source{{Name: "subfinder", Type: "passive"}},
+		},
+	}}
+
+	tests := []struct {
+		domain   string
+		expected []string
+	}{
+		{domain: "example.com", expected: []string{"example.com"}},
+		{domain: "api.example.com", expected: []string{"example.com", "api.example.com"}},
+		{domain: "https://DEV.example.com/", expected: []string{"example.com", "api.example.com", "dev.example.com"}},
+		{domain: "shop.example.net", expected: []string{"192.0.2.10", "shop.example.net"}},
+		{domain: "legacy.example.com", expected: []string{"legacy.example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			chain, err := result.Provenance(tt.domain)
+			if err != nil {
+				t.Fatalf("Provenance() returned error: %v", err)
+			}
+			var got []string
+			for _, step := range chain {
+				got = append(got, step.Domain)
+			}
+			if strings.Join(got, " -> ") != strings.Join(tt.expected, " -> ") {
+				t.Errorf("Expected chain %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := result.Provenance("missing.example.com"); err == nil {
+		t.Error("Provenance() should fail for a domain not in the result")
+	}
+}
+
+func TestAddDiscoverySource(t *testing.T) {
+	cert := &types.CertificateInfo{Subject: "api.example.com", Issuer: "CA"}
+	entry := &DomainEntry{Domain: "dev.example.com"}
+
+	addDiscoverySource(entry, newDiscoverySource("certificate-san", "certificate", "certificate", "api.example.com", "example.com", 0))
+	addDiscoverySource(entry, newDiscoverySource("certificate-san", "certificate", "certificate", "api.example.com", "example.com", 1))
+	withCert := newDiscoverySource("certificate-san", "certificate", "certificate", "api.example.com", "example.com", 1)
+	withCert.Certificate = cert
+	addDiscoverySource(entry, withCert)
+	addDiscoverySource(entry, newDiscoverySource("certificate-san", "certificate", "certificate", "www.example.com", "example.com", 1))
+
+	if len(entry.Sources) != 3 {
+		t.Fatalf("Expected 3 sources, got %d: %+v", len(entry.Sources), entry.Sources)
+	}
+	if entry.Sources[0].Depth != 0 {
+		t.Errorf("Expected earliest discovery to be kept, got depth %d", entry.Sources[0].Depth)
+	}
+
+	// A repeated discovery with an earlier time replaces the recorded one, as in MergeResults
+	earlier := newDiscoverySource("certificate-san", "certificate", "certificate", "www.example.com", "example.com", 2)
+	earlier.DiscoveredAt = entry.Sources[2].DiscoveredAt.Add(-time.Hour)
+	addDiscoverySource(entry, earlier)
+	if len(entry.Sources) != 3 || entry.Sources[2].Depth != 2 {
+		t.Errorf("Expected the earlier discovery to replace the later one, got %+v", entry.Sources)
+	}
+}
+
+func TestEnumeratedParent(t *testing.T) {
+	enumerated := []string{"example.com", "api.example.com", "example.net"}
+	tests := map[string]string{
+		"www.example.com":    "example.com",
+		"v1.api.example.com": "api.example.com",
+		"mail.example.net":   "example.net",
+		"notexample.com":     "",
+	}
+	for subdomain, expected := range tests {
+		if got := enumeratedParent(subdomain, enumerated); got != expected {
+			t.Errorf("enumeratedParent(%q) = %q, expected %q", subdomain, got, expected)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package domainscan
+
+import (
+	"github.com/valllabh/domain-scan/pkg/discovery"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// redirectDiscoveries records the keyword-matching hosts that the probed domains redirected
+// to with a redirect source, and returns them so they are scanned like new SAN domains
+func (s *Scanner) redirectDiscoveries(domains []string, matcher *utils.Matcher, outputDomains map[string]*DomainEntry, depth int) []string {
+	var found []string
+	for _, domain := range domains {
+		entry, exists := outputDomains[domain]
+		if !exists {
+			continue
+		}
+		for _, host := range discovery.RedirectHosts(domain, entry.Redirect) {
+			if utils.IsIPTarget(host) || !matcher.Matches(host) {
+				s.logDebug("Skipping redirect target %s of %s (out of scope)", host, domain)
+				continue
+			}
+			source := newDiscoverySource("redirect", "redirect", "redirect", domain, seedOf(domain, outputDomains), depth)
+			if s.recordDiscovery(host, source, outputDomains) {
+				s.logInfo("Found %s via redirect from %s", host, domain)
+			}
+			found = append(found, host)
+		}
+	}
+	return found
+}
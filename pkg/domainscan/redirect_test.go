@@ -0,0 +1,57 @@
+package domainscan
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+func TestRedirectDiscoveries(t *testing.T) {
+	config := DefaultConfig()
+	config.LogLevel = "silent"
+	scanner := New(config)
+
+	outputDomains := map[string]*DomainEntry{
+		"example.com": {
+			Domain:  "example.com",
+			Sources: []types.Source{{Name: "input", Type: "seed", Seed: "example.com", Stage: "seed"}},
+			Redirect: &types.RedirectInfo{
+				IsRedirect:  true,
+				RedirectsTo: "https://accounts.example-group.com/login",
+				Chain: []types.RedirectHop{
+					{URL: "https://example.com", Status: 301, Location: "https://www.example.com/"},
+					{URL: "https://www.example.com/", Status: 302, Location: "https://accounts.example-group.com/login"},
+					{URL: "https://accounts.example-group.com/login", Status: 302, Location: "https://idp.thirdparty.net/sso"},
+					{URL: "https://idp.thirdparty.net/sso", Status: 200},
+				},
+			},
+		},
+		"www.example.com":    {Domain: "www.example.com", Sources: []types.Source{{Name: "subfinder", Type: "passive", Parent: "example.com", Seed: "example.com"}}},
+		"static.example.com": {Domain: "static.example.com"},
+	}
+
+	found := scanner.redirectDiscoveries([]string{"example.com", "static.example.com"}, newTestMatcher(t, "example"), outputDomains, 1)
+
+	expected := []string{"www.example.com", "accounts.example-group.com"}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Expected keyword-matching redirect hosts %v, got %v", expected, found)
+	}
+	if _, exists := outputDomains["idp.thirdparty.net"]; exists {
+		t.Error("Expected the out of scope redirect host to be skipped")
+	}
+
+	sister := outputDomains["accounts.example-group.com"]
+	if sister == nil || len(sister.Sources) != 1 {
+		t.Fatalf("Expected the sister domain to be recorded with one source, got %+v", sister)
+	}
+	source := sister.Sources[0]
+	if source.Name != "redirect" || source.Type != "redirect" || source.Stage != "redirect" || source.Parent != "example.com" || source.Seed != "example.com" || source.Depth != 1 {
+		t.Errorf("Unexpected redirect source: %+v", source)
+	}
+
+	// Known domains gain the redirect as an additional source
+	if www := outputDomains["www.example.com"]; len(www.Sources) != 2 || www.Sources[1].Name != "redirect" {
+		t.Errorf("Expected www.example.com to gain a redirect source, got %+v", www.Sources)
+	}
+}
@@ -1,8 +1,13 @@
 package domainscan
 
 import (
-	"github.com/valllabh/domain-scan/pkg/types"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
 	"time"
+
+	"github.com/valllabh/domain-scan/pkg/types"
 )
 
 // DomainEntry is now in types package
@@ -42,3 +47,57 @@ func DefaultScanRequest(domains []string) *ScanRequest {
 		Timeout:  10 * time.Second,
 	}
 }
+
+// UpdateStatistics recomputes domain counts from the Domains map
+func (r *AssetDiscoveryResult) UpdateStatistics() {
+	live := 0
+	for _, entry := range r.Domains {
+		if entry.Reachable {
+			live++
+		}
+	}
+	r.Statistics.TotalSubdomains = len(r.Domains)
+	r.Statistics.ActiveServices = live
+	r.Statistics.TracedDomains = r.Statistics.TotalSubdomains - r.Statistics.ActiveServices
+}
+
+// ReadResult decodes a saved result in JSON form. Both full results (--format json)
+// and domains.json files are accepted; statistics are recomputed from the domains.
+func ReadResult(r io.Reader) (*AssetDiscoveryResult, error) {
+	var saved struct {
+		Domains    map[string]*DomainEntry `json:"domains"`
+		Statistics DiscoveryStats          `json:"statistics"`
+	}
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	if saved.Domains == nil {
+		saved.Domains = make(map[string]*DomainEntry)
+	}
+
+	result := &AssetDiscoveryResult{
+		Domains:    saved.Domains,
+		Statistics: saved.Statistics,
+		Errors:     []error{},
+	}
+	if !hasSeeds(result) {
+		result.TagSeeds(nil)
+	}
+	result.UpdateStatistics()
+	return result, nil
+}
+
+// LoadResult reads a saved result from a JSON file
+func LoadResult(path string) (*AssetDiscoveryResult, error) {
+	file, err := os.Open(path) // #nosec G304 - user supplied result file
+	if err != nil {
+		return nil, fmt.Errorf("failed to open result: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	result, err := ReadResult(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return result, nil
+}
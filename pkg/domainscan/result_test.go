@@ -0,0 +1,38 @@
+package domainscan
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadResult(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "domains.json layout",
+			input: `{"domains": {"a.example.com": {"domain": "a.example.com", "reachable": true, "status": 200}, "b.example.com": {"domain": "b.example.com"}}}`,
+		},
+		{
+			name:  "full result with stale statistics",
+			input: `{"domains": {"a.example.com": {"domain": "a.example.com", "reachable": true, "status": 200}, "b.example.com": {"domain": "b.example.com"}}, "statistics": {"total_subdomains": 99}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ReadResult(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ReadResult() returned error: %v", err)
+			}
+			if result.Statistics.TotalSubdomains != 2 || result.Statistics.ActiveServices != 1 || result.Statistics.TracedDomains != 1 {
+				t.Errorf("Unexpected statistics: %+v", result.Statistics)
+			}
+		})
+	}
+
+	if _, err := ReadResult(strings.NewReader("not json")); err == nil {
+		t.Error("ReadResult() should fail on invalid JSON")
+	}
+}
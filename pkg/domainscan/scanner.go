@@ -2,7 +2,10 @@ package domainscan
 
 import (
 	"context"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/projectdiscovery/gologger"
 	"github.com/valllabh/domain-scan/pkg/discovery"
@@ -62,10 +65,44 @@ func (s *Scanner) ScanWithOptions(ctx context.Context, req *ScanRequest) (*Asset
 		return nil, NewError(ErrInvalidConfig, "no domains provided", nil)
 	}
 
-	domains := req.Domains
-	keywords := utils.LoadKeywords(domains, req.Keywords)
+	if err := utils.ValidateKeywords(req.Keywords); err != nil {
+		return nil, NewError(ErrInvalidConfig, "invalid keywords", err)
+	}
+
+	domains, ipSeeds, err := utils.SplitTargets(req.Domains)
+	if err != nil {
+		return nil, NewError(ErrInvalidConfig, "invalid scan targets", err)
+	}
 	outputDomains := make(map[string]*DomainEntry)
 
+	// Record input domains as the roots of every provenance chain
+	startedAt := time.Now()
+	for _, domain := range domains {
+		outputDomains[domain] = &DomainEntry{
+			Domain:        domain,
+			UnicodeDomain: utils.ToUnicode(domain),
+			Sources: []types.Source{{
+				Name:         "input",
+				Type:         "seed",
+				Seed:         domain,
+				Stage:        "seed",
+				DiscoveredAt: startedAt,
+			}},
+		}
+	}
+
+	// IP and CIDR seeds contribute the SAN domains of the certificates they serve
+	if len(ipSeeds) > 0 && ctx.Err() == nil {
+		domains = append(domains, s.seedFromIPs(ctx, ipSeeds, outputDomains)...)
+	}
+
+	// Keywords are parsed once per scan and matched against every discovered domain
+	keywords := utils.LoadKeywords(domains, req.Keywords)
+	matcher, err := utils.NewMatcher(keywords)
+	if err != nil {
+		return nil, NewError(ErrInvalidConfig, "invalid keywords", err)
+	}
+
 	// Global tracking to prevent infinite loops
 	processedDomains := make(map[string]bool)
 
@@ -73,20 +110,37 @@ func (s *Scanner) ScanWithOptions(ctx context.Context, req *ScanRequest) (*Asset
 		s.progress.OnStart(domains, keywords)
 	}
 
+	// Stages stop starting new work once ctx is cancelled or times out
 	s.logDebug("Starting passiveScan with domains: %v", domains)
-	s.passiveScanWithTracking(ctx, domains, keywords, outputDomains, processedDomains, 0)
+	s.passiveScanWithTracking(ctx, domains, matcher, outputDomains, processedDomains, 0)
 	s.logDebug("Completed passiveScan")
 
+	if s.config.Discovery.EnableIPPivot && ctx.Err() == nil {
+		s.ipPivotScanWithTracking(ctx, matcher, outputDomains, processedDomains)
+	}
+
+	if s.config.Discovery.EnableActive && ctx.Err() == nil {
+		s.activeScanWithTracking(ctx, matcher, outputDomains, processedDomains)
+	}
+
+	if s.config.Discovery.EnableContent && ctx.Err() == nil {
+		s.contentScanWithTracking(ctx, matcher, outputDomains, processedDomains)
+	}
+
 	result := &AssetDiscoveryResult{
 		Domains:    outputDomains,
 		Statistics: DiscoveryStats{},
 		Errors:     []error{},
 	}
 
-	// Update statistics
-	result.Statistics.TotalSubdomains = len(outputDomains)
-	result.Statistics.ActiveServices = s.countLiveDomainsFromMap(outputDomains)
-	result.Statistics.TracedDomains = result.Statistics.TotalSubdomains - result.Statistics.ActiveServices
+	// Tag entries with their seeds and update statistics
+	result.TagSeeds(req.Domains)
+	result.UpdateStatistics()
+
+	// A stopped scan returns what it found so far along with the context error
+	if err := ctx.Err(); err != nil {
+		return result, NewError(ErrTimeout, "scan stopped before completion", err)
+	}
 
 	if s.progress != nil {
 		s.progress.OnEnd(result)
@@ -123,9 +177,102 @@ func (s *Scanner) logError(msg string, args ...interface{}) {
 	}
 }
 
+// extendedProgress returns the progress callback if it implements ExtendedProgressCallback
+func (s *Scanner) extendedProgress() ExtendedProgressCallback {
+	extended, _ := s.progress.(ExtendedProgressCallback)
+	return extended
+}
+
+// stageStart reports the start of a stage batch and returns a function reporting its end
+func (s *Scanner) stageStart(stage string, depth int, targets int) func(found int) {
+	extended := s.extendedProgress()
+	if extended == nil {
+		return func(int) {}
+	}
+	info := StageInfo{Stage: stage, Depth: depth, Targets: targets}
+	extended.OnStageStart(info)
+	started := time.Now()
+	return func(found int) {
+		extended.OnStageEnd(info, found, time.Since(started))
+	}
+}
+
+// reportError reports a non-fatal stage error to extended progress callbacks
+func (s *Scanner) reportError(stage string, err error) {
+	if extended := s.extendedProgress(); extended != nil {
+		extended.OnError(stage, err)
+	}
+}
+
+// reportDepth reports the first time recursion reaches depth, tracked with a "depth:" key
+func (s *Scanner) reportDepth(depth int, processedDomains map[string]bool) {
+	key := "depth:" + strconv.Itoa(depth)
+	if processedDomains[key] {
+		return
+	}
+	processedDomains[key] = true
+	if extended := s.extendedProgress(); extended != nil {
+		extended.OnDepthChange(depth)
+	}
+}
+
+// recordDiscovery adds a discovery source to domain, creating its entry when needed.
+// Returns true if the domain is new to the results.
+func (s *Scanner) recordDiscovery(domain string, source types.Source, outputDomains map[string]*DomainEntry) bool {
+	entry, exists := outputDomains[domain]
+	if !exists {
+		entry = &DomainEntry{
+			Domain:        domain,
+			UnicodeDomain: utils.ToUnicode(domain),
+			Sources:       []types.Source{},
+		}
+		outputDomains[domain] = entry
+	}
+	addDiscoverySource(entry, source)
+	if !exists {
+		if extended := s.extendedProgress(); extended != nil {
+			extended.OnDomainDiscovered(domain, source)
+		}
+	}
+	return !exists
+}
+
+// seedFromIPs grabs TLS certificates served on IP seeds and returns their SAN domains.
+// Each SAN domain is recorded with the presenting certificate as its source.
+func (s *Scanner) seedFromIPs(ctx context.Context, ips []string, outputDomains map[string]*DomainEntry) []string {
+	stageEnd := s.stageStart(StageIPSeed, 0, len(ips))
+	sanDomains, sanOrigins, err := discovery.IPCertificateDiscovery(ctx, ips, s.config.Discovery.probeOptions(), s.logger)
+	if err != nil {
+		s.logWarn("IP certificate discovery error: %v", err)
+		s.reportError(StageIPSeed, err)
+		stageEnd(0)
+		return nil
+	}
+
+	s.logInfo("Seeding %d domains from certificates on %d IPs", len(sanDomains), len(ips))
+	s.recordIPSeeds(sanDomains, sanOrigins, outputDomains)
+	stageEnd(len(sanDomains))
+	return sanDomains
+}
+
+// recordIPSeeds records SAN domains of certificates served on IP seeds, with the
+// presenting IP as both parent and seed of their provenance chains
+func (s *Scanner) recordIPSeeds(sanDomains []string, sanOrigins map[string]discovery.SANOrigin, outputDomains map[string]*DomainEntry) {
+	for _, domain := range sanDomains {
+		origin := sanOrigins[domain]
+		source := newDiscoverySource("ip-certificate", "certificate", "seed", origin.Host, origin.Host, 0)
+		source.Certificate = origin.Certificate
+		s.recordDiscovery(domain, source, outputDomains)
+	}
+}
+
 // passiveScanWithTracking performs passive subdomain enumeration using subfinder.
 // Collects subdomains for each input domain and batches them for certificate analysis.
-func (s *Scanner) passiveScanWithTracking(ctx context.Context, domains []string, keywords []string, outputDomains map[string]*DomainEntry, processedDomains map[string]bool, depth int) {
+func (s *Scanner) passiveScanWithTracking(ctx context.Context, domains []string, matcher *utils.Matcher, outputDomains map[string]*DomainEntry, processedDomains map[string]bool, depth int) {
+	if ctx.Err() != nil {
+		return
+	}
+
 	// Check if passive discovery is disabled
 	if !s.config.Discovery.EnablePassive {
 		s.logDebug("Passive discovery disabled, skipping")
@@ -135,7 +282,7 @@ func (s *Scanner) passiveScanWithTracking(ctx context.Context, domains []string,
 
 		// If certificate discovery is enabled, also scan certificates for additional domains
 		if s.config.Discovery.EnableCertificate {
-			s.certificateScanWithTracking(ctx, domains, keywords, outputDomains, processedDomains, depth)
+			s.certificateScanWithTracking(ctx, domains, matcher, outputDomains, processedDomains, depth)
 		}
 		return
 	}
@@ -171,10 +318,15 @@ func (s *Scanner) passiveScanWithTracking(ctx context.Context, domains []string,
 		return
 	}
 
+	s.reportDepth(depth, processedDomains)
+	stageEnd := s.stageStart(StagePassive, depth, len(unprocessedDomains))
+
 	// Run bulk passive discovery with configured sources
-	subdomains, err := discovery.PassiveDiscoveryWithOptions(ctx, unprocessedDomains, s.config.Discovery.Sources, s.logger)
+	subdomains, err := discovery.PassiveDiscoveryWithProbe(ctx, unprocessedDomains, s.config.Discovery.Sources, s.config.Discovery.probeOptions(), s.logger)
 	if err != nil {
 		s.logError("Bulk passive discovery failed: %v", err)
+		s.reportError(StagePassive, err)
+		stageEnd(0)
 		return
 	}
 
@@ -183,31 +335,33 @@ func (s *Scanner) passiveScanWithTracking(ctx context.Context, domains []string,
 
 	// Track passive discovery source for all discovered subdomains
 	for _, subdomain := range subdomains {
-		entry, exists := outputDomains[subdomain]
-		if !exists {
-			entry = &DomainEntry{
-				Domain:  subdomain,
-				Sources: []types.Source{},
-			}
-			outputDomains[subdomain] = entry
-		}
-		addSource(entry, "subfinder", "passive")
+		parent := enumeratedParent(subdomain, unprocessedDomains)
+		s.recordDiscovery(subdomain, newDiscoverySource("subfinder", "passive", "passive", parent, seedOf(parent, outputDomains), depth), outputDomains)
 	}
+	stageEnd(len(subdomains))
 
 	// Prepare certificate scan batch with original domains + discovered subdomains
 	certScanBatch := make([]string, 0, len(unprocessedDomains)+len(subdomains))
 	certScanBatch = append(certScanBatch, unprocessedDomains...)
 	certScanBatch = append(certScanBatch, subdomains...)
 
+	if ctx.Err() != nil {
+		return
+	}
+
 	s.logInfo("Processing certificate scans for %d domains", len(certScanBatch))
 	s.logDebug("certScanBatch domains: %v", certScanBatch)
-	s.certificateScanWithTracking(ctx, certScanBatch, keywords, outputDomains, processedDomains, depth)
+	s.certificateScanWithTracking(ctx, certScanBatch, matcher, outputDomains, processedDomains, depth)
 	s.logInfo("Completed all certificate scans")
 }
 
 // certificateScanWithTracking performs certificate analysis on bulk domains.
 // Filters already processed domains and performs HTTP verification with certificate analysis.
-func (s *Scanner) certificateScanWithTracking(ctx context.Context, domains []string, keywords []string, outputDomains map[string]*DomainEntry, processedDomains map[string]bool, depth int) {
+func (s *Scanner) certificateScanWithTracking(ctx context.Context, domains []string, matcher *utils.Matcher, outputDomains map[string]*DomainEntry, processedDomains map[string]bool, depth int) {
+	if ctx.Err() != nil {
+		return
+	}
+
 	// Check if certificate discovery is disabled
 	if !s.config.Discovery.EnableCertificate {
 		s.logDebug("Certificate discovery disabled, skipping")
@@ -228,26 +382,28 @@ func (s *Scanner) certificateScanWithTracking(ctx context.Context, domains []str
 		return
 	}
 
-	newDomains, sanCertMap := s.bulkAnalyzeAndMerge(ctx, validDomains, keywords, s.config.Discovery.EnableCertificate, "cert", "certificate analysis", outputDomains, processedDomains)
+	s.reportDepth(depth, processedDomains)
+	stageEnd := s.stageStart(StageCertificate, depth, len(validDomains))
+	newDomains, sanOrigins := s.bulkAnalyzeAndMerge(ctx, validDomains, matcher, s.config.Discovery.EnableCertificate, "cert", "certificate analysis", outputDomains, processedDomains)
 
 	s.logInfo("Found %d new domains from certificate", len(newDomains))
 	s.logDebug("New domains: %v", newDomains)
 
 	// Track certificate SAN as source for newly discovered domains
 	for _, domain := range newDomains {
-		entry, exists := outputDomains[domain]
-		if !exists {
-			entry = &DomainEntry{
-				Domain:  domain,
-				Sources: []types.Source{},
-			}
-			outputDomains[domain] = entry
-		}
-		// Add certificate source with parent certificate info
-		certInfo := sanCertMap[domain]
-		addSourceWithCert(entry, "certificate-san", "certificate", certInfo)
+		// Add certificate source with the host that presented the certificate as parent
+		origin := sanOrigins[domain]
+		source := newDiscoverySource("certificate-san", "certificate", "certificate", origin.Host, seedOf(origin.Host, outputDomains), depth)
+		source.Certificate = origin.Certificate
+		s.recordDiscovery(domain, source, outputDomains)
 	}
 
+	// Hosts the probed domains redirect to often reveal sister domains
+	if s.config.Discovery.EnableRedirectDiscovery {
+		newDomains = append(newDomains, s.redirectDiscoveries(validDomains, matcher, outputDomains, depth)...)
+	}
+	stageEnd(len(newDomains))
+
 	// Only recurse if recursive discovery is enabled
 	if !s.config.Discovery.Recursive {
 		s.logDebug("Recursive discovery disabled, skipping recursion")
@@ -261,6 +417,10 @@ func (s *Scanner) certificateScanWithTracking(ctx context.Context, domains []str
 	}
 
 	for _, newDomain := range newDomains {
+		if ctx.Err() != nil {
+			return
+		}
+
 		// Check max domains limit before each recursive call
 		if s.config.Discovery.MaxDomains > 0 && len(outputDomains) >= s.config.Discovery.MaxDomains {
 			s.logInfo("Max domains limit reached (%d), stopping recursion", s.config.Discovery.MaxDomains)
@@ -269,14 +429,253 @@ func (s *Scanner) certificateScanWithTracking(ctx context.Context, domains []str
 
 		if s.isSubdomain(newDomain) {
 			s.logDebug("Recursively calling cert scan for subdomain: %s (depth %d)", newDomain, depth+1)
-			s.certificateScanWithTracking(ctx, []string{newDomain}, keywords, outputDomains, processedDomains, depth+1)
+			s.certificateScanWithTracking(ctx, []string{newDomain}, matcher, outputDomains, processedDomains, depth+1)
 		} else {
 			s.logDebug("Recursively calling passive scan for main domain: %s (depth %d)", newDomain, depth+1)
-			s.passiveScanWithTracking(ctx, []string{newDomain}, keywords, outputDomains, processedDomains, depth+1)
+			s.passiveScanWithTracking(ctx, []string{newDomain}, matcher, outputDomains, processedDomains, depth+1)
 		}
 	}
 }
 
+// scanFeedbackDomains feeds domains found by auxiliary stages (IP pivoting, brute-force, ...)
+// back into discovery. Main domains get passive enumeration when recursion is enabled, and
+// every domain is verified with certificate analysis, recursing into new SANs as usual.
+func (s *Scanner) scanFeedbackDomains(ctx context.Context, domains []string, matcher *utils.Matcher, outputDomains map[string]*DomainEntry, processedDomains map[string]bool, depth int) {
+	if len(domains) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	if s.config.Discovery.Recursive {
+		var mainDomains []string
+		for _, domain := range domains {
+			if !s.isSubdomain(domain) {
+				mainDomains = append(mainDomains, domain)
+			}
+		}
+		if len(mainDomains) > 0 {
+			s.passiveScanWithTracking(ctx, mainDomains, matcher, outputDomains, processedDomains, depth)
+		}
+	}
+
+	if !s.config.Discovery.EnableCertificate {
+		s.httpVerificationOnly(ctx, domains, outputDomains, processedDomains)
+		return
+	}
+	s.certificateScanWithTracking(ctx, domains, matcher, outputDomains, processedDomains, depth)
+}
+
+// ipPivotScanWithTracking expands every resolved IP to its configured network, performs PTR
+// lookups and TLS grabs on the neighbouring IPs, and feeds keyword-matching names back into
+// discovery. Repeats until no new names are found since fed-back domains may resolve to new networks.
+func (s *Scanner) ipPivotScanWithTracking(ctx context.Context, matcher *utils.Matcher, outputDomains map[string]*DomainEntry, processedDomains map[string]bool) {
+	for ctx.Err() == nil {
+		if s.config.Discovery.MaxDomains > 0 && len(outputDomains) >= s.config.Discovery.MaxDomains {
+			s.logInfo("Max domains limit reached (%d), skipping IP pivot", s.config.Discovery.MaxDomains)
+			return
+		}
+
+		ranges, rangeOwners := s.pivotRanges(outputDomains)
+		ranges = s.filterUnprocessedDomains(ranges, processedDomains, "pivot")
+		if len(ranges) == 0 {
+			return
+		}
+
+		_, ips, err := utils.SplitTargets(ranges)
+		if err != nil {
+			s.logWarn("IP pivot range error: %v", err)
+			s.reportError(StageIPPivot, err)
+			return
+		}
+		s.logInfo("Pivoting on %d IPs across %d networks", len(ips), len(ranges))
+		stageEnd := s.stageStart(StageIPPivot, 1, len(ips))
+
+		// Names from PTR records and from certificates served on the swept IPs
+		ptrNames := discovery.ReverseDNSLookup(ctx, ips, nil, s.config.Discovery.Threads, s.config.Discovery.Timeout, s.logger)
+		sanDomains, sanOrigins, err := discovery.IPCertificateDiscovery(ctx, ips, s.config.Discovery.probeOptions(), s.logger)
+		if err != nil {
+			s.logWarn("IP pivot certificate discovery error: %v", err)
+			s.reportError(StageIPPivot, err)
+		}
+		newByDepth := s.recordPivotNames(ptrNames, sanDomains, sanOrigins, rangeOwners, matcher, outputDomains)
+
+		found := 0
+		depths := make([]int, 0, len(newByDepth))
+		for depth, domains := range newByDepth {
+			depths = append(depths, depth)
+			found += len(domains)
+		}
+		s.logInfo("IP pivot found %d new domains", found)
+		stageEnd(found)
+		if found == 0 {
+			return
+		}
+		sort.Ints(depths)
+		for _, depth := range depths {
+			if ctx.Err() != nil {
+				return
+			}
+			s.scanFeedbackDomains(ctx, newByDepth[depth], matcher, outputDomains, processedDomains, depth)
+		}
+	}
+}
+
+// pivotRanges returns the networks of resolved IPs, each with the shallowest domain
+// resolving into it (ties broken by name) as the owner used for provenance
+func (s *Scanner) pivotRanges(outputDomains map[string]*DomainEntry) ([]string, map[string]string) {
+	rangeOwners := make(map[string]string)
+	for _, entry := range outputDomains {
+		if entry.IP == "" {
+			continue
+		}
+		cidr, err := utils.ContainingCIDR(entry.IP, s.config.Discovery.IPPivotPrefix)
+		if err != nil {
+			s.logDebug("Skipping IP pivot for %s: %v", entry.IP, err)
+			continue
+		}
+		owner, exists := rangeOwners[cidr]
+		if !exists {
+			rangeOwners[cidr] = entry.Domain
+			continue
+		}
+		ownerDepth, depth := depthOf(owner, outputDomains), depthOf(entry.Domain, outputDomains)
+		if depth < ownerDepth || (depth == ownerDepth && entry.Domain < owner) {
+			rangeOwners[cidr] = entry.Domain
+		}
+	}
+	ranges := make([]string, 0, len(rangeOwners))
+	for cidr := range rangeOwners {
+		ranges = append(ranges, cidr)
+	}
+	sort.Strings(ranges)
+	return ranges, rangeOwners
+}
+
+// recordPivotNames records the keyword-matching names found on swept IPs through PTR records
+// (preferred) or served certificates. The parent of a name is the owner of the network its IP
+// is in, one level deeper. Returns the new domains grouped by discovery depth.
+func (s *Scanner) recordPivotNames(ptrNames map[string]string, sanDomains []string, sanOrigins map[string]discovery.SANOrigin, rangeOwners map[string]string, matcher *utils.Matcher, outputDomains map[string]*DomainEntry) map[int][]string {
+	pivotSource := func(name string, ip string) types.Source {
+		cidr, _ := utils.ContainingCIDR(ip, s.config.Discovery.IPPivotPrefix)
+		parent := rangeOwners[cidr]
+		return newDiscoverySource(name, "reverse-dns", StageIPPivot, parent, seedOf(parent, outputDomains), depthOf(parent, outputDomains)+1)
+	}
+
+	pivotSources := make(map[string]types.Source)
+	for name, ip := range ptrNames {
+		pivotSources[name] = pivotSource("ptr", ip)
+	}
+	for _, name := range sanDomains {
+		if _, exists := pivotSources[name]; !exists {
+			source := pivotSource("ip-certificate", sanOrigins[name].Host)
+			source.Certificate = sanOrigins[name].Certificate
+			pivotSources[name] = source
+		}
+	}
+
+	names := make([]string, 0, len(pivotSources))
+	for name := range pivotSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	newByDepth := make(map[int][]string)
+	for _, name := range names {
+		if !matcher.Matches(name) {
+			continue
+		}
+		source := pivotSources[name]
+		if s.recordDiscovery(name, source, outputDomains) {
+			newByDepth[source.Depth] = append(newByDepth[source.Depth], name)
+		}
+	}
+	return newByDepth
+}
+
+// activeScanWithTracking generates permutations of the discovered domains and optional wordlist
+// guesses, resolves them with wildcard detection and feeds the hits back into discovery.
+func (s *Scanner) activeScanWithTracking(ctx context.Context, matcher *utils.Matcher, outputDomains map[string]*DomainEntry, processedDomains map[string]bool) {
+	if s.config.Discovery.MaxDomains > 0 && len(outputDomains) >= s.config.Discovery.MaxDomains {
+		s.logInfo("Max domains limit reached (%d), skipping active enumeration", s.config.Discovery.MaxDomains)
+		return
+	}
+	if s.config.Discovery.Proxy != "" {
+		s.logWarn("Active enumeration resolves names with the system resolver, bypassing the proxy")
+	}
+
+	var wordlist []string
+	if s.config.Discovery.Wordlist != "" {
+		var err error
+		if wordlist, err = discovery.LoadWordlist(s.config.Discovery.Wordlist); err != nil {
+			s.logError("Active enumeration wordlist error: %v", err)
+			s.reportError(StageActive, err)
+		}
+	}
+	candidateSources := s.activeCandidateSources(outputDomains, wordlist)
+
+	candidates := make([]string, 0, len(candidateSources))
+	for candidate := range candidateSources {
+		if matcher.Matches(candidate) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	candidates = s.filterUnprocessedDomains(candidates, processedDomains, "active")
+	if len(candidates) == 0 {
+		return
+	}
+
+	stageEnd := s.stageStart(StageActive, 1, len(candidates))
+	resolved := discovery.ResolveCandidates(ctx, candidates, nil, s.config.Discovery.Threads, s.config.Discovery.Timeout, s.logger)
+
+	found := 0
+	newByDepth := make(map[int][]string)
+	for _, domain := range resolved {
+		if _, exists := outputDomains[domain]; exists {
+			continue
+		}
+		source := candidateSources[domain]
+		s.recordDiscovery(domain, source, outputDomains)
+		newByDepth[source.Depth] = append(newByDepth[source.Depth], domain)
+		found++
+	}
+
+	s.logInfo("Active enumeration found %d new domains", found)
+	stageEnd(found)
+	depths := make([]int, 0, len(newByDepth))
+	for depth := range newByDepth {
+		depths = append(depths, depth)
+	}
+	sort.Ints(depths)
+	for _, depth := range depths {
+		if ctx.Err() != nil {
+			return
+		}
+		s.scanFeedbackDomains(ctx, newByDepth[depth], matcher, outputDomains, processedDomains, depth)
+	}
+}
+
+// activeCandidateSources maps permutations of the discovered domains and wordlist guesses under
+// their registrable domains to their sources. Candidates are one level deeper than the domain they derive from.
+func (s *Scanner) activeCandidateSources(outputDomains map[string]*DomainEntry, wordlist []string) map[string]types.Source {
+	knownDomains := make([]string, 0, len(outputDomains))
+	for domain := range outputDomains {
+		knownDomains = append(knownDomains, domain)
+	}
+
+	words := s.config.Discovery.PermutationWords
+	if len(words) == 0 {
+		words = discovery.DefaultPermutationWords
+	}
+	candidateSources := make(map[string]types.Source)
+	for candidate, parent := range discovery.GeneratePermutationParents(knownDomains, words) {
+		candidateSources[candidate] = newDiscoverySource("permutation", "permutation", "active", parent, seedOf(parent, outputDomains), depthOf(parent, outputDomains)+1)
+	}
+	for _, candidate := range discovery.GenerateBruteforce(knownDomains, wordlist) {
+		parent := utils.RegistrableDomain(candidate)
+		candidateSources[candidate] = newDiscoverySource("wordlist", "bruteforce", "active", parent, seedOf(parent, outputDomains), depthOf(parent, outputDomains)+1)
+	}
+	return candidateSources
+}
+
 // isSubdomain determines if a domain is a subdomain by counting DNS labels.
 // Domains with more than 2 parts (e.g., sub.example.com) are considered subdomains.
 func (s *Scanner) isSubdomain(domain string) bool {
@@ -344,12 +743,14 @@ func (s *Scanner) filterUnprocessedDomains(domains []string, processedDomains ma
 // httpVerificationOnly performs HTTP verification on domains without certificate discovery.
 // Used when passive discovery is disabled to still verify if domains are live.
 func (s *Scanner) httpVerificationOnly(ctx context.Context, domains []string, outputDomains map[string]*DomainEntry, processedDomains map[string]bool) {
-	s.bulkAnalyzeAndMerge(ctx, domains, []string{}, false, "http", "HTTP verification", outputDomains, processedDomains)
+	stageEnd := s.stageStart(StageHTTP, 0, len(domains))
+	s.bulkAnalyzeAndMerge(ctx, domains, nil, false, "http", "HTTP verification", outputDomains, processedDomains)
+	stageEnd(0)
 }
 
 // bulkAnalyzeAndMerge performs bulk certificate analysis and merges results into outputDomains.
-// Returns the list of newly discovered domains from certificate SANs and their parent certificate info.
-func (s *Scanner) bulkAnalyzeAndMerge(ctx context.Context, domains []string, keywords []string, extractNewDomains bool, processKeyPrefix string, operationName string, outputDomains map[string]*DomainEntry, processedDomains map[string]bool) ([]string, map[string]*types.CertificateInfo) {
+// Returns the list of newly discovered domains from certificate SANs and the host and certificate each came from.
+func (s *Scanner) bulkAnalyzeAndMerge(ctx context.Context, domains []string, matcher *utils.Matcher, extractNewDomains bool, processKeyPrefix string, operationName string, outputDomains map[string]*DomainEntry, processedDomains map[string]bool) ([]string, map[string]discovery.SANOrigin) {
 	// Filter unprocessed domains if not already filtered
 	var targetDomains []string
 	if processKeyPrefix != "cert" {
@@ -373,12 +774,23 @@ func (s *Scanner) bulkAnalyzeAndMerge(ctx context.Context, domains []string, key
 		return []string{}, nil
 	}
 
+	// httpx does not observe ctx, so stop before starting another batch
+	if ctx.Err() != nil {
+		s.logDebug("Skipping %s for %d targets: %v", operationName, len(targetDomains), ctx.Err())
+		return []string{}, nil
+	}
+
 	s.logInfo("Running bulk %s for %d targets", operationName, len(targetDomains))
 	s.logDebug("Bulk targets: %v", targetDomains)
 
-	domainEntries, newDomains, sanCertMap, err := discovery.BulkCertificateAnalysisForScanner(ctx, targetDomains, keywords, extractNewDomains, s.logger)
+	domainEntries, newDomains, sanOrigins, err := discovery.BulkCertificateAnalysisWithProbe(ctx, targetDomains, matcher, extractNewDomains, s.config.Discovery.probeOptions(), s.logger)
 	if err != nil {
 		s.logWarn("Bulk %s error: %v", operationName, err)
+		stage := StageCertificate
+		if processKeyPrefix == "http" {
+			stage = StageHTTP
+		}
+		s.reportError(stage, err)
 		return []string{}, nil
 	}
 
@@ -390,27 +802,50 @@ func (s *Scanner) bulkAnalyzeAndMerge(ctx context.Context, domains []string, key
 	}
 	s.mergeDomainEntries(domainEntries, outputDomains, logPrefix)
 
-	return newDomains, sanCertMap
+	return newDomains, sanOrigins
 }
 
 // mergeDomainEntries merges domain entries into outputDomains and updates progress
 func (s *Scanner) mergeDomainEntries(domainEntries []*DomainEntry, outputDomains map[string]*DomainEntry, logPrefix string) {
+	s.mergeEntries(domainEntries, outputDomains, logPrefix, true)
+}
+
+// mergeProbeResults copies the probed fields of re-probed domains onto their entries in
+// outputDomains, leaving sources untouched. Probe results carry placeholder discovery
+// sources that would otherwise rewrite the provenance of known domains.
+func (s *Scanner) mergeProbeResults(domainEntries []*DomainEntry, outputDomains map[string]*DomainEntry, logPrefix string) {
+	s.mergeEntries(domainEntries, outputDomains, logPrefix, false)
+}
+
+// mergeEntries merges domain entries into outputDomains and updates progress. Without
+// withSources, only the probed fields of known domains are updated.
+func (s *Scanner) mergeEntries(domainEntries []*DomainEntry, outputDomains map[string]*DomainEntry, logPrefix string, withSources bool) {
 	liveDomainCount := s.countLiveDomainsFromMap(outputDomains)
+	extended := s.extendedProgress()
 	for _, domainEntry := range domainEntries {
 		// Merge with existing entry if present
-		if existing, exists := outputDomains[domainEntry.Domain]; exists {
+		merged := domainEntry
+		existing, exists := outputDomains[domainEntry.Domain]
+		switch {
+		case exists:
+			merged = existing
 			existing.Status = domainEntry.Status
 			existing.Reachable = domainEntry.Reachable
 			existing.URL = domainEntry.URL
 			existing.IP = domainEntry.IP
+			existing.CNAMEs = domainEntry.CNAMEs
 			existing.Redirect = domainEntry.Redirect
 			existing.Certificate = domainEntry.Certificate
-			// Merge sources
-			for _, src := range domainEntry.Sources {
-				addSource(existing, src.Name, src.Type)
+			existing.ProbedAt = domainEntry.ProbedAt
+			if withSources {
+				for _, src := range domainEntry.Sources {
+					addDiscoverySource(existing, src)
+				}
 			}
-		} else {
+		case withSources:
 			outputDomains[domainEntry.Domain] = domainEntry
+		default:
+			continue
 		}
 
 		s.logInfo("%s domain %s (reachable: %t, status: %d)", logPrefix, domainEntry.Domain, domainEntry.Reachable, domainEntry.Status)
@@ -419,44 +854,100 @@ func (s *Scanner) mergeDomainEntries(domainEntries []*DomainEntry, outputDomains
 			liveDomainCount++
 		}
 
+		if extended != nil {
+			extended.OnDomainVerified(merged)
+		}
 		if s.progress != nil {
 			s.progress.OnProgress(len(outputDomains), liveDomainCount)
 		}
 	}
 }
 
-// addSource adds a source to a domain entry, avoiding duplicates
-func addSource(entry *DomainEntry, name string, sourceType string) {
-	// Check if source already exists
-	for _, src := range entry.Sources {
-		if src.Name == name && src.Type == sourceType {
+// newDiscoverySource builds a source carrying provenance: the parent whose scan produced
+// the domain, the seed its chain started from, the recursion depth and the discovery stage
+func newDiscoverySource(name string, sourceType string, stage string, parent string, seed string, depth int) types.Source {
+	return types.Source{
+		Name:         name,
+		Type:         sourceType,
+		Parent:       parent,
+		Seed:         seed,
+		Depth:        depth,
+		Stage:        stage,
+		DiscoveredAt: time.Now(),
+	}
+}
+
+// addDiscoverySource adds a provenance source to a domain entry. A source with the same name,
+// type, parent and certificate is only recorded once, keeping the earliest discovery. Scans
+// and MergeResults both deduplicate through it.
+func addDiscoverySource(entry *DomainEntry, source types.Source) {
+	for i, src := range entry.Sources {
+		if sameSource(src, source) {
+			if !source.DiscoveredAt.IsZero() && (src.DiscoveredAt.IsZero() || source.DiscoveredAt.Before(src.DiscoveredAt)) {
+				entry.Sources[i] = source
+			}
 			return
 		}
 	}
-	// Add new source
-	entry.Sources = append(entry.Sources, types.Source{
-		Name: name,
-		Type: sourceType,
-	})
+	entry.Sources = append(entry.Sources, source)
 }
 
-// addSourceWithCert adds a source with certificate info to a domain entry, avoiding duplicates
-func addSourceWithCert(entry *DomainEntry, name string, sourceType string, cert *types.CertificateInfo) {
-	// Check if source with same name, type and certificate already exists
-	for _, src := range entry.Sources {
-		if src.Name == name && src.Type == sourceType && src.Certificate != nil && cert != nil {
-			// Compare certificate details to check if it's the same
-			if src.Certificate.Subject == cert.Subject &&
-				src.Certificate.Issuer == cert.Issuer &&
-				src.Certificate.IssuedOn.Equal(cert.IssuedOn) {
-				return // Already exists
+// sameSource reports whether two sources record the same discovery: the same name, type,
+// parent and certificate
+func sameSource(a types.Source, b types.Source) bool {
+	return a.Name == b.Name && a.Type == b.Type && a.Parent == b.Parent && sameCertificate(a.Certificate, b.Certificate)
+}
+
+// sameCertificate reports whether two certificate infos describe the same certificate
+func sameCertificate(a *types.CertificateInfo, b *types.CertificateInfo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Subject == b.Subject && a.Issuer == b.Issuer && a.IssuedOn.Equal(b.IssuedOn)
+}
+
+// seedOf returns the seed the discovery chain of domain started from, or domain itself when
+// it has no recorded provenance (e.g. an IP seed)
+func seedOf(domain string, outputDomains map[string]*DomainEntry) string {
+	if entry, exists := outputDomains[domain]; exists {
+		for _, src := range entry.Sources {
+			if src.Seed != "" {
+				return src.Seed
 			}
 		}
 	}
-	// Add new source
-	entry.Sources = append(entry.Sources, types.Source{
-		Name:        name,
-		Type:        sourceType,
-		Certificate: cert,
-	})
+	return domain
+}
+
+// depthOf returns the depth at which domain was first reached: the lowest depth of its
+// discovery sources, 0 for seeds and domains without provenance
+func depthOf(domain string, outputDomains map[string]*DomainEntry) int {
+	entry, exists := outputDomains[domain]
+	if !exists {
+		return 0
+	}
+	depth := -1
+	for _, src := range entry.Sources {
+		if src.Type == "seed" {
+			return 0
+		}
+		if src.Stage != "" && (depth < 0 || src.Depth < depth) {
+			depth = src.Depth
+		}
+	}
+	if depth < 0 {
+		return 0
+	}
+	return depth
+}
+
+// enumeratedParent returns the most specific enumerated domain that subdomain belongs to
+func enumeratedParent(subdomain string, enumerated []string) string {
+	parent := ""
+	for _, domain := range enumerated {
+		if (subdomain == domain || strings.HasSuffix(subdomain, "."+domain)) && len(domain) > len(parent) {
+			parent = domain
+		}
+	}
+	return parent
 }
@@ -2,8 +2,14 @@ package domainscan
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/valllabh/domain-scan/pkg/discovery"
+	"github.com/valllabh/domain-scan/pkg/types"
+	"github.com/valllabh/domain-scan/pkg/utils"
 )
 
 func TestNew(t *testing.T) {
@@ -49,19 +55,6 @@ func TestNew(t *testing.T) {
 	}
 }
 
-func TestScannerSetLogger(t *testing.T) {
-	scanner := New(nil)
-
-	// Create a mock logger
-	mockLogger := &testLogger{}
-	scanner.SetLogger(mockLogger)
-
-	// Test that the logger was set
-	if scanner.logger != mockLogger {
-		t.Error("SetLogger() did not set the logger correctly")
-	}
-}
-
 func TestScannerGetConfig(t *testing.T) {
 	config := DefaultConfig()
 	scanner := New(config)
@@ -119,6 +112,29 @@ func TestScanWithOptionsValidation(t *testing.T) {
 	}
 }
 
+func TestScanWithOptionsCancelled(t *testing.T) {
+	config := DefaultConfig()
+	config.Discovery.EnableIPPivot = true
+	config.Discovery.EnableActive = true
+	config.Discovery.EnableContent = true
+	scanner := New(config)
+
+	// A cancelled scan starts no stage and returns the seeds with the context error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	started := time.Now()
+	result, err := scanner.ScanWithOptions(ctx, DefaultScanRequest([]string{"example.com", "192.0.2.1"}))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ScanWithOptions() error = %v, expected context.Canceled", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Cancelled scan took %s, expected it to return immediately", elapsed)
+	}
+	if result == nil || len(result.Domains) != 1 || result.Domains["example.com"] == nil {
+		t.Errorf("Expected the partial result to hold only the seed, got %+v", result)
+	}
+}
+
 func TestDefaultScanRequest(t *testing.T) {
 	domains := []string{"example.com", "test.com"}
 	req := DefaultScanRequest(domains)
@@ -143,15 +159,114 @@ func TestDefaultScanRequest(t *testing.T) {
 	}
 }
 
-// testLogger is a mock logger for testing
-type testLogger struct {
-	messages []string
+func TestRecordIPSeeds(t *testing.T) {
+	config := DefaultConfig()
+	config.LogLevel = "silent"
+	scanner := New(config)
+
+	cert := &types.CertificateInfo{Subject: "www.example.com"}
+	outputDomains := map[string]*DomainEntry{}
+	scanner.recordIPSeeds([]string{"www.example.com", "api.example.com"}, map[string]discovery.SANOrigin{
+		"www.example.com": {Host: "192.0.2.10", Certificate: cert},
+		"api.example.com": {Host: "192.0.2.10", Certificate: cert},
+	}, outputDomains)
+
+	if len(outputDomains) != 2 {
+		t.Fatalf("Expected 2 seeded domains, got %v", outputDomains)
+	}
+	for domain, entry := range outputDomains {
+		if len(entry.Sources) != 1 {
+			t.Fatalf("Expected %s to have one source, got %+v", domain, entry.Sources)
+		}
+		source := entry.Sources[0]
+		if source.Name != "ip-certificate" || source.Type != "certificate" || source.Stage != "seed" ||
+			source.Parent != "192.0.2.10" || source.Seed != "192.0.2.10" || source.Depth != 0 || source.Certificate != cert {
+			t.Errorf("Unexpected IP seed source for %s: %+v", domain, source)
+		}
+	}
+}
+
+// newTestMatcher parses keywords, failing the test if any is invalid
+func newTestMatcher(t *testing.T, keywords ...string) *utils.Matcher {
+	t.Helper()
+	matcher, err := utils.NewMatcher(keywords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matcher
 }
 
-func (l *testLogger) Printf(format string, v ...interface{}) {
-	l.messages = append(l.messages, format)
+func TestIPPivotRecording(t *testing.T) {
+	config := DefaultConfig()
+	config.LogLevel = "silent"
+	scanner := New(config)
+
+	outputDomains := map[string]*DomainEntry{
+		"example.com": {Domain: "example.com", IP: "192.0.2.10",
+			Sources: []types.Source{{Name: "input", Type: "seed", Seed: "example.com", Stage: "seed"}}},
+		"api.example.com": {Domain: "api.example.com", IP: "192.0.2.20",
+			Sources: []types.Source{{Name: "subfinder", Type: "passive", Stage: "passive", Parent: "example.com", Seed: "example.com", Depth: 1}}},
+		"deep.example.net": {Domain: "deep.example.net", IP: "198.51.100.5",
+			Sources: []types.Source{{Name: "certificate-san", Type: "certificate", Stage: "certificate", Parent: "api.example.com", Seed: "example.com", Depth: 2}}},
+	}
+
+	ranges, owners := scanner.pivotRanges(outputDomains)
+	if !reflect.DeepEqual(ranges, []string{"192.0.2.0/24", "198.51.100.0/24"}) {
+		t.Fatalf("Unexpected pivot ranges %v", ranges)
+	}
+	// The seed is shallower than api.example.com in the same network
+	if owners["192.0.2.0/24"] != "example.com" || owners["198.51.100.0/24"] != "deep.example.net" {
+		t.Errorf("Unexpected range owners %v", owners)
+	}
+
+	cert := &types.CertificateInfo{Subject: "vpn.example.net"}
+	newByDepth := scanner.recordPivotNames(
+		map[string]string{"mail.example.com": "192.0.2.44", "unrelated.org": "192.0.2.45", "api.example.com": "192.0.2.20"},
+		[]string{"vpn.example.net", "mail.example.com"},
+		map[string]discovery.SANOrigin{"vpn.example.net": {Host: "198.51.100.6", Certificate: cert}, "mail.example.com": {Host: "192.0.2.44"}},
+		owners, newTestMatcher(t, "example"), outputDomains)
+
+	expected := map[int][]string{1: {"mail.example.com"}, 3: {"vpn.example.net"}}
+	if !reflect.DeepEqual(newByDepth, expected) {
+		t.Errorf("Expected new domains by depth %v, got %v", expected, newByDepth)
+	}
+	if _, exists := outputDomains["unrelated.org"]; exists {
+		t.Error("Expected names not matching keywords to be skipped")
+	}
+
+	mail := outputDomains["mail.example.com"].Sources
+	if len(mail) != 1 || mail[0].Name != "ptr" || mail[0].Type != "reverse-dns" || mail[0].Stage != StageIPPivot ||
+		mail[0].Parent != "example.com" || mail[0].Seed != "example.com" || mail[0].Depth != 1 {
+		t.Errorf("Unexpected PTR pivot source: %+v", mail)
+	}
+	vpn := outputDomains["vpn.example.net"].Sources
+	if len(vpn) != 1 || vpn[0].Name != "ip-certificate" || vpn[0].Parent != "deep.example.net" || vpn[0].Depth != 3 || vpn[0].Certificate != cert {
+		t.Errorf("Unexpected certificate pivot source: %+v", vpn)
+	}
+	// Known domains gain the pivot as an additional source without being fed back
+	if api := outputDomains["api.example.com"].Sources; len(api) != 2 || api[1].Name != "ptr" || api[1].Depth != 1 {
+		t.Errorf("Expected api.example.com to gain a PTR source, got %+v", api)
+	}
 }
 
-func (l *testLogger) Println(v ...interface{}) {
-	l.messages = append(l.messages, "println")
+func TestActiveCandidateSourcesDepth(t *testing.T) {
+	config := DefaultConfig()
+	config.LogLevel = "silent"
+	config.Discovery.PermutationWords = []string{"dev"}
+	scanner := New(config)
+	outputDomains := map[string]*DomainEntry{
+		"example.com": {Domain: "example.com",
+			Sources: []types.Source{{Name: "input", Type: "seed", Seed: "example.com", Stage: "seed"}}},
+		"api.internal.example.com": {Domain: "api.internal.example.com",
+			Sources: []types.Source{{Name: "certificate-san", Type: "certificate", Stage: "certificate", Parent: "internal.example.com", Seed: "example.com", Depth: 3}}},
+	}
+
+	sources := scanner.activeCandidateSources(outputDomains, []string{"mail"})
+	// Permutations sit one level below the domain they were derived from
+	if permutation := sources["dev-api.internal.example.com"]; permutation.Parent != "api.internal.example.com" || permutation.Seed != "example.com" || permutation.Depth != 4 {
+		t.Errorf("Unexpected permutation source: %+v", permutation)
+	}
+	if guess := sources["mail.example.com"]; guess.Name != "wordlist" || guess.Parent != "example.com" || guess.Depth != 1 {
+		t.Errorf("Unexpected wordlist source: %+v", guess)
+	}
 }
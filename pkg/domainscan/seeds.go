@@ -0,0 +1,97 @@
+package domainscan
+
+import (
+	"net/netip"
+	"sort"
+)
+
+// Unattributed groups entries without a recorded seed, such as results saved by older versions
+const Unattributed = "unattributed"
+
+// TagSeeds sets the Seeds of every entry from the seeds recorded in its sources. Seeds are
+// mapped back to the scan targets they came from, so a domain found on an IP of a CIDR
+// target is tagged with the CIDR. With no targets, recorded seeds are used as they are.
+func (r *AssetDiscoveryResult) TagSeeds(targets []string) {
+	prefixes := make(map[string]netip.Prefix)
+	for _, target := range targets {
+		if prefix, err := netip.ParsePrefix(target); err == nil {
+			prefixes[target] = prefix.Masked()
+		}
+	}
+
+	for _, entry := range r.Domains {
+		seen := make(map[string]bool)
+		var seeds []string
+		for _, src := range entry.Sources {
+			if src.Seed == "" {
+				continue
+			}
+			seed := targetOf(src.Seed, prefixes)
+			if !seen[seed] {
+				seen[seed] = true
+				seeds = append(seeds, seed)
+			}
+		}
+		sort.Strings(seeds)
+		entry.Seeds = seeds
+	}
+}
+
+// targetOf returns the CIDR target containing an IP seed, or the seed itself. The most
+// specific prefix wins when CIDR targets overlap.
+func targetOf(seed string, prefixes map[string]netip.Prefix) string {
+	addr, err := netip.ParseAddr(seed)
+	if err != nil {
+		return seed
+	}
+	target, bits := seed, -1
+	for cidr, prefix := range prefixes {
+		if prefix.Contains(addr) && (prefix.Bits() > bits || (prefix.Bits() == bits && cidr < target)) {
+			target, bits = cidr, prefix.Bits()
+		}
+	}
+	return target
+}
+
+// BySeed splits the result into one result per seed, keyed by seed. Entries reached from
+// several seeds appear in each of their groups; entries without seeds are grouped under
+// Unattributed. Statistics are recomputed per group.
+func (r *AssetDiscoveryResult) BySeed() map[string]*AssetDiscoveryResult {
+	groups := make(map[string]*AssetDiscoveryResult)
+	add := func(seed string, entry *DomainEntry) {
+		group, exists := groups[seed]
+		if !exists {
+			group = &AssetDiscoveryResult{
+				Domains:    make(map[string]*DomainEntry),
+				Statistics: DiscoveryStats{},
+				Errors:     []error{},
+			}
+			groups[seed] = group
+		}
+		group.Domains[entry.Domain] = entry
+	}
+
+	for _, entry := range r.Domains {
+		if len(entry.Seeds) == 0 {
+			add(Unattributed, entry)
+			continue
+		}
+		for _, seed := range entry.Seeds {
+			add(seed, entry)
+		}
+	}
+	for _, group := range groups {
+		group.UpdateStatistics()
+	}
+	return groups
+}
+
+// hasSeeds reports whether any entry is tagged with seeds
+func hasSeeds(r *AssetDiscoveryResult) bool {
+	for _, entry := range r.Domains {
+		if len(entry.Seeds) > 0 {
+			return true
+		}
+	}
+	return false
+}
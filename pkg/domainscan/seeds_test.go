@@ -0,0 +1,85 @@
+package domainscan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+func TestTagSeedsAndBySeed(t *testing.T) {
+	result := &AssetDiscoveryResult{Domains: map[string]*DomainEntry{
+		"a.com": {Domain: "a.com", Reachable: true, Sources: []types.Source{{Name: "input", Type: "seed", Seed: "a.com"}}},
+		"b.com": {Domain: "b.com", Sources: []types.Source{{Name: "input", Type: "seed", Seed: "b.com"}}},
+		"shared.a.com": {Domain: "shared.a.com", Reachable: true, Sources: []types.Source{
+			{Name: "subfinder", Type: "passive", Parent: "a.com", Seed: "a.com"},
+			{Name: "certificate-san", Type: "certificate", Parent: "b.com", Seed: "b.com"},
+			{Name: "httpx", Type: "http"},
+		}},
+		"net.example.com":  {Domain: "net.example.com", Sources: []types.Source{{Name: "ip-certificate", Type: "certificate", Parent: "192.0.2.7", Seed: "192.0.2.7"}}},
+		"host.example.com": {Domain: "host.example.com", Sources: []types.Source{{Name: "ip-certificate", Type: "certificate", Parent: "198.51.100.1", Seed: "198.51.100.1"}}},
+		"legacy.a.com":     {Domain: "legacy.a.com", Sources: []types.Source{{Name: "subfinder", Type: "passive"}}},
+	}}
+
+	result.TagSeeds([]string{"a.com", "b.com", "192.0.2.0/16", "192.0.2.0/24", "198.51.100.1"})
+
+	expected := map[string]string{
+		"a.com":            "a.com",
+		"b.com":            "b.com",
+		"shared.a.com":     "a.com,b.com",
+		"net.example.com":  "192.0.2.0/24",
+		"host.example.com": "198.51.100.1",
+		"legacy.a.com":     "",
+	}
+	for domain, seeds := range expected {
+		if got := strings.Join(result.Domains[domain].Seeds, ","); got != seeds {
+			t.Errorf("%s: expected seeds %q, got %q", domain, seeds, got)
+		}
+	}
+
+	groups := result.BySeed()
+	counts := map[string]int{"a.com": 2, "b.com": 2, "192.0.2.0/24": 1, "198.51.100.1": 1, Unattributed: 1}
+	if len(groups) != len(counts) {
+		t.Fatalf("Expected %d groups, got %d", len(counts), len(groups))
+	}
+	for seed, count := range counts {
+		group := groups[seed]
+		if group == nil {
+			t.Errorf("Missing group %s", seed)
+			continue
+		}
+		if group.Statistics.TotalSubdomains != count {
+			t.Errorf("%s: expected %d domains, got %d", seed, count, group.Statistics.TotalSubdomains)
+		}
+	}
+	if groups["a.com"].Statistics.ActiveServices != 2 || groups["b.com"].Statistics.ActiveServices != 1 {
+		t.Errorf("Expected per-group live counts, got %+v and %+v", groups["a.com"].Statistics, groups["b.com"].Statistics)
+	}
+
+	// Without targets, recorded seeds are used as they are
+	result.TagSeeds(nil)
+	if got := strings.Join(result.Domains["net.example.com"].Seeds, ","); got != "192.0.2.7" {
+		t.Errorf("Expected the recorded IP seed without targets, got %q", got)
+	}
+}
+
+func TestReadResultTagsSeeds(t *testing.T) {
+	saved := `{"domains": {"api.example.com": {"domain": "api.example.com", "sources": [{"name": "subfinder", "type": "passive", "seed": "example.com"}]}}}`
+	result, err := ReadResult(strings.NewReader(saved))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Domains["api.example.com"].Seeds; len(got) != 1 || got[0] != "example.com" {
+		t.Errorf("Expected seeds derived from sources, got %v", got)
+	}
+
+	// Saved seeds are kept, so CIDR attribution survives a round trip
+	saved = `{"domains": {"net.example.com": {"domain": "net.example.com", "seeds": ["192.0.2.0/24"], "sources": [{"name": "ip-certificate", "type": "certificate", "seed": "192.0.2.7"}]}}}`
+	result, err = ReadResult(strings.NewReader(saved))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Domains["net.example.com"].Seeds; len(got) != 1 || got[0] != "192.0.2.0/24" {
+		t.Errorf("Expected saved seeds to be kept, got %v", got)
+	}
+}
@@ -0,0 +1,395 @@
+package domainscan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// ANSI escape sequences used by the terminal renderer
+const (
+	ansiClearLine   = "\r\033[K"
+	ansiClearScreen = "\033[H\033[2J"
+	ansiAltScreen   = "\033[?1049h"
+	ansiMainScreen  = "\033[?1049l"
+	ansiHideCursor  = "\033[?25l"
+	ansiShowCursor  = "\033[?25h"
+	ansiBold        = "\033[1m"
+	ansiDim         = "\033[2m"
+	ansiGreen       = "\033[32m"
+	ansiYellow      = "\033[33m"
+	ansiRed         = "\033[31m"
+	ansiCyan        = "\033[36m"
+	ansiReset       = "\033[0m"
+)
+
+// terminalRefresh is how often the status bar is redrawn while nothing else happens
+const terminalRefresh = 200 * time.Millisecond
+
+// terminalMinRedraw limits redraws triggered by bursts of events; the periodic refresh catches up
+const terminalMinRedraw = 50 * time.Millisecond
+
+// spinnerFrames animate the status bar so long stages visibly make progress
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// TerminalProgressOptions configures TerminalProgressHandler
+type TerminalProgressOptions struct {
+	// FullScreen switches to the terminal's alternate screen and lists live hosts as they're verified
+	FullScreen bool
+	// Size returns the terminal width and height; 80x24 is assumed when nil or failing
+	Size func() (width, height int, err error)
+}
+
+// TerminalProgressHandler implements ExtendedProgressCallback for interactive terminals.
+// Instead of printing a line per update it redraws a single status bar showing the
+// current stage, recursion depth, discovery rate and an ETA for the running stage.
+// In full-screen mode live hosts are listed below the status bar as they're verified.
+// Output must be a terminal understanding ANSI escape sequences; use CLIProgressHandler otherwise.
+type TerminalProgressHandler struct {
+	mu      sync.Mutex
+	out     io.Writer
+	options TerminalProgressOptions
+
+	startTime    time.Time
+	stage        StageInfo
+	stageStarted time.Time
+	active       bool // A stage is running
+	depth        int
+	totalDomains int
+	liveDomains  int
+	errors       int
+	lastError    string
+	frame        int
+	lastDraw     time.Time
+
+	// Targets processed per second by completed batches of each stage, for ETAs
+	stageTargets  map[string]int
+	stageDuration map[string]time.Duration
+
+	live     []*DomainEntry
+	liveSeen map[string]bool
+
+	stop   chan struct{}
+	closed bool
+}
+
+// NewTerminalProgressHandler creates a terminal progress renderer writing to out
+func NewTerminalProgressHandler(out io.Writer, options TerminalProgressOptions) *TerminalProgressHandler {
+	return &TerminalProgressHandler{
+		out:           out,
+		options:       options,
+		startTime:     time.Now(),
+		stageTargets:  make(map[string]int),
+		stageDuration: make(map[string]time.Duration),
+		liveSeen:      make(map[string]bool),
+	}
+}
+
+// OnStart is called when domain asset discovery begins
+func (t *TerminalProgressHandler) OnStart(domains []string, keywords []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.startTime = time.Now()
+
+	if t.options.FullScreen {
+		fmt.Fprint(t.out, ansiAltScreen+ansiHideCursor)
+	} else {
+		fmt.Fprintf(t.out, "🔍 Starting domain discovery for %d domains\n", len(domains))
+		if len(keywords) > 0 {
+			fmt.Fprintf(t.out, "🔑 Using keywords: %s\n", strings.Join(keywords, ", "))
+		}
+		fmt.Fprintf(t.out, "\n")
+	}
+	t.drawLocked()
+
+	if t.stop == nil {
+		t.stop = make(chan struct{})
+		go t.refresh(t.stop)
+	}
+}
+
+// OnProgress is called with unified progress updates
+func (t *TerminalProgressHandler) OnProgress(totalDomains, liveDomains int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.totalDomains = totalDomains
+	t.liveDomains = liveDomains
+	t.drawLocked()
+}
+
+// OnEnd restores the terminal and prints the final summary
+func (t *TerminalProgressHandler) OnEnd(result *AssetDiscoveryResult) {
+	t.Close()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	duration := time.Since(t.startTime)
+	fmt.Fprintf(t.out, "✅ Discovery completed in %v\n", duration)
+	if result != nil {
+		fmt.Fprintf(t.out, "📊 Results: %d domains, %d live services\n\n",
+			result.Statistics.TotalSubdomains, result.Statistics.ActiveServices)
+	}
+}
+
+// OnStageStart is called when a batch of a discovery stage begins
+func (t *TerminalProgressHandler) OnStageStart(stage StageInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stage = stage
+	t.stageStarted = time.Now()
+	t.active = true
+	t.drawLocked()
+}
+
+// OnStageEnd records the batch throughput used to estimate later batches of the stage
+func (t *TerminalProgressHandler) OnStageEnd(stage StageInfo, found int, elapsed time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stageTargets[stage.Stage] += stage.Targets
+	t.stageDuration[stage.Stage] += elapsed
+	t.active = false
+	t.drawLocked()
+}
+
+// OnDomainDiscovered is called when a domain is first added to the results
+func (t *TerminalProgressHandler) OnDomainDiscovered(domain string, source types.Source) {}
+
+// OnDomainVerified adds reachable hosts to the live host list
+func (t *TerminalProgressHandler) OnDomainVerified(entry *DomainEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !entry.Reachable || t.liveSeen[entry.Domain] {
+		return
+	}
+	t.liveSeen[entry.Domain] = true
+	snapshot := *entry
+	t.live = append(t.live, &snapshot)
+	t.drawLocked()
+}
+
+// OnError counts non-fatal errors and keeps the latest for display
+func (t *TerminalProgressHandler) OnError(stage string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errors++
+	t.lastError = fmt.Sprintf("%s: %v", stage, err)
+	t.drawLocked()
+}
+
+// OnDepthChange is called when recursion reaches a new depth for the first time
+func (t *TerminalProgressHandler) OnDepthChange(depth int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.depth = depth
+	t.drawLocked()
+}
+
+// Print runs write with the status bar cleared and redraws it afterwards, so other output
+// sharing the terminal (such as log lines on stderr) doesn't collide with the bar
+func (t *TerminalProgressHandler) Print(write func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.closed && !t.options.FullScreen {
+		fmt.Fprint(t.out, ansiClearLine)
+	}
+	write()
+	t.lastDraw = time.Time{}
+	t.drawLocked()
+}
+
+// Close stops redrawing and restores the terminal. It is safe to call more than once,
+// and should be called when a scan is interrupted before OnEnd.
+func (t *TerminalProgressHandler) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	if t.stop != nil {
+		close(t.stop)
+	}
+	if t.options.FullScreen {
+		fmt.Fprint(t.out, ansiShowCursor+ansiMainScreen)
+	} else {
+		fmt.Fprint(t.out, ansiClearLine)
+	}
+}
+
+// refresh redraws periodically so the spinner, elapsed time and ETA keep moving
+func (t *TerminalProgressHandler) refresh(stop chan struct{}) {
+	ticker := time.NewTicker(terminalRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			t.mu.Lock()
+			t.frame++
+			t.lastDraw = time.Time{}
+			t.drawLocked()
+			t.mu.Unlock()
+		}
+	}
+}
+
+// drawLocked renders the current state; the caller must hold t.mu
+func (t *TerminalProgressHandler) drawLocked() {
+	if t.closed || time.Since(t.lastDraw) < terminalMinRedraw {
+		return
+	}
+	t.lastDraw = time.Now()
+	width, height := t.size()
+	if t.options.FullScreen {
+		fmt.Fprint(t.out, t.screen(width, height))
+		return
+	}
+	fmt.Fprint(t.out, ansiClearLine+t.statusLine(width))
+}
+
+// size returns the terminal dimensions, falling back to 80x24
+func (t *TerminalProgressHandler) size() (int, int) {
+	if t.options.Size != nil {
+		if width, height, err := t.options.Size(); err == nil && width > 0 && height > 0 {
+			return width, height
+		}
+	}
+	return 80, 24
+}
+
+// statusLine formats the single-line status bar, truncated to width
+func (t *TerminalProgressHandler) statusLine(width int) string {
+	elapsed := time.Since(t.startTime)
+
+	stage := t.stage.Stage
+	if stage == "" {
+		stage = "starting"
+	}
+	parts := []string{
+		fmt.Sprintf("%s %s", spinnerFrames[t.frame%len(spinnerFrames)], stage),
+		fmt.Sprintf("depth %d", t.depth),
+		fmt.Sprintf("%d domains", t.totalDomains),
+		fmt.Sprintf("%d live", t.liveDomains),
+		fmt.Sprintf("%.1f/s", rate(t.totalDomains, elapsed)),
+		"ETA " + formatETA(t.eta()),
+		formatElapsed(elapsed),
+	}
+	if t.errors > 0 {
+		parts = append(parts, fmt.Sprintf("%d errors", t.errors))
+	}
+	plain := truncate(strings.Join(parts, " │ "), width-1)
+
+	// Colour the stage and live count without affecting the truncation width
+	colored := strings.Replace(plain, stage, ansiCyan+stage+ansiReset, 1)
+	live := fmt.Sprintf("%d live", t.liveDomains)
+	return strings.Replace(colored, live, ansiGreen+live+ansiReset, 1)
+}
+
+// screen formats the full-screen view: a header, the status bar and the most recent live hosts
+func (t *TerminalProgressHandler) screen(width, height int) string {
+	var b strings.Builder
+	b.WriteString(ansiClearScreen)
+	b.WriteString(ansiBold + "domain-scan" + ansiReset + "\r\n")
+	b.WriteString(t.statusLine(width) + "\r\n")
+	if t.lastError != "" {
+		b.WriteString(ansiRed + truncate("last error: "+t.lastError, width-1) + ansiReset)
+	}
+	b.WriteString("\r\n\r\n")
+	b.WriteString(ansiBold + truncate(fmt.Sprintf("%-6s %-16s %s", "STATUS", "IP", "URL"), width-1) + ansiReset + "\r\n")
+
+	// Keep the newest hosts visible when there are more than fit on screen
+	rows := height - 6
+	if rows < 1 {
+		rows = 1
+	}
+	hosts := t.live
+	if len(hosts) > rows {
+		hosts = hosts[len(hosts)-rows:]
+	}
+	for _, entry := range hosts {
+		url := entry.URL
+		if url == "" {
+			url = entry.Domain
+		}
+		line := truncate(fmt.Sprintf("%-6d %-16s %s", entry.Status, entry.IP, url), width-1)
+		b.WriteString(statusColor(entry.Status) + line + ansiReset + "\r\n")
+	}
+	if hidden := len(t.live) - len(hosts); hidden > 0 {
+		b.WriteString(ansiDim + fmt.Sprintf("… %d more", hidden) + ansiReset)
+	}
+	return b.String()
+}
+
+// eta estimates the remaining time of the running stage batch from the throughput
+// of earlier batches of the same stage. It returns -1 when no estimate is possible.
+func (t *TerminalProgressHandler) eta() time.Duration {
+	if !t.active || t.stage.Targets == 0 {
+		return -1
+	}
+	done, spent := t.stageTargets[t.stage.Stage], t.stageDuration[t.stage.Stage]
+	if done == 0 || spent <= 0 {
+		return -1
+	}
+	expected := time.Duration(float64(spent) / float64(done) * float64(t.stage.Targets))
+	remaining := expected - time.Since(t.stageStarted)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// statusColor colours a live host row by HTTP status class
+func statusColor(status int) string {
+	switch {
+	case status >= 500:
+		return ansiRed
+	case status >= 400:
+		return ansiYellow
+	case status >= 300:
+		return ansiCyan
+	default:
+		return ansiGreen
+	}
+}
+
+// rate returns count per second over elapsed
+func rate(count int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(count) / elapsed.Seconds()
+}
+
+// formatETA formats an ETA, using "--" when unknown
+func formatETA(d time.Duration) string {
+	if d < 0 {
+		return "--"
+	}
+	return formatElapsed(d)
+}
+
+// formatElapsed formats a duration as m:ss, or h:mm:ss past an hour
+func formatElapsed(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// truncate shortens s to at most width runes, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
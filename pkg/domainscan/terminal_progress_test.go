@@ -0,0 +1,122 @@
+package domainscan
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTerminalProgressStatusBar(t *testing.T) {
+	var out bytes.Buffer
+	h := NewTerminalProgressHandler(&out, TerminalProgressOptions{})
+	var _ ExtendedProgressCallback = h
+
+	h.OnStart([]string{"example.com"}, []string{"example"})
+	h.OnDepthChange(1)
+	h.OnStageStart(StageInfo{Stage: StageCertificate, Depth: 1, Targets: 4})
+	h.OnProgress(12, 3)
+	h.OnError(StageCertificate, errors.New("handshake timeout"))
+
+	h.mu.Lock()
+	line := h.statusLine(200)
+	narrow := h.statusLine(20)
+	h.mu.Unlock()
+	for _, want := range []string{StageCertificate, "depth 1", "12 domains", "3 live", "ETA --", "1 errors"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Status bar %q missing %q", line, want)
+		}
+	}
+	if len([]rune(narrow)) > 19+2*len(ansiReset+ansiCyan) {
+		t.Errorf("Status bar not truncated to width: %q", narrow)
+	}
+
+	if strings.Count(out.String(), "🔍 Starting domain discovery") != 1 {
+		t.Errorf("Expected a single start banner, got %q", out.String())
+	}
+
+	// Other output is printed with the bar cleared, then the bar is redrawn
+	out.Reset()
+	h.Print(func() { out.WriteString("[INF] log line\n") })
+	if !strings.HasPrefix(out.String(), ansiClearLine+"[INF] log line\n"+ansiClearLine) {
+		t.Errorf("Expected log line between bar redraws, got %q", out.String())
+	}
+
+	h.OnEnd(&AssetDiscoveryResult{Statistics: DiscoveryStats{TotalSubdomains: 12, ActiveServices: 3}})
+	h.OnEnd(&AssetDiscoveryResult{}) // Closing twice must not panic
+	if !strings.Contains(out.String(), ansiClearLine+"✅ Discovery completed") {
+		t.Errorf("Expected status bar to be cleared before the summary, got %q", out.String())
+	}
+}
+
+func TestTerminalProgressFullScreen(t *testing.T) {
+	var out bytes.Buffer
+	h := NewTerminalProgressHandler(&out, TerminalProgressOptions{
+		FullScreen: true,
+		Size:       func() (int, int, error) { return 100, 8, nil },
+	})
+	h.OnStart([]string{"example.com"}, nil)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		h.OnDomainVerified(&DomainEntry{Domain: name + ".example.com", URL: "https://" + name + ".example.com", Status: 200, Reachable: true})
+	}
+	h.OnDomainVerified(&DomainEntry{Domain: "a.example.com", Reachable: true}) // Duplicate
+	h.OnDomainVerified(&DomainEntry{Domain: "dark.example.com"})               // Unreachable
+
+	h.mu.Lock()
+	screen := h.screen(100, 8)
+	h.mu.Unlock()
+	// Two rows fit on an 8 line terminal; the newest hosts stay visible
+	if strings.Contains(screen, "https://b.example.com") || !strings.Contains(screen, "https://d.example.com") {
+		t.Errorf("Expected only the newest hosts, got %q", screen)
+	}
+	if !strings.Contains(screen, "2 more") || strings.Contains(screen, "dark.example.com") {
+		t.Errorf("Unexpected live host list: %q", screen)
+	}
+
+	h.Close()
+	if !strings.HasPrefix(out.String(), ansiAltScreen) || !strings.HasSuffix(out.String(), ansiShowCursor+ansiMainScreen) {
+		t.Errorf("Expected alternate screen to be entered and restored, got %q", out.String())
+	}
+}
+
+func TestTerminalProgressETA(t *testing.T) {
+	h := NewTerminalProgressHandler(&bytes.Buffer{}, TerminalProgressOptions{})
+	if eta := h.eta(); eta != -1 {
+		t.Errorf("Expected unknown ETA before any stage, got %v", eta)
+	}
+
+	// A first batch of 10 targets took 10s, so 30 targets should take about 30s
+	h.OnStageEnd(StageInfo{Stage: StageCertificate, Targets: 10}, 5, 10*time.Second)
+	h.OnStageStart(StageInfo{Stage: StageCertificate, Depth: 1, Targets: 30})
+	if eta := h.eta(); eta < 29*time.Second || eta > 30*time.Second {
+		t.Errorf("Expected an ETA of about 30s, got %v", eta)
+	}
+
+	// Stages without history have no estimate
+	h.OnStageStart(StageInfo{Stage: StagePassive, Targets: 3})
+	if eta := h.eta(); eta != -1 {
+		t.Errorf("Expected unknown ETA for a new stage, got %v", eta)
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{0, "0:00"},
+		{65 * time.Second, "1:05"},
+		{3*time.Hour + 2*time.Minute + 1*time.Second, "3:02:01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := formatElapsed(tt.duration); got != tt.expected {
+				t.Errorf("formatElapsed(%v) = %s, want %s", tt.duration, got, tt.expected)
+			}
+		})
+	}
+	if got := formatETA(-1); got != "--" {
+		t.Errorf("formatETA(-1) = %s, want --", got)
+	}
+}
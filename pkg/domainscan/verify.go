@@ -0,0 +1,136 @@
+package domainscan
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/discovery"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// FieldChange is a field of a domain entry whose value changed on re-verification.
+// Values are rendered as strings; an empty string means the field was unset.
+type FieldChange struct {
+	Field    string `json:"field"`    // reachable, status, url, ip, redirect, certificate or certificate_trust
+	Previous string `json:"previous"` // Value in the original result
+	Current  string `json:"current"`  // Value after re-verification
+}
+
+// EntryChange lists the changed fields of one domain
+type EntryChange struct {
+	Domain string        `json:"domain"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// VerifyResult is a re-verified result and the entries that changed
+type VerifyResult struct {
+	Result  *AssetDiscoveryResult `json:"result"`
+	Changes []EntryChange         `json:"changes"` // Sorted by domain
+}
+
+// Verify re-probes every entry of a saved result over HTTP/TLS without enumerating new
+// domains, returning an updated copy of the result and the fields that changed. The
+// input result is not modified.
+func (s *Scanner) Verify(ctx context.Context, result *AssetDiscoveryResult) (*VerifyResult, error) {
+	if result == nil || len(result.Domains) == 0 {
+		return nil, NewError(ErrInvalidConfig, "no domains to verify", nil)
+	}
+
+	// Work on copies so the caller's result keeps the original values
+	domains := make([]string, 0, len(result.Domains))
+	verified := make(map[string]*DomainEntry, len(result.Domains))
+	for name, entry := range result.Domains {
+		copied := *entry
+		copied.Sources = append([]types.Source(nil), entry.Sources...)
+		verified[name] = &copied
+		domains = append(domains, name)
+	}
+	sort.Strings(domains)
+
+	if s.progress != nil {
+		s.progress.OnStart(domains, nil)
+	}
+	stageEnd := s.stageStart(StageHTTP, 0, len(domains))
+	entries, _, _, err := discovery.BulkCertificateAnalysisWithProbe(ctx, domains, nil, false, s.config.Discovery.probeOptions(), s.logger)
+	if err != nil {
+		s.reportError(StageHTTP, err)
+		stageEnd(0)
+		return nil, NewError(ErrHTTPScanFailed, "verification failed", err)
+	}
+	s.mergeProbeResults(entries, verified, "Verified")
+	stageEnd(0)
+
+	updated := &AssetDiscoveryResult{
+		Domains:    verified,
+		Statistics: result.Statistics,
+		Errors:     []error{},
+	}
+	updated.UpdateStatistics()
+
+	changes := []EntryChange{}
+	for _, name := range domains {
+		if fields := diffEntry(result.Domains[name], verified[name]); len(fields) > 0 {
+			changes = append(changes, EntryChange{Domain: name, Fields: fields})
+		}
+	}
+
+	if s.progress != nil {
+		s.progress.OnEnd(updated)
+	}
+	return &VerifyResult{Result: updated, Changes: changes}, nil
+}
+
+// diffEntry compares the probed fields of two entries of the same domain
+func diffEntry(before, after *DomainEntry) []FieldChange {
+	var fields []FieldChange
+	compare := func(field, previous, current string) {
+		if previous != current {
+			fields = append(fields, FieldChange{Field: field, Previous: previous, Current: current})
+		}
+	}
+
+	compare("reachable", strconv.FormatBool(before.Reachable), strconv.FormatBool(after.Reachable))
+	compare("status", statusString(before.Status), statusString(after.Status))
+	compare("url", before.URL, after.URL)
+	compare("ip", before.IP, after.IP)
+	compare("redirect", redirectString(before.Redirect), redirectString(after.Redirect))
+	compare("certificate", certificateString(before.Certificate), certificateString(after.Certificate))
+	if before.Certificate != nil && after.Certificate != nil {
+		compare("certificate_trust", trustString(before.Certificate), trustString(after.Certificate))
+	}
+	return fields
+}
+
+// statusString renders an HTTP status, with no response as empty
+func statusString(status int) string {
+	if status == 0 {
+		return ""
+	}
+	return strconv.Itoa(status)
+}
+
+// redirectString renders where a redirect leads
+func redirectString(redirect *types.RedirectInfo) string {
+	if redirect == nil || !redirect.IsRedirect {
+		return ""
+	}
+	return redirect.RedirectsTo
+}
+
+// certificateString identifies a certificate by subject, issuer and validity
+func certificateString(cert *types.CertificateInfo) string {
+	if cert == nil {
+		return ""
+	}
+	return cert.Subject + " issued by " + cert.Issuer + ", expires " + cert.ExpiresOn.UTC().Format(time.DateOnly)
+}
+
+// trustString renders whether a certificate verified against the trust store
+func trustString(cert *types.CertificateInfo) string {
+	if cert.Untrusted {
+		return "untrusted"
+	}
+	return "trusted"
+}
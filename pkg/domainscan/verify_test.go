@@ -0,0 +1,122 @@
+package domainscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+func TestDiffEntry(t *testing.T) {
+	expires := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	before := &DomainEntry{
+		Domain: "api.example.com", URL: "https://api.example.com", Status: 200, Reachable: true, IP: "192.0.2.1",
+		Certificate: &types.CertificateInfo{Subject: "api.example.com", Issuer: "R3", ExpiresOn: expires},
+	}
+
+	if fields := diffEntry(before, before); len(fields) != 0 {
+		t.Errorf("Expected no changes for an identical entry, got %+v", fields)
+	}
+
+	after := &DomainEntry{
+		Domain: "api.example.com", URL: "https://api.example.com", Status: 301, Reachable: true, IP: "192.0.2.2",
+		Redirect:    &types.RedirectInfo{IsRedirect: true, RedirectsTo: "https://www.example.com"},
+		Certificate: &types.CertificateInfo{Subject: "api.example.com", Issuer: "R3", ExpiresOn: expires, Untrusted: true},
+	}
+	expected := []FieldChange{
+		{Field: "status", Previous: "200", Current: "301"},
+		{Field: "ip", Previous: "192.0.2.1", Current: "192.0.2.2"},
+		{Field: "redirect", Previous: "", Current: "https://www.example.com"},
+		{Field: "certificate_trust", Previous: "trusted", Current: "untrusted"},
+	}
+	assertFieldChanges(t, diffEntry(before, after), expected)
+
+	dark := &DomainEntry{Domain: "api.example.com"}
+	expected = []FieldChange{
+		{Field: "reachable", Previous: "true", Current: "false"},
+		{Field: "status", Previous: "200", Current: ""},
+		{Field: "url", Previous: "https://api.example.com", Current: ""},
+		{Field: "ip", Previous: "192.0.2.1", Current: ""},
+		{Field: "certificate", Previous: "api.example.com issued by R3, expires 2027-03-01", Current: ""},
+	}
+	assertFieldChanges(t, diffEntry(before, dark), expected)
+}
+
+func assertFieldChanges(t *testing.T, got, expected []FieldChange) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %+v", len(expected), len(got), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Change %d: expected %+v, got %+v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	scanner := New(DefaultConfig())
+
+	if _, err := scanner.Verify(context.Background(), &AssetDiscoveryResult{}); err == nil {
+		t.Error("Expected an error for an empty result")
+	}
+
+	// A .invalid name never resolves, so a host recorded as live must come back dark
+	original := &AssetDiscoveryResult{Domains: map[string]*DomainEntry{
+		"gone.invalid": {Domain: "gone.invalid", URL: "https://gone.invalid", Status: 200, Reachable: true,
+			Seeds: []string{"example.invalid"}, Sources: []types.Source{{Name: "subfinder", Type: "passive", Seed: "example.invalid"}}},
+	}}
+	original.UpdateStatistics()
+
+	verified, err := scanner.Verify(context.Background(), original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := verified.Result.Domains["gone.invalid"]
+	if entry.Reachable || entry.Status != 0 || entry.URL != "" {
+		t.Errorf("Expected the entry to be unreachable after verification: %+v", entry)
+	}
+	if len(entry.Seeds) != 1 || len(entry.Sources) != 1 || entry.Sources[0].Name != "subfinder" {
+		t.Errorf("Expected seeds and sources to be kept: %+v", entry)
+	}
+	if verified.Result.Statistics.ActiveServices != 0 {
+		t.Errorf("Expected statistics to be recomputed, got %+v", verified.Result.Statistics)
+	}
+	if len(verified.Changes) != 1 || verified.Changes[0].Domain != "gone.invalid" || verified.Changes[0].Fields[0].Field != "reachable" {
+		t.Errorf("Expected the reachability change to be reported: %+v", verified.Changes)
+	}
+	if !original.Domains["gone.invalid"].Reachable || original.Statistics.ActiveServices != 1 {
+		t.Error("Expected the original result to be left unchanged")
+	}
+}
+
+func TestMergeProbeResults(t *testing.T) {
+	config := DefaultConfig()
+	config.LogLevel = "silent"
+	scanner := New(config)
+
+	imported := []types.Source{{Name: "amass", Type: "passive", Stage: "import"}}
+	outputDomains := map[string]*DomainEntry{
+		"api.example.com": {Domain: "api.example.com", Sources: append([]types.Source(nil), imported...)},
+	}
+	probedAt := time.Now()
+	probed := []*DomainEntry{
+		{Domain: "api.example.com", URL: "https://api.example.com", Status: 200, Reachable: true, IP: "192.0.2.1",
+			CNAMEs: []string{"api.edge.example.net"}, ProbedAt: probedAt,
+			Sources: []types.Source{{Name: "traced", Type: "passive"}, {Name: "httpx", Type: "http"}}},
+		{Domain: "other.example.com", Reachable: true},
+	}
+	scanner.mergeProbeResults(probed, outputDomains, "Verified")
+
+	entry := outputDomains["api.example.com"]
+	if !entry.Reachable || entry.Status != 200 || entry.IP != "192.0.2.1" || len(entry.CNAMEs) != 1 || !entry.ProbedAt.Equal(probedAt) {
+		t.Errorf("Expected the probed fields to be copied, got %+v", entry)
+	}
+	if len(entry.Sources) != 1 || entry.Sources[0] != imported[0] {
+		t.Errorf("Expected sources to be left untouched, got %+v", entry.Sources)
+	}
+	if _, exists := outputDomains["other.example.com"]; exists {
+		t.Error("Expected probe results for unknown domains to be ignored")
+	}
+}
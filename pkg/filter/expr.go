@@ -0,0 +1,451 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// Expr is a parsed filter expression.
+//
+// Expressions compare fields with literals and combine the comparisons with &&, || and !
+// (or and, or, not) and parentheses:
+//
+//	reachable && status >= 400 && cert.expires < 30d && source == "certificate-san"
+//
+// Strings are quoted and support == != < <= > >= and regular expression matches with =~
+// and !~. Numbers support all comparisons, booleans == and !=. Duration fields compare
+// with durations such as 12h, 30d or 2w. List fields such as source match when any
+// element matches, and != and !~ when none does. A field on its own tests a boolean or,
+// for other types, that the entry has a value. Comparisons on a missing value, such as
+// cert.issuer of a domain without a certificate, are false.
+type Expr struct {
+	source string
+	root   node
+}
+
+// Parse compiles a filter expression
+func Parse(expr string) (*Expr, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos+1)
+	}
+	return &Expr{source: expr, root: root}, nil
+}
+
+// String returns the expression as given to Parse
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Match reports whether an entry satisfies the expression, with durations measured from now
+func (e *Expr) Match(entry *domainscan.DomainEntry, now time.Time) bool {
+	return e.root.eval(entry, now)
+}
+
+// Apply returns a new result holding the entries that match the expression, with
+// statistics recomputed. A nil expression matches every entry.
+func Apply(result *domainscan.AssetDiscoveryResult, expr *Expr, now time.Time) *domainscan.AssetDiscoveryResult {
+	filtered := &domainscan.AssetDiscoveryResult{
+		Domains:    make(map[string]*domainscan.DomainEntry),
+		Statistics: result.Statistics,
+		Errors:     result.Errors,
+	}
+	for name, entry := range result.Domains {
+		if expr == nil || expr.Match(entry, now) {
+			filtered.Domains[name] = entry
+		}
+	}
+	filtered.UpdateStatistics()
+	return filtered
+}
+
+// node is an evaluable part of an expression
+type node interface {
+	eval(entry *domainscan.DomainEntry, now time.Time) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(e *domainscan.DomainEntry, now time.Time) bool {
+	return n.left.eval(e, now) && n.right.eval(e, now)
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(e *domainscan.DomainEntry, now time.Time) bool {
+	return n.left.eval(e, now) || n.right.eval(e, now)
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(e *domainscan.DomainEntry, now time.Time) bool {
+	return !n.operand.eval(e, now)
+}
+
+// truthNode tests a field on its own
+type truthNode struct{ field field }
+
+func (n truthNode) eval(e *domainscan.DomainEntry, now time.Time) bool {
+	value, ok := n.field.value(e, now)
+	if !ok {
+		return false
+	}
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	case []string:
+		return len(v) > 0
+	}
+	return true
+}
+
+// compareNode compares a field with a literal
+type compareNode struct {
+	field   field
+	op      string
+	literal any // string, float64, bool or time.Duration
+	regexp  *regexp.Regexp
+}
+
+func (n compareNode) eval(e *domainscan.DomainEntry, now time.Time) bool {
+	value, ok := n.field.value(e, now)
+	if !ok {
+		return false
+	}
+	if list, isList := value.([]string); isList {
+		// != and !~ hold when no element matches the positive operator
+		positive := map[string]string{"!=": "==", "!~": "=~"}[n.op]
+		if positive == "" {
+			for _, v := range list {
+				if n.compare(v) {
+					return true
+				}
+			}
+			return false
+		}
+		inverse := compareNode{field: n.field, op: positive, literal: n.literal, regexp: n.regexp}
+		for _, v := range list {
+			if inverse.compare(v) {
+				return false
+			}
+		}
+		return true
+	}
+	return n.compare(value)
+}
+
+// compare applies the operator to a single value of the literal's type
+func (n compareNode) compare(value any) bool {
+	switch n.op {
+	case "=~":
+		return n.regexp.MatchString(value.(string))
+	case "!~":
+		return !n.regexp.MatchString(value.(string))
+	}
+
+	var cmp int
+	switch v := value.(type) {
+	case string:
+		cmp = strings.Compare(v, n.literal.(string))
+	case float64:
+		cmp = compareOrdered(v, n.literal.(float64))
+	case time.Duration:
+		cmp = compareOrdered(v, n.literal.(time.Duration))
+	case bool:
+		if v == n.literal.(bool) {
+			cmp = 0
+		} else {
+			cmp = 1
+		}
+	}
+	switch n.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+func compareOrdered[T float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits an expression into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				// Only quotes and backslashes are escaped, so regular expressions such as "\." keep theirs
+				if runes[j] == '\\' && j+1 < len(runes) && (runes[j+1] == r || runes[j+1] == '\\') {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i+1)
+			}
+			tokens = append(tokens, token{kind: tokString, text: sb.String(), pos: i})
+			i = j + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[i:j]), pos: i})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[i:j]), pos: i})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")"} {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i+1)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, text: "end of expression", pos: len(runes)}), nil
+}
+
+// Parser
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is one of the operators or keywords
+func (p *parser) accept(ops ...string) bool {
+	tok := p.peek()
+	for _, op := range ops {
+		if (tok.kind == tokOp || tok.kind == tokIdent) && strings.EqualFold(tok.text, op) {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+// or := and ( "||" and )*
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||", "or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+// and := unary ( "&&" unary )*
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&", "and") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+// unary := "!" unary | "(" or ")" | comparison
+func (p *parser) unary() (node, error) {
+	if p.accept("!", "not") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	if p.accept("(") {
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			tok := p.peek()
+			return nil, fmt.Errorf("expected ) at position %d, got %q", tok.pos+1, tok.text)
+		}
+		return inner, nil
+	}
+	return p.comparison()
+}
+
+// comparison := field [ operator literal ]
+func (p *parser) comparison() (node, error) {
+	tok := p.next()
+	if tok.kind != tokIdent {
+		return nil, fmt.Errorf("expected a field at position %d, got %q", tok.pos+1, tok.text)
+	}
+	f, err := lookup(tok.text)
+	if err != nil {
+		return nil, err
+	}
+
+	opTok := p.peek()
+	if opTok.kind != tokOp || !isComparison(opTok.text) {
+		return truthNode{field: f}, nil
+	}
+	p.next()
+	litTok := p.next()
+	n := compareNode{field: f, op: opTok.text}
+	if n.literal, err = literal(f, tok.text, n.op, litTok); err != nil {
+		return nil, err
+	}
+	if n.op == "=~" || n.op == "!~" {
+		if n.regexp, err = regexp.Compile(n.literal.(string)); err != nil {
+			return nil, fmt.Errorf("invalid regular expression for %s: %w", tok.text, err)
+		}
+	}
+	return n, nil
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=", "=~", "!~":
+		return true
+	}
+	return false
+}
+
+// literal converts a literal token to the type of the field it is compared with
+func literal(f field, name string, op string, tok token) (any, error) {
+	mismatch := func() error {
+		return fmt.Errorf("%s is a %s field and can't be compared with %q using %s (position %d)", name, f.kind, tok.text, op, tok.pos+1)
+	}
+	if (op == "=~" || op == "!~") && f.kind != kindString && f.kind != kindList {
+		return nil, mismatch()
+	}
+
+	switch f.kind {
+	case kindString, kindList:
+		if tok.kind != tokString {
+			return nil, fmt.Errorf("expected a quoted string after %s %s at position %d, got %q", name, op, tok.pos+1, tok.text)
+		}
+		return tok.text, nil
+	case kindNumber:
+		if tok.kind != tokNumber {
+			return nil, mismatch()
+		}
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, mismatch()
+		}
+		return n, nil
+	case kindBool:
+		if tok.kind != tokIdent || (op != "==" && op != "!=") {
+			return nil, mismatch()
+		}
+		b, err := strconv.ParseBool(strings.ToLower(tok.text))
+		if err != nil {
+			return nil, mismatch()
+		}
+		return b, nil
+	case kindDuration:
+		if tok.kind != tokNumber {
+			return nil, mismatch()
+		}
+		if tok.text == "0" {
+			return time.Duration(0), nil
+		}
+		d, err := utils.ParseDuration(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("%s compares with durations such as 12h, 30d or 2w: %w", name, err)
+		}
+		return d, nil
+	}
+	return nil, mismatch()
+}
@@ -0,0 +1,200 @@
+// Package filter implements the expression language used to filter, sort and select
+// fields of discovery results, e.g. `reachable && status >= 400 && cert.expires < 30d`.
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/discovery"
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+// kind is the type of a field's value
+type kind int
+
+const (
+	kindString kind = iota
+	kindNumber
+	kindBool
+	kindDuration // a time, compared as the distance from now
+	kindList     // several strings; comparisons match when any element does
+)
+
+func (k kind) String() string {
+	return [...]string{"string", "number", "boolean", "duration", "list"}[k]
+}
+
+// field describes a queryable property of a domain entry
+type field struct {
+	kind        kind
+	description string
+	// get returns the field value (string, float64, bool or []string), or false when the
+	// entry has no value. Duration fields use at instead.
+	get func(entry *domainscan.DomainEntry) (any, bool)
+	// at returns the time of a duration field; since reports whether the duration is
+	// measured from that time to now (age) rather than from now until it (remaining)
+	at    func(entry *domainscan.DomainEntry) time.Time
+	since bool
+}
+
+var fields = map[string]field{
+	"domain": {kind: kindString, description: "Domain name", get: func(e *domainscan.DomainEntry) (any, bool) {
+		return e.Domain, true
+	}},
+	"unicode_domain": {kind: kindString, description: "Unicode form of an internationalised domain", get: func(e *domainscan.DomainEntry) (any, bool) {
+		return e.UnicodeDomain, e.UnicodeDomain != ""
+	}},
+	"url": {kind: kindString, description: "Verified URL", get: func(e *domainscan.DomainEntry) (any, bool) {
+		return e.URL, e.URL != ""
+	}},
+	"status": {kind: kindNumber, description: "HTTP status code (0 when not verified)", get: func(e *domainscan.DomainEntry) (any, bool) {
+		return float64(e.Status), true
+	}},
+	"reachable": {kind: kindBool, description: "Whether the domain answered over HTTP", get: func(e *domainscan.DomainEntry) (any, bool) {
+		return e.Reachable, true
+	}},
+	"ip": {kind: kindString, description: "Resolved IP address", get: func(e *domainscan.DomainEntry) (any, bool) {
+		return e.IP, e.IP != ""
+	}},
+	"redirect": {kind: kindBool, description: "Whether the domain redirects", get: func(e *domainscan.DomainEntry) (any, bool) {
+		return e.Redirect != nil && e.Redirect.IsRedirect, true
+	}},
+	"redirect.to": {kind: kindString, description: "Final redirect URL", get: func(e *domainscan.DomainEntry) (any, bool) {
+		if e.Redirect == nil || !e.Redirect.IsRedirect {
+			return nil, false
+		}
+		return e.Redirect.RedirectsTo, true
+	}},
+	"redirect.hosts": {kind: kindList, description: "Hosts in the redirect chain, excluding the domain itself", get: func(e *domainscan.DomainEntry) (any, bool) {
+		hosts := discovery.RedirectHosts(e.Domain, e.Redirect)
+		return hosts, len(hosts) > 0
+	}},
+	"source": {kind: kindList, description: "Discovery source names (e.g. subfinder, certificate-san)", get: func(e *domainscan.DomainEntry) (any, bool) {
+		var names []string
+		for _, src := range e.Sources {
+			names = append(names, src.Name)
+		}
+		return unique(names), len(names) > 0
+	}},
+	"source.type": {kind: kindList, description: "Discovery source types (e.g. passive, certificate)", get: func(e *domainscan.DomainEntry) (any, bool) {
+		var types []string
+		for _, src := range e.Sources {
+			types = append(types, src.Type)
+		}
+		return unique(types), len(types) > 0
+	}},
+	"sources": {kind: kindNumber, description: "Number of distinct discovery sources", get: func(e *domainscan.DomainEntry) (any, bool) {
+		names := make(map[string]bool)
+		for _, src := range e.Sources {
+			names[src.Name] = true
+		}
+		return float64(len(names)), true
+	}},
+	"seed": {kind: kindList, description: "Scan targets whose discovery reached the domain", get: func(e *domainscan.DomainEntry) (any, bool) {
+		return e.Seeds, len(e.Seeds) > 0
+	}},
+	"cert": {kind: kindBool, description: "Whether a TLS certificate was collected", get: func(e *domainscan.DomainEntry) (any, bool) {
+		return e.Certificate != nil, true
+	}},
+	"cert.issuer": {kind: kindString, description: "Certificate issuer", get: func(e *domainscan.DomainEntry) (any, bool) {
+		if e.Certificate == nil {
+			return nil, false
+		}
+		return e.Certificate.Issuer, true
+	}},
+	"cert.subject": {kind: kindString, description: "Certificate subject", get: func(e *domainscan.DomainEntry) (any, bool) {
+		if e.Certificate == nil {
+			return nil, false
+		}
+		return e.Certificate.Subject, true
+	}},
+	"cert.untrusted": {kind: kindBool, description: "Whether the certificate failed trust verification", get: func(e *domainscan.DomainEntry) (any, bool) {
+		if e.Certificate == nil {
+			return nil, false
+		}
+		return e.Certificate.Untrusted, true
+	}},
+	"cert.expires": {kind: kindDuration, description: "Time until the certificate expires (negative once expired)", at: func(e *domainscan.DomainEntry) time.Time {
+		if e.Certificate == nil {
+			return time.Time{}
+		}
+		return e.Certificate.ExpiresOn
+	}},
+	"cert.issued": {kind: kindDuration, since: true, description: "Time since the certificate was issued", at: func(e *domainscan.DomainEntry) time.Time {
+		if e.Certificate == nil {
+			return time.Time{}
+		}
+		return e.Certificate.IssuedOn
+	}},
+	"probed": {kind: kindDuration, since: true, description: "Time since the domain was last probed", at: func(e *domainscan.DomainEntry) time.Time {
+		return e.ProbedAt
+	}},
+	"discovered": {kind: kindDuration, since: true, description: "Time since the domain was first discovered", at: func(e *domainscan.DomainEntry) time.Time {
+		var first time.Time
+		for _, src := range e.Sources {
+			if !src.DiscoveredAt.IsZero() && (first.IsZero() || src.DiscoveredAt.Before(first)) {
+				first = src.DiscoveredAt
+			}
+		}
+		return first
+	}},
+}
+
+// Fields returns the sorted names of all queryable fields
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Describe returns the help text of a field and its type
+func Describe(name string) string {
+	f, ok := fields[name]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", f.description, f.kind)
+}
+
+// lookup returns the named field
+func lookup(name string) (field, error) {
+	f, ok := fields[strings.ToLower(name)]
+	if !ok {
+		return field{}, fmt.Errorf("unknown field %q: must be one of %s", name, strings.Join(Fields(), ", "))
+	}
+	return f, nil
+}
+
+// value returns the field value of an entry; durations are returned as time.Duration
+func (f field) value(entry *domainscan.DomainEntry, now time.Time) (any, bool) {
+	if f.kind != kindDuration {
+		return f.get(entry)
+	}
+	at := f.at(entry)
+	if at.IsZero() {
+		return nil, false
+	}
+	if f.since {
+		return now.Sub(at), true
+	}
+	return at.Sub(now), true
+}
+
+// unique returns values without duplicates, in first-seen order
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,170 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+var now = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+func testResult() *domainscan.AssetDiscoveryResult {
+	return &domainscan.AssetDiscoveryResult{Domains: map[string]*domainscan.DomainEntry{
+		"api.example.com": {Domain: "api.example.com", URL: "https://api.example.com", Status: 503, Reachable: true, IP: "192.0.2.1",
+			Sources:     []types.Source{{Name: "certificate-san", Type: "certificate", DiscoveredAt: now.AddDate(0, 0, -2)}, {Name: "httpx", Type: "http"}},
+			Seeds:       []string{"example.com"},
+			Certificate: &types.CertificateInfo{Issuer: "R3", ExpiresOn: now.AddDate(0, 0, 10), IssuedOn: now.AddDate(0, 0, -80)}},
+		"www.example.com": {Domain: "www.example.com", URL: "https://www.example.com", Status: 200, Reachable: true,
+			Sources:     []types.Source{{Name: "subfinder", Type: "passive", DiscoveredAt: now.AddDate(0, -1, 0)}},
+			Redirect:    &types.RedirectInfo{IsRedirect: true, RedirectsTo: "https://example.com/"},
+			Certificate: &types.CertificateInfo{Issuer: "DigiCert", ExpiresOn: now.AddDate(0, 0, 200), Untrusted: true}},
+		"old.example.com": {Domain: "old.example.com", Status: 404, Reachable: true,
+			Sources:     []types.Source{{Name: "subfinder", Type: "passive"}, {Name: "certificate-san", Type: "certificate"}},
+			Certificate: &types.CertificateInfo{Issuer: "R3", ExpiresOn: now.AddDate(0, 0, -3)}},
+		"dev.example.com": {Domain: "dev.example.com",
+			Sources: []types.Source{{Name: "subfinder", Type: "passive"}}},
+	}}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{`reachable && status >= 400 && cert.expires < 30d && source == "certificate-san"`, []string{"api.example.com", "old.example.com"}},
+		{`reachable`, []string{"api.example.com", "old.example.com", "www.example.com"}},
+		{`!reachable`, []string{"dev.example.com"}},
+		{`not cert`, []string{"dev.example.com"}},
+		{`cert.expires < 0`, []string{"old.example.com"}},
+		{`cert.expires >= 30d or status == 503`, []string{"api.example.com", "www.example.com"}},
+		{`source != "certificate-san"`, []string{"dev.example.com", "www.example.com"}},
+		{`source.type == 'http'`, []string{"api.example.com"}},
+		{`domain =~ "^(api|www)\\."`, []string{"api.example.com", "www.example.com"}},
+		{`domain =~ 'api\.example'`, []string{"api.example.com"}},
+		{`cert.issuer !~ "^R"`, []string{"www.example.com"}},
+		{`redirect && redirect.to == "https://example.com/"`, []string{"www.example.com"}},
+		{`redirect.hosts == "example.com"`, []string{"www.example.com"}},
+		{`cert.untrusted == true`, []string{"www.example.com"}},
+		{`(status == 200 || status == 404) && !(cert.issuer == "R3")`, []string{"www.example.com"}},
+		{`discovered < 7d`, []string{"api.example.com"}},
+		{`cert.issued > 60d`, []string{"api.example.com"}},
+		{`seed == "example.com" && ip`, []string{"api.example.com"}},
+		{`sources >= 2 AND url`, []string{"api.example.com"}},
+	}
+
+	result := testResult()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			var got []string
+			for _, entry := range Entries(Apply(result, expr, now)) {
+				got = append(got, entry.Domain)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`unknown == 1`,
+		`status == "200"`,
+		`source == certificate`,
+		`cert.expires < 30`,
+		`cert.expires < soon`,
+		`reachable > true`,
+		`status =~ "5.."`,
+		`domain =~ "("`,
+		`(reachable`,
+		`reachable status`,
+		`domain == "unterminated`,
+		`reachable & status`,
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Expected Parse(%q) to fail", expr)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	result := testResult()
+	expr, _ := Parse(`status >= 400`)
+	filtered := Apply(result, expr, now)
+	if filtered.Statistics.TotalSubdomains != 2 || filtered.Statistics.ActiveServices != 2 {
+		t.Errorf("Expected recomputed statistics of 2 live domains, got %+v", filtered.Statistics)
+	}
+	if len(result.Domains) != 4 {
+		t.Error("Expected the input result to be left unchanged")
+	}
+	if got := Apply(result, nil, now).Statistics.TotalSubdomains; got != 4 {
+		t.Errorf("Expected a nil expression to keep every domain, got %d", got)
+	}
+}
+
+func TestSortAndRows(t *testing.T) {
+	entries := Entries(testResult())
+	if err := Sort(entries, "-status,domain", now); err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, entry := range entries {
+		order = append(order, entry.Domain)
+	}
+	if got := strings.Join(order, " "); got != "api.example.com old.example.com www.example.com dev.example.com" {
+		t.Errorf("Unexpected descending status order: %s", got)
+	}
+
+	// Entries without a certificate sort last in either direction
+	if err := Sort(entries, "cert.expires", now); err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].Domain != "old.example.com" || entries[3].Domain != "dev.example.com" {
+		t.Errorf("Unexpected expiry order: %s ... %s", entries[0].Domain, entries[3].Domain)
+	}
+	if err := Sort(entries, "nope", now); err == nil {
+		t.Error("Expected an error for an unknown sort field")
+	}
+
+	columns, err := ParseFields("domain, status,cert.expires,source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseFields("domain,bogus"); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+
+	var text bytes.Buffer
+	if err := WriteRows(&text, "text", entries[:1], columns, now); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "DOMAIN") || !strings.Contains(text.String(), "2026-08-29T00:00:00Z") || !strings.Contains(text.String(), "subfinder,certificate-san") {
+		t.Errorf("Unexpected text rows:\n%s", text.String())
+	}
+
+	var jsonl bytes.Buffer
+	if err := WriteRows(&jsonl, "jsonl", entries[3:], columns, now); err != nil {
+		t.Fatal(err)
+	}
+	var row map[string]any
+	if err := json.Unmarshal(jsonl.Bytes(), &row); err != nil {
+		t.Fatalf("Invalid JSONL row %q: %v", jsonl.String(), err)
+	}
+	if row["domain"] != "dev.example.com" || row["status"] != float64(0) || row["cert.expires"] != nil {
+		t.Errorf("Unexpected JSON row: %v", row)
+	}
+
+	if err := WriteRows(&text, "html", entries, columns, now); err == nil {
+		t.Error("Expected an error for an unsupported row format")
+	}
+}
@@ -0,0 +1,228 @@
+package filter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+// DefaultColumns are the fields shown when sorting without selecting fields
+var DefaultColumns = []string{"domain", "reachable", "status", "url", "source"}
+
+// ParseFields splits a comma separated field list, validating every name
+func ParseFields(spec string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, err := lookup(name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Sort orders entries by comma separated fields, each optionally prefixed with - for
+// descending order, e.g. "-status,cert.expires". Entries without a value sort last and
+// ties are broken by domain name.
+func Sort(entries []*domainscan.DomainEntry, spec string, now time.Time) error {
+	type key struct {
+		field      field
+		descending bool
+	}
+	var keys []key
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		descending := strings.HasPrefix(name, "-")
+		name = strings.TrimLeft(name, "-+")
+		if name == "" {
+			continue
+		}
+		f, err := lookup(name)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key{field: f, descending: descending})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		for _, k := range keys {
+			a, aok := k.field.value(entries[i], now)
+			b, bok := k.field.value(entries[j], now)
+			if aok != bok {
+				return aok
+			}
+			if !aok {
+				continue
+			}
+			cmp := compareValues(a, b)
+			if cmp == 0 {
+				continue
+			}
+			if k.descending {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return entries[i].Domain < entries[j].Domain
+	})
+	return nil
+}
+
+// compareValues orders two values of the same field
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case float64:
+		return compareOrdered(a, b.(float64))
+	case time.Duration:
+		return compareOrdered(a, b.(time.Duration))
+	case bool:
+		switch {
+		case a == b.(bool):
+			return 0
+		case !a:
+			return -1
+		}
+		return 1
+	case []string:
+		return strings.Compare(strings.Join(a, ","), strings.Join(b.([]string), ","))
+	}
+	return 0
+}
+
+// Entries returns the entries of a result sorted by domain name
+func Entries(result *domainscan.AssetDiscoveryResult) []*domainscan.DomainEntry {
+	entries := make([]*domainscan.DomainEntry, 0, len(result.Domains))
+	for _, entry := range result.Domains {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Domain < entries[j].Domain
+	})
+	return entries
+}
+
+// RowFormats returns the formats supported by WriteRows
+func RowFormats() []string {
+	return []string{"text", "csv", "json", "jsonl"}
+}
+
+// WriteRows writes the selected fields of entries, in order, as an aligned table (text),
+// CSV, a JSON array or JSON lines. Duration fields are written as the underlying time.
+func WriteRows(w io.Writer, format string, entries []*domainscan.DomainEntry, columns []string, now time.Time) error {
+	selected := make([]field, len(columns))
+	for i, name := range columns {
+		f, err := lookup(name)
+		if err != nil {
+			return err
+		}
+		selected[i] = f
+	}
+
+	switch strings.ToLower(format) {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, entry := range entries {
+			cells := make([]string, len(selected))
+			for i, f := range selected {
+				cells[i] = cellText(f, entry, now)
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		return tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			cells := make([]string, len(selected))
+			for i, f := range selected {
+				cells[i] = cellText(f, entry, now)
+			}
+			if err := cw.Write(cells); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json", "jsonl":
+		rows := make([]map[string]any, 0, len(entries))
+		for _, entry := range entries {
+			row := make(map[string]any, len(selected))
+			for i, f := range selected {
+				row[columns[i]] = cellValue(f, entry, now)
+			}
+			rows = append(rows, row)
+		}
+		if strings.EqualFold(format, "jsonl") {
+			encoder := json.NewEncoder(w)
+			for _, row := range rows {
+				if err := encoder.Encode(row); err != nil {
+					return fmt.Errorf("failed to marshal JSONL row: %w", err)
+				}
+			}
+			return nil
+		}
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON rows: %w", err)
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+	return fmt.Errorf("format %q can't be used with selected fields: must be one of %s", format, strings.Join(RowFormats(), ", "))
+}
+
+// cellValue returns the JSON value of a field, nil when the entry has none
+func cellValue(f field, entry *domainscan.DomainEntry, now time.Time) any {
+	if f.kind == kindDuration {
+		if at := f.at(entry); !at.IsZero() {
+			return at
+		}
+		return nil
+	}
+	value, ok := f.value(entry, now)
+	if !ok {
+		return nil
+	}
+	if n, isNumber := value.(float64); isNumber && n == float64(int64(n)) {
+		return int64(n)
+	}
+	return value
+}
+
+// cellText returns the text form of a field, empty when the entry has none
+func cellText(f field, entry *domainscan.DomainEntry, now time.Time) string {
+	switch v := cellValue(f, entry, now).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return strings.Join(v, ",")
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	}
+	return ""
+}
@@ -0,0 +1,210 @@
+package ingest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// amassRecord is a line of amass enum -json output
+type amassRecord struct {
+	Name      string `json:"name"`
+	Domain    string `json:"domain"`
+	Addresses []struct {
+		IP string `json:"ip"`
+	} `json:"addresses"`
+	Sources []string `json:"sources"`
+	Source  string   `json:"source"`
+}
+
+// parseAmass reads amass enum -json lines, or the "name (FQDN) --> relation --> target (Type)"
+// lines printed by amass v4
+func parseAmass(scanner *bufio.Scanner, c *collector) error {
+	return records(scanner, func(line int, text string) error {
+		if strings.HasPrefix(text, "{") {
+			var record amassRecord
+			if err := json.Unmarshal([]byte(text), &record); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			ip := ""
+			if len(record.Addresses) > 0 {
+				ip = record.Addresses[0].IP
+			}
+			sources := record.Sources
+			if record.Source != "" {
+				sources = append(sources, record.Source)
+			}
+			if len(sources) == 0 {
+				c.add(record.Name, "", record.Domain, ip)
+			}
+			for _, source := range sources {
+				c.add(record.Name, source, record.Domain, ip)
+			}
+			return nil
+		}
+
+		// v4 graph lines: each side names a node and its type, e.g.
+		// "www.example.com (FQDN) --> a_record --> 192.0.2.1 (IPAddress)"
+		parts := strings.Split(text, "-->")
+		if len(parts) != 3 {
+			c.add(firstField(text), "", "", "")
+			return nil
+		}
+		subject, subjectType := graphNode(parts[0])
+		object, objectType := graphNode(parts[2])
+		if subjectType != "FQDN" {
+			return nil
+		}
+		relation := strings.TrimSpace(parts[1])
+		switch {
+		case objectType == "IPAddress" && (relation == "a_record" || relation == "aaaa_record"):
+			c.add(subject, "", "", object)
+		case objectType == "FQDN" && relation == "node":
+			// "example.com (FQDN) --> node --> www.example.com (FQDN)": a subdomain of an enumerated domain
+			c.add(subject, "", "", "")
+			c.add(object, "", subject, "")
+		case objectType == "FQDN" && (relation == "cname_record" || relation == "ns_record" || relation == "mx_record"):
+			c.add(subject, "", "", "")
+			c.add(object, "", "", "")
+		default:
+			c.add(subject, "", "", "")
+		}
+		return nil
+	})
+}
+
+// graphNode splits an amass v4 node such as "www.example.com (FQDN)" into name and type
+func graphNode(node string) (string, string) {
+	node = strings.TrimSpace(node)
+	open := strings.LastIndex(node, " (")
+	if open < 0 || !strings.HasSuffix(node, ")") {
+		return node, ""
+	}
+	return strings.TrimSpace(node[:open]), node[open+2 : len(node)-1]
+}
+
+// subfinderRecord is a line of subfinder -oJ output
+type subfinderRecord struct {
+	Host    string   `json:"host"`
+	Input   string   `json:"input"`
+	IP      string   `json:"ip"`
+	Source  string   `json:"source"`
+	Sources []string `json:"sources"`
+}
+
+// parseSubfinder reads subfinder -oJ lines, or host[,ip[,source]] lines from -silent and -oI output
+func parseSubfinder(scanner *bufio.Scanner, c *collector) error {
+	return records(scanner, func(line int, text string) error {
+		if strings.HasPrefix(text, "{") {
+			var record subfinderRecord
+			if err := json.Unmarshal([]byte(text), &record); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			sources := record.Sources
+			if record.Source != "" {
+				sources = append(sources, record.Source)
+			}
+			if len(sources) == 0 {
+				c.add(record.Host, "", record.Input, record.IP)
+			}
+			for _, source := range sources {
+				c.add(record.Host, source, record.Input, record.IP)
+			}
+			return nil
+		}
+
+		fields := strings.Split(text, ",")
+		host, ip, source := strings.TrimSpace(fields[0]), "", ""
+		if len(fields) > 1 {
+			ip = strings.TrimSpace(fields[1])
+		}
+		if len(fields) > 2 {
+			source = strings.TrimSpace(fields[2])
+		}
+		c.add(host, source, "", ip)
+		return nil
+	})
+}
+
+// massDNSRecord is a line of massdns -o J output
+type massDNSRecord struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Data   struct {
+		Answers []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+			Data string `json:"data"`
+		} `json:"answers"`
+	} `json:"data"`
+}
+
+// parseMassDNS reads massdns -o S ("name. A 192.0.2.1") or -o J output. Only names that
+// resolved are imported; CNAME targets are not, since they often point outside the scope.
+// Addresses a CNAME chain resolves to are attributed to the queried name.
+func parseMassDNS(scanner *bufio.Scanner, c *collector) error {
+	// Queried name of every CNAME target seen in simple output
+	cnameOwners := make(map[string]string)
+	return records(scanner, func(line int, text string) error {
+		if strings.HasPrefix(text, "{") {
+			var record massDNSRecord
+			if err := json.Unmarshal([]byte(text), &record); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			if record.Status != "" && record.Status != "NOERROR" {
+				return nil
+			}
+			ip := ""
+			for _, answer := range record.Data.Answers {
+				if answer.Type == "A" || answer.Type == "AAAA" {
+					ip = answer.Data
+					break
+				}
+			}
+			if len(record.Data.Answers) > 0 {
+				c.add(record.Name, "", "", ip)
+			}
+			return nil
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 3 {
+			return nil
+		}
+		name := normalizeName(fields[0])
+		if owner, isTarget := cnameOwners[name]; isTarget {
+			name = owner
+		}
+		ip := ""
+		switch fields[1] {
+		case "A", "AAAA":
+			ip = fields[2]
+		case "CNAME":
+			if target := normalizeName(fields[2]); target != "" && name != "" {
+				cnameOwners[target] = name
+			}
+		}
+		c.add(name, "", "", ip)
+		return nil
+	})
+}
+
+// parseList reads one host, URL or domain per line, using the first field of each line
+func parseList(scanner *bufio.Scanner, c *collector) error {
+	return records(scanner, func(_ int, text string) error {
+		c.add(firstField(text), "", "", "")
+		return nil
+	})
+}
+
+// firstField returns the first whitespace or comma separated field of a line
+func firstField(line string) string {
+	fields := strings.FieldsFunc(line, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,222 @@
+// Package ingest imports the output of other enumeration tools as discovery results
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/types"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// Supported input formats
+const (
+	FormatAuto      = "auto"      // Detect the format from the first record
+	FormatAmass     = "amass"     // amass enum -json lines, or amass v4 "name (FQDN) --> ..." lines
+	FormatSubfinder = "subfinder" // subfinder -oJ lines, or host[,ip] lines from -silent / -oI
+	FormatMassDNS   = "massdns"   // massdns -o S (simple text) or -o J (ndjson)
+	FormatList      = "list"      // One host, URL or domain per line
+)
+
+// StageImport is the provenance stage recorded on imported sources
+const StageImport = "import"
+
+// Formats returns the supported format names, auto first
+func Formats() []string {
+	return []string{FormatAuto, FormatAmass, FormatSubfinder, FormatMassDNS, FormatList}
+}
+
+// parser reads records from lines of a tool's output into a collector
+type parser func(scanner *bufio.Scanner, c *collector) error
+
+var parsers = map[string]parser{
+	FormatAmass:     parseAmass,
+	FormatSubfinder: parseSubfinder,
+	FormatMassDNS:   parseMassDNS,
+	FormatList:      parseList,
+}
+
+// ParseFile imports a tool output file; see Parse
+func ParseFile(path string, format string) (*domainscan.AssetDiscoveryResult, error) {
+	file, err := os.Open(path) // #nosec G304 - user supplied import file
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	result, err := Parse(file, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return result, nil
+}
+
+// Parse imports the output of another enumeration tool. Every domain becomes an
+// unverified entry whose sources name the tool and, where the tool reports them, its
+// upstream sources (e.g. "amass:crtsh"), with the enumerated domain as parent and seed.
+// Resolved IPs are kept. With FormatAuto the format is detected from the first record.
+func Parse(r io.Reader, format string) (*domainscan.AssetDiscoveryResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	format = strings.ToLower(format)
+	if format == "" || format == FormatAuto {
+		format = Detect(data)
+	}
+	parse, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format %q: must be one of %s", format, strings.Join(Formats(), ", "))
+	}
+
+	c := &collector{
+		tool:    format,
+		now:     time.Now(),
+		entries: make(map[string]*domainscan.DomainEntry),
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	if err := parse(scanner, c); err != nil {
+		return nil, fmt.Errorf("invalid %s output: %w", format, err)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	result := &domainscan.AssetDiscoveryResult{Domains: c.entries, Errors: []error{}}
+	result.TagSeeds(nil)
+	result.UpdateStatistics()
+	return result, nil
+}
+
+var (
+	amassGraphLine = regexp.MustCompile(`\(FQDN\)\s+-->`)
+	massDNSLine    = regexp.MustCompile(`^\S+\.?\s+(A|AAAA|CNAME|NS|MX|TXT|PTR|SOA)\s+\S+`)
+)
+
+// Detect guesses the format of tool output from its first non-empty line
+func Detect(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "{") {
+			var record map[string]json.RawMessage
+			if json.Unmarshal([]byte(line), &record) != nil {
+				return FormatList
+			}
+			switch {
+			case record["host"] != nil:
+				return FormatSubfinder
+			case record["data"] != nil || record["status"] != nil:
+				return FormatMassDNS
+			case record["name"] != nil:
+				return FormatAmass
+			}
+			return FormatList
+		}
+		switch {
+		case amassGraphLine.MatchString(line):
+			return FormatAmass
+		case massDNSLine.MatchString(line):
+			return FormatMassDNS
+		case strings.Contains(line, ","):
+			return FormatSubfinder
+		}
+		return FormatList
+	}
+	return FormatList
+}
+
+// collector accumulates imported entries
+type collector struct {
+	tool    string
+	now     time.Time
+	entries map[string]*domainscan.DomainEntry
+}
+
+// add records a domain found by the tool, optionally via an upstream source, under the
+// enumerated parent domain, with a resolved IP. Names that aren't valid domains are skipped.
+func (c *collector) add(name string, upstream string, parent string, ip string) {
+	domain := normalizeName(name)
+	if domain == "" {
+		return
+	}
+	entry, exists := c.entries[domain]
+	if !exists {
+		entry = &domainscan.DomainEntry{Domain: domain, UnicodeDomain: utils.ToUnicode(domain)}
+		c.entries[domain] = entry
+	}
+	if entry.IP == "" && ip != "" && utils.IsIPTarget(ip) && !strings.Contains(ip, "/") {
+		entry.IP = ip
+	}
+
+	sourceName := c.tool
+	if upstream = strings.ToLower(strings.TrimSpace(upstream)); upstream != "" {
+		sourceName += ":" + upstream
+	}
+	// Without a known parent the domain is its own seed; a later record naming the
+	// enumerated parent replaces that placeholder rather than adding a second source
+	parent = normalizeName(parent)
+	for i, src := range entry.Sources {
+		if src.Name != sourceName {
+			continue
+		}
+		if parent == "" || src.Parent == parent {
+			return
+		}
+		if src.Parent == domain {
+			entry.Sources[i].Parent = parent
+			entry.Sources[i].Seed = parent
+			return
+		}
+	}
+	if parent == "" {
+		parent = domain
+	}
+	entry.Sources = append(entry.Sources, types.Source{
+		Name:         sourceName,
+		Type:         "import",
+		Parent:       parent,
+		Seed:         parent,
+		Stage:        StageImport,
+		DiscoveredAt: c.now,
+	})
+}
+
+// normalizeName turns a reported name into a bare domain, dropping wildcard labels and
+// anything that isn't a valid multi-label domain name
+func normalizeName(name string) string {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "*.")
+	domain := utils.NormalizeTarget(name)
+	if domain == "" || utils.IsIPTarget(domain) || !strings.Contains(domain, ".") || strings.Contains(domain, "*") {
+		return ""
+	}
+	return domain
+}
+
+// records calls fn for every non-empty, non-comment line with its 1-based line number in the input
+func records(scanner *bufio.Scanner, fn func(number int, line string) error) error {
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := fn(number, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
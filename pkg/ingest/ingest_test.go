@@ -0,0 +1,207 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		domains map[string]string   // domain -> expected IP
+		sources map[string][]string // domain -> expected source names
+		parents map[string]string   // domain -> expected parent of the first source
+	}{
+		{
+			name:   "amass json",
+			format: FormatAmass,
+			input: `{"name":"www.example.com","domain":"example.com","addresses":[{"ip":"192.0.2.1","cidr":"192.0.2.0/24","asn":64496}],"tag":"cert","sources":["CertSpotter","Crtsh"]}
+{"name":"*.dev.example.com","domain":"example.com","addresses":[],"sources":["DNS"]}`,
+			domains: map[string]string{"www.example.com": "192.0.2.1", "dev.example.com": ""},
+			sources: map[string][]string{"www.example.com": {"amass:certspotter", "amass:crtsh"}, "dev.example.com": {"amass:dns"}},
+			parents: map[string]string{"www.example.com": "example.com"},
+		},
+		{
+			name:   "amass v4 graph",
+			format: FormatAuto,
+			input: `example.com (FQDN) --> node --> api.example.com (FQDN)
+api.example.com (FQDN) --> a_record --> 192.0.2.7 (IPAddress)
+192.0.2.0/24 (Netblock) --> contains --> 192.0.2.7 (IPAddress)`,
+			domains: map[string]string{"example.com": "", "api.example.com": "192.0.2.7"},
+			sources: map[string][]string{"api.example.com": {"amass"}},
+			parents: map[string]string{"api.example.com": "example.com"},
+		},
+		{
+			name:   "subfinder json",
+			format: FormatAuto,
+			input: `{"host":"mail.example.com","input":"example.com","source":"crtsh"}
+{"host":"mail.example.com","input":"example.com","source":"alienvault"}
+{"host":"MAIL.example.com","input":"example.com","source":"crtsh"}`,
+			domains: map[string]string{"mail.example.com": ""},
+			sources: map[string][]string{"mail.example.com": {"subfinder:crtsh", "subfinder:alienvault"}},
+			parents: map[string]string{"mail.example.com": "example.com"},
+		},
+		{
+			name:    "subfinder host and ip",
+			format:  FormatAuto,
+			input:   "a.example.com,192.0.2.10\nb.example.com,192.0.2.11,crtsh\n",
+			domains: map[string]string{"a.example.com": "192.0.2.10", "b.example.com": "192.0.2.11"},
+			sources: map[string][]string{"a.example.com": {"subfinder"}, "b.example.com": {"subfinder:crtsh"}},
+			parents: map[string]string{"a.example.com": "a.example.com"},
+		},
+		{
+			name:   "massdns simple",
+			format: FormatAuto,
+			input: `shop.example.com. A 192.0.2.20
+cdn.example.com. CNAME edge.cdn-provider.net.
+edge.cdn-provider.net. CNAME edge1.cdn-provider.net.
+edge1.cdn-provider.net. A 198.51.100.1`,
+			domains: map[string]string{"shop.example.com": "192.0.2.20", "cdn.example.com": "198.51.100.1"},
+			sources: map[string][]string{"shop.example.com": {"massdns"}, "cdn.example.com": {"massdns"}},
+		},
+		{
+			name:   "massdns json",
+			format: FormatMassDNS,
+			input: `{"name":"vpn.example.com.","type":"A","class":"IN","status":"NOERROR","data":{"answers":[{"ttl":300,"type":"CNAME","class":"IN","name":"vpn.example.com.","data":"gw.example.com."},{"ttl":300,"type":"A","class":"IN","name":"gw.example.com.","data":"192.0.2.30"}]}}
+{"name":"gone.example.com.","type":"A","class":"IN","status":"NXDOMAIN","data":{}}`,
+			domains: map[string]string{"vpn.example.com": "192.0.2.30"},
+			sources: map[string][]string{"vpn.example.com": {"massdns"}},
+		},
+		{
+			name:   "host list",
+			format: FormatAuto,
+			input: `# exported from the asset inventory
+https://portal.example.com/login
+Portal.Example.com
+10.0.0.1
+localhost
+not a domain!`,
+			domains: map[string]string{"portal.example.com": ""},
+			sources: map[string][]string{"portal.example.com": {"list"}},
+			parents: map[string]string{"portal.example.com": "portal.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(result.Domains) != len(tt.domains) {
+				t.Errorf("Expected %d domains, got %d: %v", len(tt.domains), len(result.Domains), result.Domains)
+			}
+			for domain, ip := range tt.domains {
+				entry, ok := result.Domains[domain]
+				if !ok {
+					t.Errorf("Expected %s to be imported", domain)
+					continue
+				}
+				if entry.IP != ip {
+					t.Errorf("Expected %s to have IP %q, got %q", domain, ip, entry.IP)
+				}
+				if entry.Reachable {
+					t.Errorf("Expected %s to be unverified", domain)
+				}
+				if len(entry.Seeds) == 0 {
+					t.Errorf("Expected %s to be tagged with a seed", domain)
+				}
+			}
+			for domain, names := range tt.sources {
+				entry := result.Domains[domain]
+				if entry == nil || len(entry.Sources) != len(names) {
+					t.Errorf("Expected %s to have sources %v, got %+v", domain, names, entry)
+					continue
+				}
+				for i, name := range names {
+					src := entry.Sources[i]
+					if src.Name != name || src.Type != "import" || src.Stage != StageImport || src.DiscoveredAt.IsZero() {
+						t.Errorf("Expected source %d of %s to be import source %s, got %+v", i, domain, name, src)
+					}
+				}
+			}
+			for domain, parent := range tt.parents {
+				if got := result.Domains[domain].Sources[0].Parent; got != parent {
+					t.Errorf("Expected %s to have parent %s, got %s", domain, parent, got)
+				}
+			}
+			if result.Statistics.TotalSubdomains != len(tt.domains) {
+				t.Errorf("Expected statistics to count %d domains, got %d", len(tt.domains), result.Statistics.TotalSubdomains)
+			}
+		})
+	}
+}
+
+func TestParseInternationalised(t *testing.T) {
+	result, err := Parse(strings.NewReader("https://Bücher.example/\nxn--bcher-kva.example\nshop.BÜCHER.example\n"), FormatAuto)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(result.Domains) != 2 {
+		t.Fatalf("Expected Unicode and punycode names to merge into 2 domains, got %v", result.Domains)
+	}
+	for domain, unicode := range map[string]string{"xn--bcher-kva.example": "bücher.example", "shop.xn--bcher-kva.example": "shop.bücher.example"} {
+		entry := result.Domains[domain]
+		if entry == nil {
+			t.Errorf("Expected %s to be imported", domain)
+			continue
+		}
+		if entry.UnicodeDomain != unicode {
+			t.Errorf("Expected %s to have Unicode form %q, got %q", domain, unicode, entry.UnicodeDomain)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"name":"a.example.com","domain":"example.com","sources":["DNS"]}`, FormatAmass},
+		{"a.example.com (FQDN) --> a_record --> 192.0.2.1 (IPAddress)", FormatAmass},
+		{`{"host":"a.example.com","input":"example.com","source":"crtsh"}`, FormatSubfinder},
+		{"a.example.com,192.0.2.1", FormatSubfinder},
+		{`{"name":"a.example.com.","status":"NOERROR","data":{}}`, FormatMassDNS},
+		{"a.example.com. A 192.0.2.1", FormatMassDNS},
+		{"\n# comment\na.example.com\n", FormatList},
+		{"", FormatList},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want+"/"+tt.input, func(t *testing.T) {
+			if got := Detect([]byte(tt.input)); got != tt.want {
+				t.Errorf("Detect(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(strings.NewReader("a.example.com"), "nmap"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	if _, err := Parse(strings.NewReader(`{"host":`), FormatSubfinder); err == nil {
+		t.Error("Expected an error for malformed JSON")
+	}
+	// Errors name the line of the file, counting blank and comment lines
+	input := "# subfinder -oJ\n\n" + `{"host":"a.example.com"}` + "\n" + `{"host":`
+	if _, err := Parse(strings.NewReader(input), FormatSubfinder); err == nil || !strings.Contains(err.Error(), "line 4:") {
+		t.Errorf("Expected an error on line 4, got %v", err)
+	}
+	if _, err := ParseFile(filepath.Join(t.TempDir(), "missing.txt"), FormatAuto); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+
+	path := filepath.Join(t.TempDir(), "subs.txt")
+	if err := os.WriteFile(path, []byte("a.example.com\nb.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ParseFile(path, "")
+	if err != nil || len(result.Domains) != 2 {
+		t.Errorf("Expected 2 domains from %s, got %v (%v)", path, result, err)
+	}
+}
@@ -3,6 +3,7 @@ package logging
 import (
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/gologger/levels"
+	"github.com/projectdiscovery/gologger/writer"
 )
 
 // InitLogger configures the global gologger based on log level string
@@ -31,3 +32,24 @@ func InitLogger(logLevel string) {
 func GetLogger() *gologger.Logger {
 	return gologger.DefaultLogger
 }
+
+// WrapOutput routes every log write through wrap, letting progress renderers clear
+// their status line before a log line is printed. A nil wrap restores plain output.
+func WrapOutput(wrap func(write func())) {
+	if wrap == nil {
+		gologger.DefaultLogger.SetWriter(writer.NewCLI())
+		return
+	}
+	gologger.DefaultLogger.SetWriter(&wrappedWriter{next: writer.NewCLI(), wrap: wrap})
+}
+
+// wrappedWriter is a gologger writer delegating to next inside wrap
+type wrappedWriter struct {
+	next writer.Writer
+	wrap func(write func())
+}
+
+// Write writes data to the underlying writer through wrap
+func (w *wrappedWriter) Write(data []byte, level levels.Level) {
+	w.wrap(func() { w.next.Write(data, level) })
+}
@@ -0,0 +1,254 @@
+// Package monitor rescans targets on an interval and reports what changed between runs
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/logging"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// Change event types
+const (
+	ChangeNewHost     = "new_host"     // Domain not present in the previous run
+	ChangeHostLive    = "host_live"    // Domain became reachable
+	ChangeHostDark    = "host_dark"    // Domain stopped being reachable
+	ChangeCertRotated = "cert_rotated" // Domain serves a different certificate
+	ChangeIPChanged   = "ip_changed"   // Domain resolves to a different IP
+)
+
+// DefaultInterval is the time between the start of consecutive runs
+const DefaultInterval = time.Hour
+
+// Change describes a difference for one domain between two runs
+type Change struct {
+	Type                string                 `json:"type"`
+	Domain              string                 `json:"domain"`
+	Time                time.Time              `json:"time"`                           // Start of the run that observed the change
+	Run                 int                    `json:"run,omitempty"`                  // Run number within the monitor process
+	URL                 string                 `json:"url,omitempty"`                  // Current URL, if verified
+	Status              int                    `json:"status,omitempty"`               // Current HTTP status
+	Reachable           bool                   `json:"reachable"`                      // Current reachability
+	PreviousIP          string                 `json:"previous_ip,omitempty"`          // ip_changed
+	IP                  string                 `json:"ip,omitempty"`                   // Current IP
+	PreviousCertificate *types.CertificateInfo `json:"previous_certificate,omitempty"` // cert_rotated
+	Certificate         *types.CertificateInfo `json:"certificate,omitempty"`          // Current certificate
+}
+
+// Diff compares two results and returns the changes, ordered by domain.
+// Domains missing from current are ignored: a single run failing to rediscover
+// a host is not evidence that it went away.
+func Diff(previous, current *domainscan.AssetDiscoveryResult, at time.Time) []Change {
+	var changes []Change
+	for name, entry := range current.Domains {
+		change := func(changeType string) Change {
+			return Change{
+				Type:        changeType,
+				Domain:      name,
+				Time:        at,
+				URL:         entry.URL,
+				Status:      entry.Status,
+				Reachable:   entry.Reachable,
+				IP:          entry.IP,
+				Certificate: entry.Certificate,
+			}
+		}
+
+		before, ok := previous.Domains[name]
+		if !ok {
+			changes = append(changes, change(ChangeNewHost))
+			continue
+		}
+		if entry.Reachable && !before.Reachable {
+			changes = append(changes, change(ChangeHostLive))
+		}
+		if !entry.Reachable && before.Reachable {
+			changes = append(changes, change(ChangeHostDark))
+		}
+		if before.Certificate != nil && entry.Certificate != nil && !sameCertificate(before.Certificate, entry.Certificate) {
+			c := change(ChangeCertRotated)
+			c.PreviousCertificate = before.Certificate
+			changes = append(changes, c)
+		}
+		if before.IP != "" && entry.IP != "" && before.IP != entry.IP {
+			c := change(ChangeIPChanged)
+			c.PreviousIP = before.IP
+			changes = append(changes, c)
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Domain != changes[j].Domain {
+			return changes[i].Domain < changes[j].Domain
+		}
+		return changes[i].Type < changes[j].Type
+	})
+	return changes
+}
+
+// baseline returns the result the next run is compared against: current plus the domains
+// of previous that current missed. Since Diff ignores missing domains, dropping them would
+// report a host that skipped one run as new when it reappears. current is not modified.
+func baseline(previous, current *domainscan.AssetDiscoveryResult) *domainscan.AssetDiscoveryResult {
+	if previous == nil {
+		return current
+	}
+	next := &domainscan.AssetDiscoveryResult{
+		Domains: make(map[string]*domainscan.DomainEntry, len(current.Domains)),
+		Errors:  current.Errors,
+	}
+	for name, entry := range previous.Domains {
+		next.Domains[name] = entry
+	}
+	for name, entry := range current.Domains {
+		next.Domains[name] = entry
+	}
+	next.UpdateStatistics()
+	return next
+}
+
+// sameCertificate reports whether two certificates are the same; untrusted flags are ignored
+func sameCertificate(a, b *types.CertificateInfo) bool {
+	return a.Subject == b.Subject && a.Issuer == b.Issuer &&
+		a.IssuedOn.Equal(b.IssuedOn) && a.ExpiresOn.Equal(b.ExpiresOn)
+}
+
+// ScanFunc performs one monitoring run
+type ScanFunc func(ctx context.Context) (*domainscan.AssetDiscoveryResult, error)
+
+// Options configures a Monitor
+type Options struct {
+	Interval  time.Duration // Time between the start of consecutive runs (default: DefaultInterval)
+	Scan      ScanFunc      // Performs a run
+	Sinks     []Sink        // Receive the changes of every run
+	StateFile string        // Keeps the last result so restarts compare against it (optional)
+	Runs      int           // Stop after this many runs (0 = until the context is cancelled)
+}
+
+// Monitor runs scans on an interval and emits the changes between consecutive runs.
+// The first run only establishes the baseline unless a state file from an earlier
+// monitor process exists.
+type Monitor struct {
+	opts   Options
+	logger *gologger.Logger
+}
+
+// New creates a monitor
+func New(opts Options) *Monitor {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	return &Monitor{opts: opts, logger: logging.GetLogger()}
+}
+
+// Run monitors until the context is cancelled or the configured number of runs is reached.
+// Failed runs and sink errors are logged and monitoring continues.
+func (m *Monitor) Run(ctx context.Context) error {
+	previous, err := m.loadState()
+	if err != nil {
+		return err
+	}
+
+	for run := 1; ; run++ {
+		started := time.Now()
+		current, err := m.opts.Scan(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		switch {
+		case err != nil:
+			m.logger.Warning().Msgf("Monitor run %d failed: %v", run, err)
+		case previous == nil:
+			m.logger.Info().Msgf("Monitor run %d: baseline of %d domains", run, len(current.Domains))
+		default:
+			changes := Diff(previous, current, started)
+			for i := range changes {
+				changes[i].Run = run
+			}
+			m.logger.Info().Msgf("Monitor run %d: %d domains, %d changes", run, len(current.Domains), len(changes))
+			if err := m.emit(ctx, changes); err != nil {
+				m.logger.Warning().Msgf("Monitor run %d: %v", run, err)
+			}
+		}
+		if err == nil {
+			previous = baseline(previous, current)
+			if err := m.saveState(previous); err != nil {
+				m.logger.Warning().Msgf("Monitor run %d: %v", run, err)
+			}
+		}
+
+		if m.opts.Runs > 0 && run >= m.opts.Runs {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Until(started.Add(m.opts.Interval))):
+		}
+	}
+}
+
+// emit sends changes to every sink, continuing past failing sinks
+func (m *Monitor) emit(ctx context.Context, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, sink := range m.opts.Sinks {
+		if err := sink.Send(ctx, changes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// loadState reads the previous result from the state file, if any
+func (m *Monitor) loadState() (*domainscan.AssetDiscoveryResult, error) {
+	if m.opts.StateFile == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(m.opts.StateFile); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return domainscan.LoadResult(m.opts.StateFile)
+}
+
+// saveState atomically replaces the state file with result
+func (m *Monitor) saveState(result *domainscan.AssetDiscoveryResult) error {
+	if m.opts.StateFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(struct {
+		Domains map[string]*domainscan.DomainEntry `json:"domains"`
+	}{result.Domains})
+	if err != nil {
+		return fmt.Errorf("failed to encode monitor state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.opts.StateFile), ".monitor-state-*")
+	if err != nil {
+		return fmt.Errorf("failed to write monitor state: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write monitor state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write monitor state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), m.opts.StateFile); err != nil {
+		return fmt.Errorf("failed to write monitor state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// result builds a discovery result from entries
+func result(entries ...*domainscan.DomainEntry) *domainscan.AssetDiscoveryResult {
+	r := &domainscan.AssetDiscoveryResult{Domains: make(map[string]*domainscan.DomainEntry)}
+	for _, entry := range entries {
+		r.Domains[entry.Domain] = entry
+	}
+	r.UpdateStatistics()
+	return r
+}
+
+// changeKeys formats changes as "type:domain" for comparison
+func changeKeys(changes []Change) string {
+	var keys []string
+	for _, change := range changes {
+		keys = append(keys, change.Type+":"+change.Domain)
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestDiff(t *testing.T) {
+	oldCert := &types.CertificateInfo{Subject: "CN=api.example.com", Issuer: "CN=CA", ExpiresOn: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	newCert := &types.CertificateInfo{Subject: "CN=api.example.com", Issuer: "CN=CA", ExpiresOn: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	previous := result(
+		&domainscan.DomainEntry{Domain: "api.example.com", Reachable: true, IP: "192.0.2.1", Certificate: oldCert},
+		&domainscan.DomainEntry{Domain: "dev.example.com", Reachable: false},
+		&domainscan.DomainEntry{Domain: "gone.example.com", Reachable: true},
+		&domainscan.DomainEntry{Domain: "www.example.com", Reachable: true, IP: "192.0.2.9", Certificate: oldCert},
+	)
+	current := result(
+		&domainscan.DomainEntry{Domain: "api.example.com", Reachable: true, IP: "192.0.2.2", Certificate: newCert},
+		&domainscan.DomainEntry{Domain: "dev.example.com", Reachable: true, URL: "https://dev.example.com", Status: 200},
+		&domainscan.DomainEntry{Domain: "new.example.com", Reachable: false},
+		&domainscan.DomainEntry{Domain: "www.example.com", Reachable: false, Certificate: &types.CertificateInfo{
+			Subject: oldCert.Subject, Issuer: oldCert.Issuer, ExpiresOn: oldCert.ExpiresOn, Untrusted: true,
+		}},
+	)
+
+	at := time.Now()
+	changes := Diff(previous, current, at)
+	expected := "cert_rotated:api.example.com,ip_changed:api.example.com,host_live:dev.example.com,new_host:new.example.com,host_dark:www.example.com"
+	if got := changeKeys(changes); got != expected {
+		t.Fatalf("Expected %s, got %s", expected, got)
+	}
+	if changes[0].PreviousCertificate != oldCert || changes[0].Certificate != newCert {
+		t.Errorf("Expected previous and current certificates on cert_rotated: %+v", changes[0])
+	}
+	if changes[1].PreviousIP != "192.0.2.1" || changes[1].IP != "192.0.2.2" {
+		t.Errorf("Expected previous and current IPs on ip_changed: %+v", changes[1])
+	}
+	if changes[2].URL != "https://dev.example.com" || !changes[2].Reachable || !changes[2].Time.Equal(at) {
+		t.Errorf("Expected current state on host_live: %+v", changes[2])
+	}
+
+	if changes := Diff(current, current, at); len(changes) != 0 {
+		t.Errorf("Expected no changes between identical runs, got %s", changeKeys(changes))
+	}
+}
+
+func TestMonitorRun(t *testing.T) {
+	var mu sync.Mutex
+	var posted []Change
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Changes []Change `json:"changes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		posted = append(posted, body.Changes...)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	dir := t.TempDir()
+	changesFile := filepath.Join(dir, "changes.jsonl")
+	stateFile := filepath.Join(dir, "state.json")
+
+	// Run 1 is the baseline, run 2 fails, run 3 finds a new host, run 4 sees it go live
+	runs := []*domainscan.AssetDiscoveryResult{
+		result(&domainscan.DomainEntry{Domain: "example.com", Reachable: true}),
+		nil,
+		result(&domainscan.DomainEntry{Domain: "example.com", Reachable: true}, &domainscan.DomainEntry{Domain: "api.example.com"}),
+		result(&domainscan.DomainEntry{Domain: "example.com", Reachable: true}, &domainscan.DomainEntry{Domain: "api.example.com", Reachable: true}),
+	}
+	calls := 0
+	scan := func(ctx context.Context) (*domainscan.AssetDiscoveryResult, error) {
+		r := runs[calls]
+		calls++
+		if r == nil {
+			return nil, errors.New("network down")
+		}
+		return r, nil
+	}
+
+	m := New(Options{
+		Interval:  time.Millisecond,
+		Scan:      scan,
+		Sinks:     []Sink{NewFileSink(changesFile), NewWebhookSink(webhook.URL)},
+		StateFile: stateFile,
+		Runs:      len(runs),
+	})
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(changesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+	var lines []Change
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var change Change
+		if err := json.Unmarshal(scanner.Bytes(), &change); err != nil {
+			t.Fatalf("invalid JSON line %s: %v", scanner.Text(), err)
+		}
+		lines = append(lines, change)
+	}
+	if got := changeKeys(lines); got != "new_host:api.example.com,host_live:api.example.com" {
+		t.Errorf("Unexpected file sink changes: %s", got)
+	}
+	if lines[0].Run != 3 || lines[1].Run != 4 {
+		t.Errorf("Expected run numbers 3 and 4, got %d and %d", lines[0].Run, lines[1].Run)
+	}
+	mu.Lock()
+	if got := changeKeys(posted); got != "new_host:api.example.com,host_live:api.example.com" {
+		t.Errorf("Unexpected webhook changes: %s", got)
+	}
+	mu.Unlock()
+
+	// A restarted monitor compares its first run against the saved state
+	var restarted []Change
+	m = New(Options{
+		Scan: func(ctx context.Context) (*domainscan.AssetDiscoveryResult, error) {
+			return result(&domainscan.DomainEntry{Domain: "example.com"}, &domainscan.DomainEntry{Domain: "api.example.com", Reachable: true}), nil
+		},
+		Sinks:     []Sink{sinkFunc(func(changes []Change) { restarted = append(restarted, changes...) })},
+		StateFile: stateFile,
+		Runs:      1,
+	})
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := changeKeys(restarted); got != "host_dark:example.com" {
+		t.Errorf("Expected the restarted monitor to diff against saved state, got %s", got)
+	}
+}
+
+func TestMonitorCarriesMissingHosts(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	// Runs A, A without api.example.com, A: the host skipped a run but never went away
+	full := result(&domainscan.DomainEntry{Domain: "example.com", Reachable: true}, &domainscan.DomainEntry{Domain: "api.example.com", Reachable: true})
+	runs := []*domainscan.AssetDiscoveryResult{
+		full,
+		result(&domainscan.DomainEntry{Domain: "example.com", Reachable: true}),
+		full,
+	}
+	calls := 0
+	var changes []Change
+	m := New(Options{
+		Interval: time.Millisecond,
+		Scan: func(ctx context.Context) (*domainscan.AssetDiscoveryResult, error) {
+			r := runs[calls]
+			calls++
+			return r, nil
+		},
+		Sinks:     []Sink{sinkFunc(func(c []Change) { changes = append(changes, c...) })},
+		StateFile: stateFile,
+		Runs:      len(runs),
+	})
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes, got %s", changeKeys(changes))
+	}
+
+	// The missing host stayed in the saved state while it was missing
+	runs, calls = runs[:2], 0
+	m = New(Options{Interval: time.Millisecond, Scan: m.opts.Scan, Sinks: m.opts.Sinks, StateFile: stateFile, Runs: len(runs)})
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	state, err := m.loadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := state.Domains["api.example.com"]; !ok || len(changes) != 0 {
+		t.Errorf("Expected the saved state to keep api.example.com, got %d domains and changes %s", len(state.Domains), changeKeys(changes))
+	}
+	if got := baseline(full, runs[1]); len(runs[1].Domains) != 1 || len(got.Domains) != 2 {
+		t.Errorf("baseline() should union the runs without modifying them, got %d domains", len(got.Domains))
+	}
+}
+
+func TestMonitorStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := New(Options{
+		Interval: time.Hour,
+		Scan: func(ctx context.Context) (*domainscan.AssetDiscoveryResult, error) {
+			cancel()
+			return result(), nil
+		},
+	})
+	done := make(chan error)
+	go func() { done <- m.Run(ctx) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected nil error on cancellation, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Monitor did not stop after cancellation")
+	}
+}
+
+func TestWebhookSinkError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookSink(srv.URL).Send(context.Background(), []Change{{Type: ChangeNewHost, Domain: "example.com"}})
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected error for a 500 response, got %v", err)
+	}
+}
+
+func TestParseSink(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{spec: "-", expected: "*monitor.WriterSink"},
+		{spec: "stdout", expected: "*monitor.WriterSink"},
+		{spec: "https://hooks.example.com/abc", expected: "*monitor.WebhookSink"},
+		{spec: "changes.jsonl", expected: "*monitor.FileSink"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			sink, err := ParseSink(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprintf("%T", sink); got != tt.expected {
+				t.Errorf("ParseSink(%q) = %s, want %s", tt.spec, got, tt.expected)
+			}
+		})
+	}
+	if _, err := ParseSink(""); err == nil {
+		t.Error("Expected error for an empty sink")
+	}
+}
+
+// sinkFunc adapts a function to Sink
+type sinkFunc func(changes []Change)
+
+func (f sinkFunc) Send(ctx context.Context, changes []Change) error {
+	f(changes)
+	return nil
+}
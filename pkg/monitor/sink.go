@@ -0,0 +1,130 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink receives the changes of a monitoring run
+type Sink interface {
+	Send(ctx context.Context, changes []Change) error
+}
+
+// WriterSink writes changes as JSON lines to a writer such as stdout
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a sink writing JSON lines to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Send writes one JSON line per change
+func (s *WriterSink) Send(ctx context.Context, changes []Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeJSONLines(s.w, changes)
+}
+
+// FileSink appends changes as JSON lines to a file, opening it for each run so the
+// file can be rotated while the monitor is running
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSink creates a sink appending JSON lines to path
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Send appends one JSON line per change
+func (s *FileSink) Send(ctx context.Context, changes []Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600) // #nosec G304 - user supplied sink path
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", s.path, err)
+	}
+	if err := writeJSONLines(file, changes); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	return file.Close()
+}
+
+// WebhookSink posts the changes of each run as a JSON object {"changes": [...]}
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a sink posting to url
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Send posts the changes; any non-2xx response is an error
+func (s *WebhookSink) Send(ctx context.Context, changes []Change) error {
+	body, err := json.Marshal(struct {
+		Changes []Change `json:"changes"`
+	}{changes})
+	if err != nil {
+		return fmt.Errorf("failed to encode changes: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "domain-scan")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// ParseSink creates a sink from a spec: "-" or "stdout" for stdout, an http(s) URL
+// for a webhook, and anything else as a file path
+func ParseSink(spec string) (Sink, error) {
+	switch {
+	case spec == "":
+		return nil, fmt.Errorf("empty sink")
+	case spec == "-" || spec == "stdout":
+		return NewWriterSink(os.Stdout), nil
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		return NewWebhookSink(spec), nil
+	default:
+		return NewFileSink(spec), nil
+	}
+}
+
+// writeJSONLines encodes each change on its own line
+func writeJSONLines(w io.Writer, changes []Change) error {
+	encoder := json.NewEncoder(w)
+	for _, change := range changes {
+		if err := encoder.Encode(change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+// Package notify sends scan notifications to webhooks and chat services
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/output"
+)
+
+// Event types that trigger notifications
+const (
+	EventScanCompleted = "scan_completed" // Every finished scan
+	EventNewLiveHosts  = "new_live_hosts" // Hosts live now but not live in the previous result
+	EventFindings      = "findings"       // Certificate or takeover findings not in the previous result
+)
+
+// Notifier types
+const (
+	TypeWebhook = "webhook" // Generic JSON webhook, optionally with a templated body
+	TypeSlack   = "slack"   // Slack-compatible incoming webhook
+	TypeTeams   = "teams"   // Microsoft Teams workflow webhook (Adaptive Card)
+)
+
+// Defaults applied to zero-valued Config fields
+const (
+	DefaultRetries = 3
+	DefaultBackoff = time.Second
+	DefaultTimeout = 10 * time.Second
+)
+
+// Config configures notifications, read from the "notifications" config section
+type Config struct {
+	DryRun    bool             `yaml:"dry_run" json:"dry_run" mapstructure:"dry_run"`       // Print payloads instead of sending them
+	Retries   int              `yaml:"retries" json:"retries" mapstructure:"retries"`       // Retries after a failed attempt (default 3, -1 disables)
+	Backoff   time.Duration    `yaml:"backoff" json:"backoff" mapstructure:"backoff"`       // Delay before the first retry, doubled for each retry (default 1s)
+	Timeout   time.Duration    `yaml:"timeout" json:"timeout" mapstructure:"timeout"`       // Per-request timeout (default 10s)
+	Notifiers []NotifierConfig `yaml:"notifiers" json:"notifiers" mapstructure:"notifiers"` // Destinations
+}
+
+// NotifierConfig configures a single destination
+type NotifierConfig struct {
+	Name     string            `yaml:"name" json:"name" mapstructure:"name"`             // Label used in logs (default: type)
+	Type     string            `yaml:"type" json:"type" mapstructure:"type"`             // webhook, slack or teams
+	URL      string            `yaml:"url" json:"url" mapstructure:"url"`                // Endpoint to POST to
+	Events   []string          `yaml:"events" json:"events" mapstructure:"events"`       // Events to send (default: all)
+	Template string            `yaml:"template" json:"template" mapstructure:"template"` // Go template for webhook bodies (default: the event as JSON)
+	Headers  map[string]string `yaml:"headers" json:"headers" mapstructure:"headers"`    // Extra request headers, e.g. Authorization
+}
+
+// Event is a notification about a finished scan
+type Event struct {
+	Type       string                     `json:"type"`
+	Time       time.Time                  `json:"time"`
+	Targets    []string                   `json:"targets"`
+	Summary    string                     `json:"summary"`              // One-line human-readable description
+	Statistics *domainscan.DiscoveryStats `json:"statistics,omitempty"` // scan_completed
+	Hosts      []*domainscan.DomainEntry  `json:"hosts,omitempty"`      // new_live_hosts
+	Findings   []output.Finding           `json:"findings,omitempty"`   // findings
+}
+
+// Events builds the notifications for a finished scan. New live hosts are those reachable
+// in result but not in previous, and new findings are those previous didn't have when it
+// was probed; without a previous result every live host and finding is new.
+func Events(targets []string, result, previous *domainscan.AssetDiscoveryResult, now time.Time) []Event {
+	stats := result.Statistics
+	events := []Event{{
+		Type:       EventScanCompleted,
+		Time:       now,
+		Targets:    targets,
+		Summary:    fmt.Sprintf("Scan of %s completed: %d domains, %d live", strings.Join(targets, ", "), stats.TotalSubdomains, stats.ActiveServices),
+		Statistics: &stats,
+	}}
+
+	var hosts []*domainscan.DomainEntry
+	for name, entry := range result.Domains {
+		if !entry.Reachable {
+			continue
+		}
+		if previous != nil {
+			if before, ok := previous.Domains[name]; ok && before.Reachable {
+				continue
+			}
+		}
+		hosts = append(hosts, entry)
+	}
+	if len(hosts) > 0 {
+		sort.Slice(hosts, func(i, j int) bool { return hosts[i].Domain < hosts[j].Domain })
+		events = append(events, Event{
+			Type:    EventNewLiveHosts,
+			Time:    now,
+			Targets: targets,
+			Summary: fmt.Sprintf("%d new live hosts for %s", len(hosts), strings.Join(targets, ", ")),
+			Hosts:   hosts,
+		})
+	}
+
+	if findings := newFindings(result, previous, now); len(findings) > 0 {
+		events = append(events, Event{
+			Type:     EventFindings,
+			Time:     now,
+			Targets:  targets,
+			Summary:  fmt.Sprintf("%d findings for %s", len(findings), strings.Join(targets, ", ")),
+			Findings: findings,
+		})
+	}
+	return events
+}
+
+// newFindings returns the findings of result that previous didn't have. Previous findings
+// are evaluated at the latest probe time of previous, so certificates that started expiring
+// since then are reported.
+func newFindings(result, previous *domainscan.AssetDiscoveryResult, now time.Time) []output.Finding {
+	findings := output.Findings(result, now)
+	if previous == nil {
+		return findings
+	}
+
+	var probedAt time.Time
+	for _, entry := range previous.Domains {
+		if entry.ProbedAt.After(probedAt) {
+			probedAt = entry.ProbedAt
+		}
+	}
+	if probedAt.IsZero() {
+		probedAt = now
+	}
+	seen := make(map[string]bool)
+	for _, finding := range output.Findings(previous, probedAt) {
+		seen[finding.RuleID+" "+finding.Domain] = true
+	}
+
+	var fresh []output.Finding
+	for _, finding := range findings {
+		if !seen[finding.RuleID+" "+finding.Domain] {
+			fresh = append(fresh, finding)
+		}
+	}
+	return fresh
+}
+
+// Dispatcher delivers events to the configured notifiers
+type Dispatcher struct {
+	config    Config
+	notifiers []*notifier
+	client    *http.Client
+	out       io.Writer                                  // Dry-run output
+	sleep     func(context.Context, time.Duration) error // Replaced in tests
+}
+
+// New validates config and creates a dispatcher. Dry-run payloads are printed to stderr.
+func New(config Config) (*Dispatcher, error) {
+	if config.Retries == 0 {
+		config.Retries = DefaultRetries
+	} else if config.Retries < 0 {
+		config.Retries = 0
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = DefaultBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	d := &Dispatcher{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		out:    os.Stderr,
+		sleep:  sleepContext,
+	}
+	for i, nc := range config.Notifiers {
+		n, err := newNotifier(nc)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %d: %w", i+1, err)
+		}
+		d.notifiers = append(d.notifiers, n)
+	}
+	return d, nil
+}
+
+// SetOutput sets where dry-run payloads are printed
+func (d *Dispatcher) SetOutput(w io.Writer) {
+	d.out = w
+}
+
+// Enabled reports whether any notifier is configured
+func (d *Dispatcher) Enabled() bool {
+	return len(d.notifiers) > 0
+}
+
+// Notify sends event to every notifier subscribed to its type. All notifiers are
+// attempted; the returned error joins the failures.
+func (d *Dispatcher) Notify(ctx context.Context, event Event) error {
+	var errs []error
+	for _, n := range d.notifiers {
+		if !n.wants(event.Type) {
+			continue
+		}
+		payload, err := n.payload(event)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.name, err))
+			continue
+		}
+		if d.config.DryRun {
+			fmt.Fprintf(d.out, "[dry-run] %s (%s) %s -> %s\n%s\n", n.name, n.config.Type, event.Type, redactURL(n.config.URL), payload)
+			continue
+		}
+		if err := d.send(ctx, n, payload); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send posts payload, retrying network errors, 429 and 5xx responses with exponential backoff.
+// Retry-After is honoured up to Timeout times the number of retries.
+func (d *Dispatcher) send(ctx context.Context, n *notifier, payload []byte) error {
+	backoff := d.config.Backoff
+	maxRetryAfter := d.config.Timeout * time.Duration(max(d.config.Retries, 1))
+	var lastErr error
+	for attempt := 0; attempt <= d.config.Retries; attempt++ {
+		if attempt > 0 {
+			if err := d.sleep(ctx, backoff); err != nil {
+				return err
+			}
+			backoff *= 2
+		}
+
+		retryAfter, err := d.post(ctx, n, payload)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return err
+		}
+		if retryAfter > backoff {
+			backoff = min(retryAfter, maxRetryAfter)
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", d.config.Retries+1, lastErr)
+}
+
+// permanentError is a failure that retrying won't fix, such as a 4xx response
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// post performs a single delivery attempt, returning the server's Retry-After delay if any
+func (d *Dispatcher) post(ctx context.Context, n *notifier, payload []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, &permanentError{withoutURL(err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "domain-scan")
+	for name, value := range n.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", withoutURL(err))
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return time.Duration(seconds) * time.Second, fmt.Errorf("server returned %s", resp.Status)
+	default:
+		return 0, &permanentError{fmt.Errorf("server returned %s", resp.Status)}
+	}
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// withoutURL drops the request URL that *url.Error adds to its message, keeping webhook secrets out of errors
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// redactURL hides the path and query of webhook URLs, which usually embed secrets
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "<invalid URL>"
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		return parsed.Scheme + "://" + parsed.Host
+	}
+	return parsed.Scheme + "://" + parsed.Host + "/…"
+}
@@ -0,0 +1,372 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// recorder is a test server recording request bodies and answering with a scripted status sequence
+type recorder struct {
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	statuses []int // Status per request; the last one repeats
+	server   *httptest.Server
+}
+
+func newRecorder(t *testing.T, statuses ...int) *recorder {
+	r := &recorder{statuses: statuses}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.bodies = append(r.bodies, string(body))
+		r.headers = append(r.headers, req.Header.Clone())
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status = r.statuses[min(len(r.bodies), len(r.statuses))-1]
+		}
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "2")
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *recorder) requests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.bodies...)
+}
+
+// newTestDispatcher creates a dispatcher whose retries don't sleep, recording the delays
+func newTestDispatcher(t *testing.T, config Config) (*Dispatcher, *[]time.Duration) {
+	t.Helper()
+	d, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var delays []time.Duration
+	d.sleep = func(ctx context.Context, delay time.Duration) error {
+		delays = append(delays, delay)
+		return ctx.Err()
+	}
+	return d, &delays
+}
+
+// testResult builds a result with one live host and one expired certificate
+func testResult(now time.Time) *domainscan.AssetDiscoveryResult {
+	result := &domainscan.AssetDiscoveryResult{Domains: map[string]*domainscan.DomainEntry{
+		"www.example.com": {Domain: "www.example.com", URL: "https://www.example.com", Status: 200, Reachable: true},
+		"api.example.com": {Domain: "api.example.com", URL: "https://api.example.com", Status: 403, Reachable: true,
+			Certificate: &types.CertificateInfo{Subject: "CN=api.example.com", ExpiresOn: now.AddDate(0, 0, -2)}},
+		"dev.example.com": {Domain: "dev.example.com"},
+	}}
+	result.UpdateStatistics()
+	return result
+}
+
+func TestEvents(t *testing.T) {
+	now := time.Now()
+	result := testResult(now)
+
+	events := Events([]string{"example.com"}, result, nil, now)
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+	if events[0].Type != EventScanCompleted || events[0].Statistics.TotalSubdomains != 3 {
+		t.Errorf("Unexpected completion event: %+v", events[0])
+	}
+	if events[1].Type != EventNewLiveHosts || len(events[1].Hosts) != 2 || events[1].Hosts[0].Domain != "api.example.com" {
+		t.Errorf("Expected both live hosts, sorted, without a previous result: %+v", events[1])
+	}
+	if events[2].Type != EventFindings || len(events[2].Findings) != 1 || events[2].Findings[0].Domain != "api.example.com" {
+		t.Errorf("Expected the expired certificate finding: %+v", events[2])
+	}
+
+	// Hosts already live in the previous result aren't new
+	previous := &domainscan.AssetDiscoveryResult{Domains: map[string]*domainscan.DomainEntry{
+		"www.example.com": {Domain: "www.example.com", Reachable: true},
+		"api.example.com": {Domain: "api.example.com", Reachable: false},
+	}}
+	events = Events([]string{"example.com"}, result, previous, now)
+	if events[1].Type != EventNewLiveHosts || len(events[1].Hosts) != 1 || events[1].Hosts[0].Domain != "api.example.com" {
+		t.Errorf("Expected only api.example.com as a new live host: %+v", events[1])
+	}
+
+	events = Events([]string{"example.com"}, result, result, now)
+	for _, event := range events {
+		if event.Type == EventNewLiveHosts || event.Type == EventFindings {
+			t.Errorf("Expected no new live hosts or findings when nothing changed: %+v", event)
+		}
+	}
+
+	// A certificate that started expiring since the previous probe is a new finding; one
+	// that had already expired then is not
+	expiring := testResult(now)
+	expiring.Domains["api.example.com"].Certificate.ExpiresOn = now.AddDate(0, -2, 0)
+	expiring.Domains["www.example.com"].Certificate = &types.CertificateInfo{Subject: "CN=www.example.com", ExpiresOn: now.AddDate(0, 0, 10)}
+	probed := testResult(now)
+	probed.Domains["api.example.com"].Certificate = expiring.Domains["api.example.com"].Certificate
+	probed.Domains["www.example.com"].Certificate = expiring.Domains["www.example.com"].Certificate
+	probed.Domains["www.example.com"].ProbedAt = now.AddDate(0, -1, 0)
+	events = Events([]string{"example.com"}, expiring, probed, now)
+	if last := events[len(events)-1]; last.Type != EventFindings || len(last.Findings) != 1 || last.Findings[0].Domain != "www.example.com" {
+		t.Errorf("Expected only the newly expiring certificate as a finding: %+v", last)
+	}
+}
+
+func TestNotifyPayloads(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	event := Events([]string{"example.com"}, testResult(now), nil, now)[1]
+
+	tests := []struct {
+		name   string
+		config NotifierConfig
+		check  func(t *testing.T, body string)
+	}{
+		{
+			name:   "webhook",
+			config: NotifierConfig{Type: TypeWebhook},
+			check: func(t *testing.T, body string) {
+				var got Event
+				if err := json.Unmarshal([]byte(body), &got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Type != EventNewLiveHosts || len(got.Hosts) != 2 {
+					t.Errorf("Expected the event as JSON, got %s", body)
+				}
+			},
+		},
+		{
+			name:   "template",
+			config: NotifierConfig{Type: TypeWebhook, Template: `{"kind": {{json .Type}}, "hosts": [{{range $i, $h := .Hosts}}{{if $i}},{{end}}{{json $h.URL}}{{end}}]}`},
+			check: func(t *testing.T, body string) {
+				expected := `{"kind": "new_live_hosts", "hosts": ["https://api.example.com","https://www.example.com"]}`
+				if body != expected {
+					t.Errorf("Expected %s, got %s", expected, body)
+				}
+			},
+		},
+		{
+			name:   "slack",
+			config: NotifierConfig{Type: TypeSlack},
+			check: func(t *testing.T, body string) {
+				var got struct {
+					Text string `json:"text"`
+				}
+				if err := json.Unmarshal([]byte(body), &got); err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(got.Text, "2 new live hosts") || !strings.Contains(got.Text, "• https://api.example.com (403)") {
+					t.Errorf("Unexpected Slack text: %q", got.Text)
+				}
+			},
+		},
+		{
+			name:   "teams",
+			config: NotifierConfig{Type: TypeTeams},
+			check: func(t *testing.T, body string) {
+				var got struct {
+					Type        string `json:"type"`
+					Attachments []struct {
+						ContentType string `json:"contentType"`
+						Content     struct {
+							Type string `json:"type"`
+							Body []struct {
+								Text string `json:"text"`
+							} `json:"body"`
+						} `json:"content"`
+					} `json:"attachments"`
+				}
+				if err := json.Unmarshal([]byte(body), &got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Type != "message" || len(got.Attachments) != 1 || got.Attachments[0].Content.Type != "AdaptiveCard" {
+					t.Fatalf("Expected an Adaptive Card message, got %s", body)
+				}
+				if card := got.Attachments[0].Content; len(card.Body) != 4 || !strings.Contains(card.Body[1].Text, "2 new live hosts") {
+					t.Errorf("Unexpected card body: %s", body)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := newRecorder(t)
+			tt.config.URL = rec.server.URL
+			tt.config.Headers = map[string]string{"Authorization": "Bearer secret"}
+			d, _ := newTestDispatcher(t, Config{Notifiers: []NotifierConfig{tt.config}})
+			if err := d.Notify(context.Background(), event); err != nil {
+				t.Fatal(err)
+			}
+			bodies := rec.requests()
+			if len(bodies) != 1 {
+				t.Fatalf("Expected 1 request, got %d", len(bodies))
+			}
+			if got := rec.headers[0].Get("Authorization"); got != "Bearer secret" {
+				t.Errorf("Expected the configured header, got %q", got)
+			}
+			if got := rec.headers[0].Get("Content-Type"); got != "application/json" {
+				t.Errorf("Expected a JSON content type, got %q", got)
+			}
+			tt.check(t, bodies[0])
+		})
+	}
+}
+
+func TestNotifyRetries(t *testing.T) {
+	event := Event{Type: EventScanCompleted, Summary: "done"}
+
+	t.Run("retries server errors with backoff", func(t *testing.T) {
+		rec := newRecorder(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+		d, delays := newTestDispatcher(t, Config{Backoff: 100 * time.Millisecond, Notifiers: []NotifierConfig{{Type: TypeWebhook, URL: rec.server.URL}}})
+		if err := d.Notify(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(rec.requests()); got != 3 {
+			t.Errorf("Expected 3 attempts, got %d", got)
+		}
+		// The second delay honours Retry-After: 2 over the doubled backoff
+		if len(*delays) != 2 || (*delays)[0] != 100*time.Millisecond || (*delays)[1] != 2*time.Second {
+			t.Errorf("Unexpected retry delays: %v", *delays)
+		}
+	})
+
+	t.Run("caps Retry-After", func(t *testing.T) {
+		rec := newRecorder(t, http.StatusTooManyRequests, http.StatusOK)
+		d, delays := newTestDispatcher(t, Config{Retries: 3, Timeout: 500 * time.Millisecond, Notifiers: []NotifierConfig{{Type: TypeWebhook, URL: rec.server.URL}}})
+		if err := d.Notify(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+		// Retry-After: 2 is capped at Timeout times Retries
+		if len(*delays) != 1 || (*delays)[0] != 1500*time.Millisecond {
+			t.Errorf("Unexpected retry delays: %v", *delays)
+		}
+	})
+
+	t.Run("gives up after the configured retries", func(t *testing.T) {
+		rec := newRecorder(t, http.StatusBadGateway)
+		d, _ := newTestDispatcher(t, Config{Retries: 2, Notifiers: []NotifierConfig{{Type: TypeWebhook, URL: rec.server.URL}}})
+		err := d.Notify(context.Background(), event)
+		if err == nil || !strings.Contains(err.Error(), "after 3 attempts") || !strings.Contains(err.Error(), "502") {
+			t.Errorf("Expected failure after 3 attempts, got %v", err)
+		}
+		if got := len(rec.requests()); got != 3 {
+			t.Errorf("Expected 3 attempts, got %d", got)
+		}
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		rec := newRecorder(t, http.StatusBadRequest)
+		d, _ := newTestDispatcher(t, Config{Notifiers: []NotifierConfig{{Type: TypeWebhook, URL: rec.server.URL}}})
+		if err := d.Notify(context.Background(), event); err == nil || !strings.Contains(err.Error(), "400") {
+			t.Errorf("Expected a 400 error, got %v", err)
+		}
+		if got := len(rec.requests()); got != 1 {
+			t.Errorf("Expected a single attempt, got %d", got)
+		}
+	})
+
+	t.Run("keeps the URL out of errors", func(t *testing.T) {
+		rec := newRecorder(t)
+		rec.server.Close()
+		d, _ := newTestDispatcher(t, Config{Retries: 0, Notifiers: []NotifierConfig{{Type: TypeSlack, URL: rec.server.URL + "/services/T000/B000/secret"}}})
+		err := d.Notify(context.Background(), event)
+		if err == nil || !strings.Contains(err.Error(), "request failed") || strings.Contains(err.Error(), "secret") {
+			t.Errorf("Expected a redacted request error, got %v", err)
+		}
+	})
+
+	t.Run("one failing notifier doesn't stop the others", func(t *testing.T) {
+		failing := newRecorder(t, http.StatusNotFound)
+		working := newRecorder(t)
+		d, _ := newTestDispatcher(t, Config{Notifiers: []NotifierConfig{
+			{Name: "broken", Type: TypeSlack, URL: failing.server.URL},
+			{Type: TypeTeams, URL: working.server.URL},
+		}})
+		err := d.Notify(context.Background(), event)
+		if err == nil || !strings.HasPrefix(err.Error(), "broken: ") {
+			t.Errorf("Expected an error naming the failing notifier, got %v", err)
+		}
+		if got := len(working.requests()); got != 1 {
+			t.Errorf("Expected the working notifier to be called, got %d requests", got)
+		}
+	})
+}
+
+func TestNotifyEventFilterAndDryRun(t *testing.T) {
+	rec := newRecorder(t)
+	d, _ := newTestDispatcher(t, Config{Notifiers: []NotifierConfig{
+		{Type: TypeWebhook, URL: rec.server.URL, Events: []string{EventFindings}},
+	}})
+	if err := d.Notify(context.Background(), Event{Type: EventScanCompleted}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Notify(context.Background(), Event{Type: EventFindings}); err != nil {
+		t.Fatal(err)
+	}
+	if bodies := rec.requests(); len(bodies) != 1 || !strings.Contains(bodies[0], `"type":"findings"`) {
+		t.Errorf("Expected only the subscribed event to be sent, got %v", bodies)
+	}
+
+	dry := newRecorder(t)
+	d, _ = newTestDispatcher(t, Config{DryRun: true, Notifiers: []NotifierConfig{{Type: TypeSlack, URL: dry.server.URL + "/services/T000/B000/secret"}}})
+	var out bytes.Buffer
+	d.SetOutput(&out)
+	if err := d.Notify(context.Background(), Event{Type: EventScanCompleted, Summary: "Scan done"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.requests()) != 0 {
+		t.Error("Expected no requests in dry-run mode")
+	}
+	if got := out.String(); !strings.Contains(got, "[dry-run] slack (slack) scan_completed") || !strings.Contains(got, "Scan done") || strings.Contains(got, "secret") {
+		t.Errorf("Unexpected dry-run output: %s", got)
+	}
+}
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   NotifierConfig
+		expected string
+	}{
+		{name: "unknown type", config: NotifierConfig{Type: "email", URL: "https://example.com"}, expected: "unknown type"},
+		{name: "missing URL", config: NotifierConfig{Type: TypeSlack}, expected: "invalid URL"},
+		{name: "redacted URL", config: NotifierConfig{Type: TypeSlack, URL: "ftp://hooks.example.com/services/secret"}, expected: "invalid URL ftp://hooks.example.com/…"},
+		{name: "unknown event", config: NotifierConfig{Type: TypeSlack, URL: "https://example.com", Events: []string{"done"}}, expected: "unknown event"},
+		{name: "template on slack", config: NotifierConfig{Type: TypeSlack, URL: "https://example.com", Template: "{}"}, expected: "only supported by webhook"},
+		{name: "bad template", config: NotifierConfig{Type: TypeWebhook, URL: "https://example.com", Template: "{{.Type"}, expected: "invalid template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{Notifiers: []NotifierConfig{tt.config}})
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	// Templates must render valid JSON
+	rec := newRecorder(t)
+	d, _ := newTestDispatcher(t, Config{Notifiers: []NotifierConfig{{Type: TypeWebhook, URL: rec.server.URL, Template: `{"text": {{.Summary}}}`}}})
+	if err := d.Notify(context.Background(), Event{Summary: "unquoted"}); err == nil || !strings.Contains(err.Error(), "invalid JSON") {
+		t.Errorf("Expected an invalid JSON error, got %v", err)
+	}
+	if len(rec.requests()) != 0 {
+		t.Error("Expected no request for an invalid payload")
+	}
+}
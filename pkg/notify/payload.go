@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// maxListed caps how many hosts or findings chat messages list
+const maxListed = 10
+
+// notifier is a validated destination with its parsed body template
+type notifier struct {
+	name     string
+	config   NotifierConfig
+	template *template.Template
+}
+
+// newNotifier validates a notifier configuration
+func newNotifier(config NotifierConfig) (*notifier, error) {
+	config.Type = strings.ToLower(config.Type)
+	switch config.Type {
+	case TypeWebhook, TypeSlack, TypeTeams:
+	default:
+		return nil, fmt.Errorf("unknown type %q: must be webhook, slack or teams", config.Type)
+	}
+	parsed, err := url.Parse(config.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid URL %s: must be an http(s) URL", redactURL(config.URL))
+	}
+	for _, event := range config.Events {
+		if event != EventScanCompleted && event != EventNewLiveHosts && event != EventFindings {
+			return nil, fmt.Errorf("unknown event %q: must be %s, %s or %s", event, EventScanCompleted, EventNewLiveHosts, EventFindings)
+		}
+	}
+
+	n := &notifier{name: config.Name, config: config}
+	if n.name == "" {
+		n.name = config.Type
+	}
+	if config.Template != "" {
+		if config.Type != TypeWebhook {
+			return nil, fmt.Errorf("templates are only supported by webhook notifiers")
+		}
+		n.template, err = template.New(n.name).Funcs(template.FuncMap{"json": toJSON}).Parse(config.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+	}
+	return n, nil
+}
+
+// wants reports whether the notifier is subscribed to an event type
+func (n *notifier) wants(eventType string) bool {
+	return len(n.config.Events) == 0 || slices.Contains(n.config.Events, eventType)
+}
+
+// payload renders the request body for event
+func (n *notifier) payload(event Event) ([]byte, error) {
+	switch n.config.Type {
+	case TypeSlack:
+		return json.Marshal(map[string]string{"text": slackText(event)})
+	case TypeTeams:
+		return json.Marshal(teamsCard(event))
+	}
+
+	if n.template == nil {
+		return json.Marshal(event)
+	}
+	var buf bytes.Buffer
+	if err := n.template.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("template failed: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("template produced invalid JSON: %s", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// toJSON is the "json" template function, encoding a value for embedding in a JSON body
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// details returns one line per listed host or finding, capped at maxListed
+func details(event Event) []string {
+	var lines []string
+	for _, host := range event.Hosts {
+		line := host.Domain
+		if host.URL != "" {
+			line = fmt.Sprintf("%s (%d)", host.URL, host.Status)
+		}
+		lines = append(lines, line)
+	}
+	for _, finding := range event.Findings {
+		lines = append(lines, fmt.Sprintf("[%s] %s", finding.Level, finding.Message))
+	}
+	if len(lines) > maxListed {
+		lines = append(lines[:maxListed], fmt.Sprintf("… and %d more", len(lines)-maxListed))
+	}
+	return lines
+}
+
+// slackText formats event as Slack mrkdwn
+func slackText(event Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*domain-scan*: %s", event.Summary)
+	for _, line := range details(event) {
+		fmt.Fprintf(&b, "\n• %s", line)
+	}
+	return b.String()
+}
+
+// teamsCard wraps event in an Adaptive Card message as accepted by Teams workflow webhooks
+func teamsCard(event Event) map[string]interface{} {
+	body := []map[string]interface{}{
+		{"type": "TextBlock", "text": "domain-scan", "weight": "Bolder", "size": "Medium"},
+		{"type": "TextBlock", "text": event.Summary, "wrap": true},
+	}
+	for _, line := range details(event) {
+		body = append(body, map[string]interface{}{"type": "TextBlock", "text": "- " + line, "wrap": true, "spacing": "None"})
+	}
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]interface{}{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
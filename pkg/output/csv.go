@@ -0,0 +1,62 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+// csvHeader lists the CSV columns in output order
+var csvHeader = []string{
+	"domain", "url", "status", "reachable", "ip", "redirects_to", "sources",
+	"cert_subject", "cert_issuer", "cert_issued_on", "cert_expires_on",
+}
+
+// formatCSV writes one row per domain entry, sorted by domain
+func formatCSV(w io.Writer, result *domainscan.AssetDiscoveryResult) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, entry := range sortedEntries(result) {
+		var subject, issuer, issuedOn, expiresOn string
+		if entry.Certificate != nil {
+			subject = entry.Certificate.Subject
+			issuer = entry.Certificate.Issuer
+			issuedOn = formatTime(entry.Certificate.IssuedOn)
+			expiresOn = formatTime(entry.Certificate.ExpiresOn)
+		}
+		row := []string{
+			entry.Domain,
+			entry.URL,
+			strconv.Itoa(entry.Status),
+			strconv.FormatBool(entry.Reachable),
+			entry.IP,
+			redirectTarget(entry),
+			sourceNames(entry, ";"),
+			subject,
+			issuer,
+			issuedOn,
+			expiresOn,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row for %s: %w", entry.Domain, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatTime formats a timestamp as RFC 3339, or empty for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,136 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// Graph node and edge types
+const (
+	NodeDomain      = "domain"
+	NodeIP          = "ip"
+	NodeCertificate = "certificate"
+
+	EdgeResolvesTo    = "resolves-to"
+	EdgePresentedCert = "presented-cert"
+	EdgeSANOf         = "san-of"
+	EdgeRedirectsTo   = "redirects-to"
+)
+
+// Graph is an asset graph of domains, IPs and certificates
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a domain, IP or certificate in the asset graph
+type GraphNode struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	Label      string            `json:"label"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// GraphEdge is a directed relationship between two nodes
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+// BuildGraph derives the asset graph from domain entries: domains resolve to IPs,
+// present certificates, appear as SANs of certificates and redirect to other domains
+func BuildGraph(result *domainscan.AssetDiscoveryResult) *Graph {
+	nodes := make(map[string]GraphNode)
+	edges := make(map[GraphEdge]bool)
+
+	addDomain := func(domain string) string {
+		id := NodeDomain + ":" + domain
+		if _, exists := nodes[id]; !exists {
+			nodes[id] = GraphNode{ID: id, Type: NodeDomain, Label: domain}
+		}
+		return id
+	}
+	addCert := func(cert *types.CertificateInfo) string {
+		id := NodeCertificate + ":" + certificateKey(cert)
+		if _, exists := nodes[id]; !exists {
+			nodes[id] = GraphNode{ID: id, Type: NodeCertificate, Label: cert.Subject, Attributes: map[string]string{
+				"issuer":     cert.Issuer,
+				"issued_on":  formatTime(cert.IssuedOn),
+				"expires_on": formatTime(cert.ExpiresOn),
+			}}
+		}
+		return id
+	}
+
+	for _, entry := range sortedEntries(result) {
+		domainID := addDomain(entry.Domain)
+		node := nodes[domainID]
+		node.Attributes = map[string]string{
+			"url":       entry.URL,
+			"status":    fmt.Sprint(entry.Status),
+			"reachable": fmt.Sprint(entry.Reachable),
+		}
+		nodes[domainID] = node
+
+		if entry.IP != "" {
+			ipID := NodeIP + ":" + entry.IP
+			nodes[ipID] = GraphNode{ID: ipID, Type: NodeIP, Label: entry.IP}
+			edges[GraphEdge{Source: domainID, Target: ipID, Type: EdgeResolvesTo}] = true
+		}
+
+		if entry.Certificate != nil {
+			edges[GraphEdge{Source: domainID, Target: addCert(entry.Certificate), Type: EdgePresentedCert}] = true
+		}
+
+		for _, src := range entry.Sources {
+			if src.Certificate != nil {
+				edges[GraphEdge{Source: domainID, Target: addCert(src.Certificate), Type: EdgeSANOf}] = true
+			}
+		}
+
+		if target := hostOf(redirectTarget(entry)); target != "" && target != entry.Domain {
+			edges[GraphEdge{Source: domainID, Target: addDomain(target), Type: EdgeRedirectsTo}] = true
+		}
+	}
+
+	graph := &Graph{Nodes: make([]GraphNode, 0, len(nodes)), Edges: make([]GraphEdge, 0, len(edges))}
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	for edge := range edges {
+		graph.Edges = append(graph.Edges, edge)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Target < b.Target
+	})
+	return graph
+}
+
+// certificateKey identifies a certificate by subject, issuer and validity start
+func certificateKey(cert *types.CertificateInfo) string {
+	return cert.Subject + "|" + cert.Issuer + "|" + formatTime(cert.IssuedOn)
+}
+
+// formatGraph writes the asset graph as indented JSON
+func formatGraph(w io.Writer, result *domainscan.AssetDiscoveryResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(BuildGraph(result)); err != nil {
+		return fmt.Errorf("failed to marshal graph: %w", err)
+	}
+	return nil
+}
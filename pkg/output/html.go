@@ -0,0 +1,144 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+// htmlReport is the data passed to the HTML report template
+type htmlReport struct {
+	Generated    string
+	Statistics   domainscan.DiscoveryStats
+	Live         []*domainscan.DomainEntry
+	Traced       []*domainscan.DomainEntry
+	Certificates []*domainscan.DomainEntry
+	Redirects    []*domainscan.DomainEntry
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"sources":  func(e *domainscan.DomainEntry) string { return sourceNames(e, ", ") },
+	"redirect": redirectTarget,
+	"date":     formatTime,
+	"codes": func(codes []int) string {
+		parts := make([]string, len(codes))
+		for i, code := range codes {
+			parts[i] = fmt.Sprint(code)
+		}
+		return strings.Join(parts, " → ")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Domain Discovery Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #222; }
+h1 { margin-bottom: 0.2rem; }
+.meta { color: #666; margin-bottom: 1.5rem; }
+.stats { display: flex; gap: 1rem; margin-bottom: 2rem; }
+.stat { border: 1px solid #ddd; border-radius: 6px; padding: 0.8rem 1.2rem; }
+.stat b { display: block; font-size: 1.6rem; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2rem; font-size: 0.9rem; }
+th, td { border-bottom: 1px solid #eee; padding: 0.4rem 0.6rem; text-align: left; }
+th { background: #f6f6f6; cursor: pointer; user-select: none; }
+th:after { content: " ⇅"; color: #aaa; }
+tr:hover td { background: #fafafa; }
+.untrusted { color: #b00; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Domain Discovery Report</h1>
+<div class="meta">Generated {{.Generated}}</div>
+<div class="stats">
+<div class="stat"><b>{{.Statistics.TotalSubdomains}}</b>domains</div>
+<div class="stat"><b>{{.Statistics.ActiveServices}}</b>live</div>
+<div class="stat"><b>{{.Statistics.TracedDomains}}</b>traced</div>
+</div>
+
+<h2>Live Hosts ({{len .Live}})</h2>
+<table class="sortable">
+<thead><tr><th>Domain</th><th>URL</th><th>Status</th><th>IP</th><th>Sources</th></tr></thead>
+<tbody>
+{{range .Live}}<tr><td>{{.Domain}}</td><td><a href="{{.URL}}">{{.URL}}</a></td><td>{{.Status}}</td><td>{{.IP}}</td><td>{{sources .}}</td></tr>
+{{end}}</tbody>
+</table>
+
+<h2>Traced Hosts ({{len .Traced}})</h2>
+<table class="sortable">
+<thead><tr><th>Domain</th><th>Sources</th></tr></thead>
+<tbody>
+{{range .Traced}}<tr><td>{{.Domain}}</td><td>{{sources .}}</td></tr>
+{{end}}</tbody>
+</table>
+
+<h2>Certificates ({{len .Certificates}})</h2>
+<table class="sortable">
+<thead><tr><th>Domain</th><th>Subject</th><th>Issuer</th><th>Issued</th><th>Expires</th><th>Trusted</th></tr></thead>
+<tbody>
+{{range .Certificates}}<tr><td>{{.Domain}}</td><td>{{.Certificate.Subject}}</td><td>{{.Certificate.Issuer}}</td><td>{{date .Certificate.IssuedOn}}</td><td>{{date .Certificate.ExpiresOn}}</td><td>{{if .Certificate.Untrusted}}<span class="untrusted">no</span>{{else}}yes{{end}}</td></tr>
+{{end}}</tbody>
+</table>
+
+<h2>Redirects ({{len .Redirects}})</h2>
+<table class="sortable">
+<thead><tr><th>Domain</th><th>Redirects To</th><th>Status Codes</th></tr></thead>
+<tbody>
+{{range .Redirects}}<tr><td>{{.Domain}}</td><td>{{redirect .}}</td><td>{{codes .Redirect.StatusCodes}}</td></tr>
+{{end}}</tbody>
+</table>
+
+<script>
+document.querySelectorAll("table.sortable th").forEach(function (th) {
+  th.addEventListener("click", function () {
+    var table = th.closest("table");
+    var index = Array.prototype.indexOf.call(th.parentNode.children, th);
+    var asc = th.dataset.order !== "asc";
+    th.parentNode.querySelectorAll("th").forEach(function (h) { delete h.dataset.order; });
+    th.dataset.order = asc ? "asc" : "desc";
+    var body = table.tBodies[0];
+    var rows = Array.prototype.slice.call(body.rows);
+    rows.sort(function (a, b) {
+      var x = a.cells[index].textContent, y = b.cells[index].textContent;
+      var nx = parseFloat(x), ny = parseFloat(y);
+      var cmp = (!isNaN(nx) && !isNaN(ny)) ? nx - ny : x.localeCompare(y);
+      return asc ? cmp : -cmp;
+    });
+    rows.forEach(function (row) { body.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// formatHTML writes a self-contained HTML report with sortable tables of
+// live and traced hosts, certificates and redirects
+func formatHTML(w io.Writer, result *domainscan.AssetDiscoveryResult) error {
+	report := htmlReport{
+		Generated:  time.Now().UTC().Format(time.RFC1123),
+		Statistics: result.Statistics,
+	}
+	for _, entry := range sortedEntries(result) {
+		if entry.Reachable {
+			report.Live = append(report.Live, entry)
+		} else {
+			report.Traced = append(report.Traced, entry)
+		}
+		if entry.Certificate != nil {
+			report.Certificates = append(report.Certificates, entry)
+		}
+		if entry.Redirect != nil && entry.Redirect.IsRedirect {
+			report.Redirects = append(report.Redirects, entry)
+		}
+	}
+
+	if err := htmlTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+// formatJSON writes the full result as an indented JSON document
+func formatJSON(w io.Writer, result *domainscan.AssetDiscoveryResult) error {
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	_, err = w.Write(output)
+	return err
+}
+
+// formatJSONL writes one compact JSON domain entry per line, sorted by domain
+func formatJSONL(w io.Writer, result *domainscan.AssetDiscoveryResult) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range sortedEntries(result) {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to marshal JSONL entry %s: %w", entry.Domain, err)
+		}
+	}
+	return nil
+}
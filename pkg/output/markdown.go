@@ -0,0 +1,54 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+// formatMarkdown writes a summary and tables of live and traced domains
+func formatMarkdown(w io.Writer, result *domainscan.AssetDiscoveryResult) error {
+	var sb strings.Builder
+	entries := sortedEntries(result)
+
+	sb.WriteString("# Domain Discovery Results\n\n")
+	sb.WriteString(fmt.Sprintf("- **Discovered:** %d domains\n", result.Statistics.TotalSubdomains))
+	sb.WriteString(fmt.Sprintf("- **Live:** %d\n", result.Statistics.ActiveServices))
+	sb.WriteString(fmt.Sprintf("- **Traced:** %d\n\n", result.Statistics.TracedDomains))
+
+	sb.WriteString("## Live Hosts\n\n")
+	sb.WriteString("| Domain | URL | Status | IP | Redirects To | Certificate Expires | Sources |\n")
+	sb.WriteString("|---|---|---|---|---|---|---|\n")
+	for _, entry := range entries {
+		if !entry.Reachable {
+			continue
+		}
+		expires := ""
+		if entry.Certificate != nil {
+			expires = formatTime(entry.Certificate.ExpiresOn)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %d | %s | %s | %s | %s |\n",
+			escapeMarkdown(entry.Domain), escapeMarkdown(entry.URL), entry.Status, entry.IP,
+			escapeMarkdown(redirectTarget(entry)), expires, escapeMarkdown(sourceNames(entry, ", "))))
+	}
+
+	sb.WriteString("\n## Traced Hosts\n\n")
+	sb.WriteString("| Domain | Sources |\n")
+	sb.WriteString("|---|---|\n")
+	for _, entry := range entries {
+		if entry.Reachable {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", escapeMarkdown(entry.Domain), escapeMarkdown(sourceNames(entry, ", "))))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// escapeMarkdown escapes characters that would break a Markdown table cell
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
@@ -0,0 +1,141 @@
+// Package output renders domain discovery results in the formats supported by the CLI.
+// Formats are registered by name so new ones can be added without touching the CLI.
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+// Formatter renders a discovery result into a specific output format
+type Formatter interface {
+	Format(w io.Writer, result *domainscan.AssetDiscoveryResult) error
+}
+
+// FormatterFunc adapts a function to the Formatter interface
+type FormatterFunc func(w io.Writer, result *domainscan.AssetDiscoveryResult) error
+
+// Format implements Formatter
+func (f FormatterFunc) Format(w io.Writer, result *domainscan.AssetDiscoveryResult) error {
+	return f(w, result)
+}
+
+// registration holds a registered formatter and its help text
+type registration struct {
+	formatter   Formatter
+	description string
+	alias       bool
+}
+
+var registry = make(map[string]registration)
+
+// Register adds a formatter under the given name, replacing any existing one.
+// Names are case-insensitive.
+func Register(name string, description string, formatter Formatter) {
+	registry[strings.ToLower(name)] = registration{formatter: formatter, description: description}
+}
+
+// RegisterAlias makes alias select the same formatter as an already registered name
+func RegisterAlias(alias string, name string) {
+	reg, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return
+	}
+	reg.alias = true
+	registry[strings.ToLower(alias)] = reg
+}
+
+// Get returns the formatter registered under name
+func Get(name string) (Formatter, error) {
+	reg, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q: must be one of %s", name, strings.Join(Names(), ", "))
+	}
+	return reg.formatter, nil
+}
+
+// Names returns the sorted names of all registered formats, excluding aliases
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name, reg := range registry {
+		if !reg.alias {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Description returns the help text of a registered format
+func Description(name string) string {
+	return registry[strings.ToLower(name)].description
+}
+
+// Write renders result with the named formatter
+func Write(w io.Writer, format string, result *domainscan.AssetDiscoveryResult) error {
+	formatter, err := Get(format)
+	if err != nil {
+		return err
+	}
+	return formatter.Format(w, result)
+}
+
+// sortedEntries returns the result entries sorted by domain name for stable output
+func sortedEntries(result *domainscan.AssetDiscoveryResult) []*domainscan.DomainEntry {
+	entries := make([]*domainscan.DomainEntry, 0, len(result.Domains))
+	for _, entry := range result.Domains {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Domain < entries[j].Domain
+	})
+	return entries
+}
+
+// sourceNames returns the unique source names of an entry joined by sep
+func sourceNames(entry *domainscan.DomainEntry, sep string) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, src := range entry.Sources {
+		if !seen[src.Name] {
+			seen[src.Name] = true
+			names = append(names, src.Name)
+		}
+	}
+	return strings.Join(names, sep)
+}
+
+// redirectTarget returns the redirect destination of an entry, if any
+func redirectTarget(entry *domainscan.DomainEntry) string {
+	if entry.Redirect == nil || !entry.Redirect.IsRedirect {
+		return ""
+	}
+	return entry.Redirect.RedirectsTo
+}
+
+// displayName returns the domain of an entry followed by its Unicode form, if it has one
+func displayName(entry *domainscan.DomainEntry) string {
+	if entry.UnicodeDomain == "" {
+		return entry.Domain
+	}
+	return fmt.Sprintf("%s (%s)", entry.Domain, entry.UnicodeDomain)
+}
+
+func init() {
+	Register("text", "Human readable summary with live hosts highlighted", FormatterFunc(formatText))
+	Register("json", "Full result as an indented JSON document", FormatterFunc(formatJSON))
+	Register("jsonl", "One domain entry per line as JSON, for streaming into jq or log shippers", FormatterFunc(formatJSONL))
+	Register("csv", "One row per domain for spreadsheets", FormatterFunc(formatCSV))
+	Register("markdown", "Markdown tables for tickets and wikis", FormatterFunc(formatMarkdown))
+	Register("html", "Self-contained HTML report with sortable tables", FormatterFunc(formatHTML))
+	Register("urls", "Live URLs one per line, as a target list for scanners such as nuclei", FormatterFunc(formatURLs))
+	Register("sarif", "SARIF 2.1.0 log of certificate and subdomain takeover findings", FormatterFunc(formatSARIF))
+	Register("graph", "Asset graph JSON of domains, IPs and certificates with their relationships", FormatterFunc(formatGraph))
+	RegisterAlias("md", "markdown")
+	RegisterAlias("nuclei", "urls")
+	RegisterAlias("ndjson", "jsonl")
+}
@@ -0,0 +1,225 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// testResult returns a small result with one live, one redirecting and one traced domain
+func testResult() *domainscan.AssetDiscoveryResult {
+	return &domainscan.AssetDiscoveryResult{
+		Domains: map[string]*domainscan.DomainEntry{
+			"www.example.com": {
+				Domain:    "www.example.com",
+				URL:       "https://www.example.com",
+				Status:    200,
+				Reachable: true,
+				IP:        "192.0.2.10",
+				Sources:   []types.Source{{Name: "subfinder", Type: "passive"}, {Name: "httpx", Type: "http"}},
+				Certificate: &types.CertificateInfo{
+					Subject:   "www.example.com",
+					Issuer:    "Example CA",
+					ExpiresOn: time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			"old.example.com": {
+				Domain:    "old.example.com",
+				URL:       "http://old.example.com",
+				Status:    301,
+				Reachable: true,
+				Redirect:  &types.RedirectInfo{IsRedirect: true, RedirectsTo: "https://www.example.com", StatusCodes: []int{301}},
+				Sources:   []types.Source{{Name: "certificate-san", Type: "certificate"}},
+			},
+			"dev.example.com": {
+				Domain:  "dev.example.com",
+				Sources: []types.Source{{Name: "subfinder", Type: "passive"}},
+			},
+		},
+		Statistics: domainscan.DiscoveryStats{TotalSubdomains: 3, ActiveServices: 2, TracedDomains: 1},
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	for _, name := range []string{"text", "json", "jsonl", "csv", "markdown", "html", "urls", "sarif", "graph", "md", "NDJSON", "nuclei"} {
+		if _, err := Get(name); err != nil {
+			t.Errorf("Get(%q) returned error: %v", name, err)
+		}
+	}
+	if _, err := Get("yaml"); err == nil {
+		t.Error("Get() should fail for unknown format")
+	}
+
+	for _, name := range Names() {
+		if name == "md" || name == "ndjson" {
+			t.Errorf("Names() should not list alias %q", name)
+		}
+	}
+
+	Register("custom", "Test formatter", FormatterFunc(func(w io.Writer, result *domainscan.AssetDiscoveryResult) error {
+		_, err := io.WriteString(w, "custom")
+		return err
+	}))
+	defer delete(registry, "custom")
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "custom", testResult()); err != nil || buf.String() != "custom" {
+		t.Errorf("Write() with custom formatter = %q, %v", buf.String(), err)
+	}
+}
+
+func TestFormatJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "jsonl", testResult()); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	var entry domainscan.DomainEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Line is not valid JSON: %v", err)
+	}
+	if entry.Domain != "dev.example.com" {
+		t.Errorf("Expected entries sorted by domain, first is %s", entry.Domain)
+	}
+}
+
+func TestFormatCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "csv", testResult()); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("Expected header + 3 rows, got %d", len(records))
+	}
+	www := records[3]
+	if www[0] != "www.example.com" || www[2] != "200" || www[6] != "subfinder;httpx" || www[10] != "2030-01-02T00:00:00Z" {
+		t.Errorf("Unexpected CSV row: %v", www)
+	}
+}
+
+func TestFormatMarkdownAndHTML(t *testing.T) {
+	var md bytes.Buffer
+	if err := Write(&md, "markdown", testResult()); err != nil {
+		t.Fatalf("markdown returned error: %v", err)
+	}
+	if !strings.Contains(md.String(), "| www.example.com | https://www.example.com | 200 |") {
+		t.Errorf("Markdown missing live host row:\n%s", md.String())
+	}
+
+	var html bytes.Buffer
+	if err := Write(&html, "html", testResult()); err != nil {
+		t.Fatalf("html returned error: %v", err)
+	}
+	for _, want := range []string{"<h2>Live Hosts (2)</h2>", "<h2>Traced Hosts (1)</h2>", "<h2>Redirects (1)</h2>", "301"} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("HTML report missing %q", want)
+		}
+	}
+}
+
+func TestFormatURLs(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "urls", testResult()); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+	expected := "http://old.example.com\nhttps://www.example.com\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestFormatSARIF(t *testing.T) {
+	result := testResult()
+	result.Domains["www.example.com"].Certificate.ExpiresOn = time.Now().Add(-time.Hour)
+	result.Domains["www.example.com"].Certificate.Untrusted = true
+	result.Domains["docs.example.com"] = &domainscan.DomainEntry{
+		Domain:    "docs.example.com",
+		URL:       "https://docs.example.com",
+		Status:    404,
+		Reachable: true,
+		Redirect:  &types.RedirectInfo{IsRedirect: true, RedirectsTo: "https://example.github.io/"},
+	}
+	result.Domains["shop.example.com"] = &domainscan.DomainEntry{
+		Domain:    "shop.example.com",
+		URL:       "https://shop.example.com",
+		Status:    404,
+		Reachable: true,
+		CNAMEs:    []string{"shop-example.herokudns.com."},
+	}
+	result.Domains["blog.example.com"] = &domainscan.DomainEntry{
+		Domain:    "blog.example.com",
+		URL:       "https://blog.example.com",
+		Status:    200,
+		Reachable: true,
+		CNAMEs:    []string{"example.netlify.app"},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "sarif", result); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF envelope: %+v", log)
+	}
+
+	rules := make(map[string]int)
+	for _, r := range log.Runs[0].Results {
+		rules[r.RuleID]++
+	}
+	expected := map[string]int{"DS001": 1, "DS003": 1, "DS004": 2}
+	for id, count := range expected {
+		if rules[id] != count {
+			t.Errorf("Expected %d %s findings, got %d (all: %v)", count, id, rules[id], rules)
+		}
+	}
+}
+
+func TestBuildGraph(t *testing.T) {
+	result := testResult()
+	result.Domains["old.example.com"].Sources[0].Certificate = result.Domains["www.example.com"].Certificate
+
+	graph := BuildGraph(result)
+
+	edges := make(map[GraphEdge]bool)
+	for _, e := range graph.Edges {
+		edges[e] = true
+	}
+	certID := "certificate:" + certificateKey(result.Domains["www.example.com"].Certificate)
+	expected := []GraphEdge{
+		{Source: "domain:www.example.com", Target: "ip:192.0.2.10", Type: EdgeResolvesTo},
+		{Source: "domain:www.example.com", Target: certID, Type: EdgePresentedCert},
+		{Source: "domain:old.example.com", Target: certID, Type: EdgeSANOf},
+		{Source: "domain:old.example.com", Target: "domain:www.example.com", Type: EdgeRedirectsTo},
+	}
+	for _, e := range expected {
+		if !edges[e] {
+			t.Errorf("Missing edge %+v", e)
+		}
+	}
+
+	// 3 domains, 1 IP, 1 certificate
+	if len(graph.Nodes) != 5 {
+		t.Errorf("Expected 5 nodes, got %d: %+v", len(graph.Nodes), graph.Nodes)
+	}
+}
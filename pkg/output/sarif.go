@@ -0,0 +1,231 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+// CertificateExpiryWarning is how far ahead an expiring certificate is reported
+var CertificateExpiryWarning = 30 * 24 * time.Hour
+
+// TakeoverServiceSuffixes are hosting services whose unclaimed resources commonly allow
+// subdomain takeover. A 404 from a domain pointing at one of them is reported as a candidate.
+var TakeoverServiceSuffixes = []string{
+	"github.io", "herokuapp.com", "herokudns.com", "s3.amazonaws.com", "cloudfront.net",
+	"azurewebsites.net", "cloudapp.net", "trafficmanager.net", "blob.core.windows.net",
+	"pantheonsite.io", "netlify.app", "fastly.net", "ghost.io", "myshopify.com",
+	"surge.sh", "bitbucket.io", "wordpress.com", "zendesk.com", "readthedocs.io",
+}
+
+// sarifRule describes a finding type reported in the SARIF log
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	DefaultLevel     string       `json:"-"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	LogicalLocations []sarifLogical        `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogical struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// Rules reported in SARIF output
+var (
+	ruleCertExpired = sarifRule{
+		ID: "DS001", Name: "CertificateExpired", DefaultLevel: "error",
+		ShortDescription: sarifMessage{Text: "TLS certificate has expired"},
+	}
+	ruleCertExpiring = sarifRule{
+		ID: "DS002", Name: "CertificateExpiringSoon", DefaultLevel: "warning",
+		ShortDescription: sarifMessage{Text: "TLS certificate expires soon"},
+	}
+	ruleCertUntrusted = sarifRule{
+		ID: "DS003", Name: "CertificateUntrusted", DefaultLevel: "warning",
+		ShortDescription: sarifMessage{Text: "TLS certificate failed verification against the trust store"},
+	}
+	ruleTakeover = sarifRule{
+		ID: "DS004", Name: "PotentialSubdomainTakeover", DefaultLevel: "warning",
+		ShortDescription: sarifMessage{Text: "Domain points at an unclaimed resource on a takeover-prone hosting service"},
+	}
+	sarifRules = []sarifRule{ruleCertExpired, ruleCertExpiring, ruleCertUntrusted, ruleTakeover}
+)
+
+// Finding is a security-relevant observation about a discovered domain
+type Finding struct {
+	RuleID  string `json:"rule_id"` // Stable rule identifier, e.g. "DS001"
+	Rule    string `json:"rule"`    // Rule name, e.g. "CertificateExpired"
+	Level   string `json:"level"`   // "error" or "warning"
+	Domain  string `json:"domain"`
+	URL     string `json:"url"`
+	Message string `json:"message"`
+}
+
+// Findings returns the certificate and subdomain takeover findings in result, ordered by domain
+func Findings(result *domainscan.AssetDiscoveryResult, now time.Time) []Finding {
+	var findings []Finding
+	for _, entry := range sortedEntries(result) {
+		if cert := entry.Certificate; cert != nil {
+			switch {
+			case !cert.ExpiresOn.IsZero() && cert.ExpiresOn.Before(now):
+				findings = append(findings, newFinding(ruleCertExpired, entry,
+					fmt.Sprintf("Certificate for %s (subject %q) expired on %s", entry.Domain, cert.Subject, formatTime(cert.ExpiresOn))))
+			case !cert.ExpiresOn.IsZero() && cert.ExpiresOn.Before(now.Add(CertificateExpiryWarning)):
+				findings = append(findings, newFinding(ruleCertExpiring, entry,
+					fmt.Sprintf("Certificate for %s (subject %q) expires on %s", entry.Domain, cert.Subject, formatTime(cert.ExpiresOn))))
+			}
+			if cert.Untrusted {
+				findings = append(findings, newFinding(ruleCertUntrusted, entry,
+					fmt.Sprintf("Certificate for %s issued by %q is not trusted", entry.Domain, cert.Issuer)))
+			}
+		}
+
+		if service := takeoverService(entry); service != "" {
+			findings = append(findings, newFinding(ruleTakeover, entry,
+				fmt.Sprintf("%s returns %d and points at %s; the resource may be unclaimed", entry.Domain, entry.Status, service)))
+		}
+	}
+	return findings
+}
+
+// newFinding creates a finding for a domain entry
+func newFinding(rule sarifRule, entry *domainscan.DomainEntry, message string) Finding {
+	uri := entry.URL
+	if uri == "" {
+		uri = "https://" + entry.Domain
+	}
+	return Finding{RuleID: rule.ID, Rule: rule.Name, Level: rule.DefaultLevel, Domain: entry.Domain, URL: uri, Message: message}
+}
+
+// formatSARIF writes certificate and takeover findings as a SARIF 2.1.0 log
+func formatSARIF(w io.Writer, result *domainscan.AssetDiscoveryResult) error {
+	results := []sarifResult{}
+	for _, finding := range Findings(result, time.Now()) {
+		results = append(results, newSARIFResult(finding))
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "domain-scan",
+				InformationURI: "https://github.com/valllabh/domain-scan",
+				Rules:          sarifRules,
+			}},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to marshal SARIF: %w", err)
+	}
+	return nil
+}
+
+// newSARIFResult converts a finding into a SARIF result
+func newSARIFResult(finding Finding) sarifResult {
+	return sarifResult{
+		RuleID:  finding.RuleID,
+		Level:   finding.Level,
+		Message: sarifMessage{Text: finding.Message},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifact{URI: finding.URL}},
+			LogicalLocations: []sarifLogical{{Name: finding.Domain, Kind: "domain"}},
+		}},
+	}
+}
+
+// takeoverService returns the takeover-prone service a 404 domain points at through its
+// CNAME chain or redirect target, or empty
+func takeoverService(entry *domainscan.DomainEntry) string {
+	if !entry.Reachable || entry.Status != 404 {
+		return ""
+	}
+	hosts := make([]string, 0, len(entry.CNAMEs)+2)
+	for _, cname := range entry.CNAMEs {
+		hosts = append(hosts, strings.TrimSuffix(strings.ToLower(cname), "."))
+	}
+	hosts = append(hosts, hostOf(entry.URL), hostOf(redirectTarget(entry)))
+	for _, host := range hosts {
+		if host != "" && isTakeoverHost(host) {
+			return host
+		}
+	}
+	return ""
+}
+
+// isTakeoverHost reports whether host belongs to a takeover-prone hosting service
+func isTakeoverHost(host string) bool {
+	for _, suffix := range TakeoverServiceSuffixes {
+		if host == suffix || strings.HasSuffix(host, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostOf returns the lowercase hostname of a URL, or empty if it cannot be parsed
+func hostOf(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
@@ -0,0 +1,47 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+// formatText writes the summary, live domains and the first 10 traced domains
+func formatText(w io.Writer, result *domainscan.AssetDiscoveryResult) error {
+	var sb strings.Builder
+	// Write summary header
+	sb.WriteString("\nDiscovery Results:\n")
+	sb.WriteString(fmt.Sprintf("  Discovered: %d domains (%d live, %d traced)\n\n",
+		result.Statistics.TotalSubdomains,
+		result.Statistics.ActiveServices,
+		result.Statistics.TracedDomains))
+
+	entries := sortedEntries(result)
+
+	// Show live domains first
+	for _, entry := range entries {
+		if entry.Reachable {
+			sb.WriteString(fmt.Sprintf("%s \033[32m[LIVE:%d]\033[0m\n", displayName(entry), entry.Status))
+		}
+	}
+
+	// Show traced domains
+	if result.Statistics.TracedDomains > 0 {
+		sb.WriteString(fmt.Sprintf("\n%d traced domains (not HTTP accessible):\n", result.Statistics.TracedDomains))
+		tracedShown := 0
+		for _, entry := range entries {
+			if !entry.Reachable && tracedShown < 10 {
+				sb.WriteString(fmt.Sprintf("  %s\033[90m [TRACED]\033[0m\n", displayName(entry)))
+				tracedShown++
+			}
+		}
+		if result.Statistics.TracedDomains > 10 {
+			sb.WriteString(fmt.Sprintf("  ... and %d more (see domains.json for full list)\n", result.Statistics.TracedDomains-10))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,21 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+// formatURLs writes the URL of every live domain, one per line, for scanners such as nuclei
+func formatURLs(w io.Writer, result *domainscan.AssetDiscoveryResult) error {
+	for _, entry := range sortedEntries(result) {
+		if !entry.Reachable || entry.URL == "" {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, entry.URL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// JobStatus is the lifecycle state of a scan job
+type JobStatus string
+
+const (
+	StatusQueued    JobStatus = "queued"
+	StatusRunning   JobStatus = "running"
+	StatusCompleted JobStatus = "completed"
+	StatusFailed    JobStatus = "failed"
+	StatusCancelled JobStatus = "cancelled"
+)
+
+// finished reports whether the job has reached a terminal state
+func (s JobStatus) finished() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
+// Progress is the latest progress reported by the scanner of a job
+type Progress struct {
+	TotalDomains int `json:"total_domains"`
+	LiveDomains  int `json:"live_domains"`
+}
+
+// JobInfo is the public view of a scan job
+type JobInfo struct {
+	ID         string                     `json:"id"`
+	Status     JobStatus                  `json:"status"`
+	Domains    []string                   `json:"domains"`
+	Keywords   []string                   `json:"keywords,omitempty"`
+	Progress   Progress                   `json:"progress"`
+	Stage      string                     `json:"stage,omitempty"` // Current discovery stage while running
+	Depth      int                        `json:"depth,omitempty"` // Current recursion depth while running
+	Statistics *domainscan.DiscoveryStats `json:"statistics,omitempty"`
+	Error      string                     `json:"error,omitempty"`
+	CreatedAt  time.Time                  `json:"created_at"`
+	StartedAt  time.Time                  `json:"started_at,omitzero"`
+	FinishedAt time.Time                  `json:"finished_at,omitzero"`
+}
+
+// job tracks a single asynchronous scan. All fields are guarded by mu.
+type job struct {
+	mu      sync.Mutex
+	info    JobInfo
+	request *domainscan.ScanRequest
+	result  *domainscan.AssetDiscoveryResult
+	cancel  func()
+	done    chan struct{}                   // Closed once the job's goroutine has exited
+	events  *domainscan.ProgressBroadcaster // Progress stream for SSE and WebSocket subscribers
+}
+
+// snapshot returns a copy of the job's public state
+func (j *job) snapshot() JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	info := j.info
+	if j.result != nil {
+		stats := j.result.Statistics
+		info.Statistics = &stats
+	}
+	return info
+}
+
+// start moves a queued job to running. Returns false if it was cancelled while queued.
+func (j *job) start() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.info.Status != StatusQueued {
+		return false
+	}
+	j.info.Status = StatusRunning
+	j.info.StartedAt = time.Now()
+	return true
+}
+
+// finish records the scan outcome unless the job was cancelled first.
+// It is called exactly once per job, by the goroutine running it.
+func (j *job) finish(result *domainscan.AssetDiscoveryResult, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if result != nil {
+		// Cancelled and timed out scans keep whatever was discovered before stopping
+		j.result = result
+		j.info.Progress = Progress{TotalDomains: result.Statistics.TotalSubdomains, LiveDomains: result.Statistics.ActiveServices}
+	}
+	if !j.info.Status.finished() {
+		if err != nil {
+			j.info.Status = StatusFailed
+			j.info.Error = err.Error()
+		} else {
+			j.info.Status = StatusCompleted
+		}
+		j.info.FinishedAt = time.Now()
+	}
+	// Failed and cancelled scans may never reach OnEnd; release subscribers either way
+	j.events.Close()
+	close(j.done)
+}
+
+// markCancelled cancels the job. Returns false if it had already finished.
+func (j *job) markCancelled() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.info.Status.finished() {
+		return false
+	}
+	j.info.Status = StatusCancelled
+	j.info.FinishedAt = time.Now()
+	j.cancel()
+	return true
+}
+
+// jobProgress adapts scanner progress callbacks onto a job and its event stream
+type jobProgress struct {
+	job *job
+}
+
+// OnStart implements domainscan.ProgressCallback
+func (p *jobProgress) OnStart(domains []string, keywords []string) {
+	p.job.mu.Lock()
+	p.job.info.Keywords = keywords
+	p.job.mu.Unlock()
+	p.job.events.OnStart(domains, keywords)
+}
+
+// OnProgress implements domainscan.ProgressCallback
+func (p *jobProgress) OnProgress(totalDomains, liveDomains int) {
+	p.job.mu.Lock()
+	p.job.info.Progress = Progress{TotalDomains: totalDomains, LiveDomains: liveDomains}
+	p.job.mu.Unlock()
+	p.job.events.OnProgress(totalDomains, liveDomains)
+}
+
+// OnEnd implements domainscan.ProgressCallback
+func (p *jobProgress) OnEnd(result *domainscan.AssetDiscoveryResult) {
+	p.job.events.OnEnd(result)
+}
+
+// OnStageStart implements domainscan.ExtendedProgressCallback
+func (p *jobProgress) OnStageStart(stage domainscan.StageInfo) {
+	p.job.mu.Lock()
+	p.job.info.Stage = stage.Stage
+	p.job.info.Depth = stage.Depth
+	p.job.mu.Unlock()
+	p.job.events.OnStageStart(stage)
+}
+
+// OnStageEnd implements domainscan.ExtendedProgressCallback
+func (p *jobProgress) OnStageEnd(stage domainscan.StageInfo, found int, elapsed time.Duration) {
+	p.job.events.OnStageEnd(stage, found, elapsed)
+}
+
+// OnDomainDiscovered implements domainscan.ExtendedProgressCallback
+func (p *jobProgress) OnDomainDiscovered(domain string, source types.Source) {
+	p.job.events.OnDomainDiscovered(domain, source)
+}
+
+// OnDomainVerified implements domainscan.ExtendedProgressCallback
+func (p *jobProgress) OnDomainVerified(entry *domainscan.DomainEntry) {
+	p.job.events.OnDomainVerified(entry)
+}
+
+// OnError implements domainscan.ExtendedProgressCallback
+func (p *jobProgress) OnError(stage string, err error) {
+	p.job.events.OnError(stage, err)
+}
+
+// OnDepthChange implements domainscan.ExtendedProgressCallback
+func (p *jobProgress) OnDepthChange(depth int) {
+	p.job.events.OnDepthChange(depth)
+}
+
+// newJobID returns a random job identifier
+func newJobID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format("150405.000000")))
+	}
+	return hex.EncodeToString(buf)
+}
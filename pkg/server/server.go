@@ -0,0 +1,439 @@
+// Package server exposes domain asset discovery as an asynchronous REST API.
+// Scans run as background jobs on a bounded worker pool and are polled for
+// status, progress and paginated results.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/utils"
+)
+
+// Default limits applied when Options leaves them unset
+const (
+	DefaultMaxConcurrent = 2
+	DefaultMaxQueued     = 16
+	DefaultMaxJobs       = 100
+	DefaultScanTimeout   = 30 * time.Minute
+	DefaultPageSize      = 100
+	MaxPageSize          = 1000
+)
+
+// ScanFunc runs a single scan, reporting progress through the callback
+type ScanFunc func(ctx context.Context, config *domainscan.Config, req *domainscan.ScanRequest, progress domainscan.ProgressCallback) (*domainscan.AssetDiscoveryResult, error)
+
+// Options configures the API server
+type Options struct {
+	Token         string             // Required as "Authorization: Bearer <token>" or X-API-Token; empty disables auth
+	MaxConcurrent int                // Scans running at the same time
+	MaxQueued     int                // Jobs waiting for a free slot before submissions are rejected
+	MaxJobs       int                // Jobs retained in memory; the oldest finished jobs are evicted first
+	ScanTimeout   time.Duration      // Upper bound on the duration of a single scan
+	Config        *domainscan.Config // Base scanner configuration for every job
+	Scan          ScanFunc           // Overrides how scans are run (defaults to a domainscan.Scanner per job)
+}
+
+// Server manages scan jobs and serves the REST API
+type Server struct {
+	opts   Options
+	slots  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// CreateScanRequest is the body of POST /scans
+type CreateScanRequest struct {
+	Domains  []string `json:"domains"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// ResultsPage is a page of discovered domains returned by GET /scans/{id}/results
+type ResultsPage struct {
+	ID      string                    `json:"id"`
+	Status  JobStatus                 `json:"status"`
+	Total   int                       `json:"total"`
+	Offset  int                       `json:"offset"`
+	Limit   int                       `json:"limit"`
+	Domains []*domainscan.DomainEntry `json:"domains"`
+}
+
+// New creates a server, filling unset options with defaults
+func New(opts Options) *Server {
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = DefaultMaxConcurrent
+	}
+	if opts.MaxQueued <= 0 {
+		opts.MaxQueued = DefaultMaxQueued
+	}
+	if opts.MaxJobs <= 0 {
+		opts.MaxJobs = DefaultMaxJobs
+	}
+	if opts.ScanTimeout <= 0 {
+		opts.ScanTimeout = DefaultScanTimeout
+	}
+	if opts.Config == nil {
+		opts.Config = domainscan.DefaultConfig()
+	}
+	if opts.Scan == nil {
+		opts.Scan = runScanner
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		opts:   opts,
+		slots:  make(chan struct{}, opts.MaxConcurrent),
+		ctx:    ctx,
+		cancel: cancel,
+		jobs:   make(map[string]*job),
+	}
+}
+
+// runScanner runs a scan with a dedicated domainscan.Scanner so progress callbacks don't mix between jobs
+func runScanner(ctx context.Context, config *domainscan.Config, req *domainscan.ScanRequest, progress domainscan.ProgressCallback) (*domainscan.AssetDiscoveryResult, error) {
+	jobConfig := *config
+	scanner := domainscan.New(&jobConfig)
+	if scanner == nil {
+		return nil, domainscan.NewError(domainscan.ErrInvalidConfig, "invalid scanner configuration", nil)
+	}
+	scanner.SetProgressCallback(progress)
+	return scanner.ScanWithOptions(ctx, req)
+}
+
+// Handler returns the HTTP handler serving the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.Handle("POST /scans", s.authenticate(s.handleCreateScan))
+	mux.Handle("GET /scans", s.authenticate(s.handleListScans))
+	mux.Handle("GET /scans/{id}", s.authenticate(s.handleGetScan))
+	mux.Handle("GET /scans/{id}/results", s.authenticate(s.handleGetResults))
+	mux.Handle("GET /scans/{id}/events", s.authenticate(s.handleEvents))
+	mux.Handle("GET /scans/{id}/ws", s.authenticate(s.handleWebSocket))
+	mux.Handle("DELETE /scans/{id}", s.authenticate(s.handleDeleteScan))
+	return mux
+}
+
+// Shutdown cancels all jobs and waits for their scans to stop or for ctx to expire
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Submit queues a scan and returns its job info. Fails when the queue is full.
+func (s *Server) Submit(req CreateScanRequest) (JobInfo, error) {
+	if len(req.Domains) == 0 {
+		return JobInfo{}, errors.New("no domains provided")
+	}
+	if _, _, err := utils.SplitTargets(req.Domains); err != nil {
+		return JobInfo{}, err
+	}
+
+	s.mu.Lock()
+	queued := 0
+	for _, j := range s.jobs {
+		if j.snapshot().Status == StatusQueued {
+			queued++
+		}
+	}
+	if queued >= s.opts.MaxQueued {
+		s.mu.Unlock()
+		return JobInfo{}, ErrQueueFull
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.opts.ScanTimeout)
+	j := &job{
+		info: JobInfo{
+			ID:        newJobID(),
+			Status:    StatusQueued,
+			Domains:   req.Domains,
+			Keywords:  req.Keywords,
+			CreatedAt: time.Now(),
+		},
+		request: &domainscan.ScanRequest{
+			Domains:  req.Domains,
+			Keywords: req.Keywords,
+			Timeout:  s.opts.Config.Discovery.Timeout,
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+		events: domainscan.NewProgressBroadcaster(0),
+	}
+	s.jobs[j.info.ID] = j
+	s.evictLocked()
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run(ctx, j)
+	return j.snapshot(), nil
+}
+
+// ErrQueueFull is returned by Submit when MaxQueued jobs are already waiting
+var ErrQueueFull = errors.New("too many queued scans, try again later")
+
+// run waits for a free slot and executes the job's scan
+func (s *Server) run(ctx context.Context, j *job) {
+	defer s.wg.Done()
+	defer j.cancel()
+
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		j.finish(nil, ctx.Err())
+		return
+	}
+	defer func() { <-s.slots }()
+
+	if !j.start() {
+		j.finish(nil, nil)
+		return
+	}
+	result, err := s.opts.Scan(ctx, s.opts.Config, j.request, &jobProgress{job: j})
+	if ctx.Err() != nil {
+		// Scans stop with a context error; report why. Cancelled jobs keep their status.
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			err = fmt.Errorf("scan timed out after %s", s.opts.ScanTimeout)
+		case s.ctx.Err() != nil:
+			err = errors.New("scan interrupted by server shutdown")
+		}
+	}
+	j.finish(result, err)
+}
+
+// evictLocked drops the oldest finished jobs beyond MaxJobs. Callers must hold s.mu.
+func (s *Server) evictLocked() {
+	if len(s.jobs) <= s.opts.MaxJobs {
+		return
+	}
+	var finished []JobInfo
+	for _, j := range s.jobs {
+		if info := j.snapshot(); info.Status.finished() {
+			finished = append(finished, info)
+		}
+	}
+	sort.Slice(finished, func(a, b int) bool { return finished[a].CreatedAt.Before(finished[b].CreatedAt) })
+	for _, info := range finished {
+		if len(s.jobs) <= s.opts.MaxJobs {
+			return
+		}
+		delete(s.jobs, info.ID)
+	}
+}
+
+// lookup returns the job with the given ID, or nil
+func (s *Server) lookup(id string) *job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[id]
+}
+
+// authenticate rejects requests without the configured API token. The access_token query
+// parameter is accepted too, since browser EventSource and WebSocket clients can't set headers.
+func (s *Server) authenticate(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.opts.Token != "" {
+			token := r.Header.Get("X-API-Token")
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				token = strings.TrimSpace(bearer)
+			}
+			if token == "" {
+				token = r.URL.Query().Get("access_token")
+			}
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="domain-scan"`)
+				writeError(w, http.StatusUnauthorized, "invalid or missing API token")
+				return
+			}
+		}
+		next(w, r)
+	})
+}
+
+// handleHealth reports server liveness and job counts
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	jobs := len(s.jobs)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":      "healthy",
+		"timestamp":   time.Now().Unix(),
+		"jobs":        jobs,
+		"running":     len(s.slots),
+		"max_running": cap(s.slots),
+	})
+}
+
+// handleCreateScan queues a new scan job
+func (s *Server) handleCreateScan(w http.ResponseWriter, r *http.Request) {
+	var req CreateScanRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	info, err := s.Submit(req)
+	if errors.Is(err, ErrQueueFull) {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Location", "/scans/"+info.ID)
+	writeJSON(w, http.StatusAccepted, info)
+}
+
+// handleListScans lists all retained jobs, newest first
+func (s *Server) handleListScans(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		infos = append(infos, j.snapshot())
+	}
+	s.mu.Unlock()
+	sort.Slice(infos, func(a, b int) bool { return infos[a].CreatedAt.After(infos[b].CreatedAt) })
+	writeJSON(w, http.StatusOK, map[string]interface{}{"scans": infos})
+}
+
+// handleGetScan returns status and progress of a job
+func (s *Server) handleGetScan(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(r.PathValue("id"))
+	if j == nil {
+		writeError(w, http.StatusNotFound, "scan not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, j.snapshot())
+}
+
+// handleGetResults returns a page of discovered domains sorted by name.
+// Query parameters: offset (default 0), limit (default 100, max 1000).
+func (s *Server) handleGetResults(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(r.PathValue("id"))
+	if j == nil {
+		writeError(w, http.StatusNotFound, "scan not found")
+		return
+	}
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		writeError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+	limit, err := queryInt(r, "limit", DefaultPageSize)
+	if err != nil || limit <= 0 {
+		writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+		return
+	}
+	limit = min(limit, MaxPageSize)
+
+	j.mu.Lock()
+	status := j.info.Status
+	result := j.result
+	j.mu.Unlock()
+	if result == nil {
+		writeError(w, http.StatusConflict, fmt.Sprintf("results not available for %s scan", status))
+		return
+	}
+
+	names := make([]string, 0, len(result.Domains))
+	for name := range result.Domains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	page := ResultsPage{
+		ID:      r.PathValue("id"),
+		Status:  status,
+		Total:   len(names),
+		Offset:  offset,
+		Limit:   limit,
+		Domains: []*domainscan.DomainEntry{},
+	}
+	for i := offset; i < len(names) && i < offset+limit; i++ {
+		page.Domains = append(page.Domains, result.Domains[names[i]])
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// handleEvents streams the progress events of a job as Server-Sent Events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(r.PathValue("id"))
+	if j == nil {
+		writeError(w, http.StatusNotFound, "scan not found")
+		return
+	}
+	j.events.ServeHTTP(w, r)
+}
+
+// handleWebSocket streams the progress events of a job over a WebSocket
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(r.PathValue("id"))
+	if j == nil {
+		writeError(w, http.StatusNotFound, "scan not found")
+		return
+	}
+	j.events.WebSocketHandler().ServeHTTP(w, r)
+}
+
+// handleDeleteScan cancels a queued or running job, or removes a finished one
+func (s *Server) handleDeleteScan(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	j := s.lookup(id)
+	if j == nil {
+		writeError(w, http.StatusNotFound, "scan not found")
+		return
+	}
+	if j.markCancelled() {
+		writeJSON(w, http.StatusAccepted, j.snapshot())
+		return
+	}
+	s.mu.Lock()
+	delete(s.jobs, id)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// queryInt parses an integer query parameter, returning def when it is absent
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,267 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+)
+
+const testToken = "secret-token"
+
+// fakeScan returns a ScanFunc that reports progress, then waits for release or cancellation
+func fakeScan(release chan struct{}, domains int) ScanFunc {
+	return func(ctx context.Context, config *domainscan.Config, req *domainscan.ScanRequest, progress domainscan.ProgressCallback) (*domainscan.AssetDiscoveryResult, error) {
+		progress.OnStart(req.Domains, []string{"example"})
+		progress.OnProgress(domains, 1)
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		result := &domainscan.AssetDiscoveryResult{Domains: map[string]*domainscan.DomainEntry{}}
+		for i := 0; i < domains; i++ {
+			name := fmt.Sprintf("host%02d.example.com", i)
+			result.Domains[name] = &domainscan.DomainEntry{Domain: name, Reachable: i == 0}
+		}
+		result.UpdateStatistics()
+		progress.OnEnd(result)
+		return result, nil
+	}
+}
+
+// request performs an authenticated API request and decodes the JSON response into v
+func request(t *testing.T, srv *httptest.Server, method, path, body string, v interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(resp.Body)
+	if v != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, v); err != nil {
+			t.Fatalf("failed to decode %s: %v", data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// waitForStatus polls a job until it reaches the expected status
+func waitForStatus(t *testing.T, srv *httptest.Server, id string, expected JobStatus) JobInfo {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var info JobInfo
+		request(t, srv, http.MethodGet, "/scans/"+id, "", &info)
+		if info.Status == expected {
+			return info
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s stuck in %s, expected %s", id, info.Status, expected)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	s := New(Options{Token: testToken, Scan: fakeScan(nil, 0)})
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		expected int
+	}{
+		{name: "missing token", expected: http.StatusUnauthorized},
+		{name: "wrong bearer token", header: "Authorization", value: "Bearer nope", expected: http.StatusUnauthorized},
+		{name: "bearer token", header: "Authorization", value: "Bearer " + testToken, expected: http.StatusOK},
+		{name: "api token header", header: "X-API-Token", value: testToken, expected: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/scans", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+
+	// Health checks don't require a token
+	resp, err := srv.Client().Get(srv.URL + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected health status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerScanLifecycle(t *testing.T) {
+	release := make(chan struct{})
+	s := New(Options{Token: testToken, Scan: fakeScan(release, 5)})
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	if code := request(t, srv, http.MethodPost, "/scans", `{"domains": []}`, nil); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for empty domains, got %d", code)
+	}
+
+	var created JobInfo
+	if code := request(t, srv, http.MethodPost, "/scans", `{"domains": ["example.com"]}`, &created); code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d", code)
+	}
+	if created.ID == "" {
+		t.Fatal("Expected a job ID")
+	}
+
+	running := waitForStatus(t, srv, created.ID, StatusRunning)
+	if running.Progress.TotalDomains != 5 || running.Progress.LiveDomains != 1 {
+		t.Errorf("Unexpected progress: %+v", running.Progress)
+	}
+	if code := request(t, srv, http.MethodGet, "/scans/"+created.ID+"/results", "", nil); code != http.StatusConflict {
+		t.Errorf("Expected 409 for results of a running scan, got %d", code)
+	}
+
+	close(release)
+	completed := waitForStatus(t, srv, created.ID, StatusCompleted)
+	if completed.Statistics == nil || completed.Statistics.TotalSubdomains != 5 {
+		t.Errorf("Unexpected statistics: %+v", completed.Statistics)
+	}
+
+	var page ResultsPage
+	if code := request(t, srv, http.MethodGet, "/scans/"+created.ID+"/results?offset=2&limit=2", "", &page); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if page.Total != 5 || len(page.Domains) != 2 || page.Domains[0].Domain != "host02.example.com" {
+		t.Errorf("Unexpected page: total=%d domains=%d", page.Total, len(page.Domains))
+	}
+	if code := request(t, srv, http.MethodGet, "/scans/"+created.ID+"/results?limit=-1", "", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid limit, got %d", code)
+	}
+
+	// Event streams replay the whole scan once it has finished
+	var events []domainscan.ProgressEvent
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/scans/"+created.ID+"/events?access_token="+testToken, nil)
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	for _, line := range strings.Split(string(body), "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var event domainscan.ProgressEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("invalid event %s: %v", data, err)
+			}
+			events = append(events, event)
+		}
+	}
+	if len(events) != 3 || events[0].Type != domainscan.EventStart || events[2].Type != domainscan.EventEnd {
+		t.Errorf("Unexpected event stream: %+v", events)
+	}
+
+	// Deleting a finished scan removes it
+	if code := request(t, srv, http.MethodDelete, "/scans/"+created.ID, "", nil); code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", code)
+	}
+	if code := request(t, srv, http.MethodGet, "/scans/"+created.ID, "", nil); code != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", code)
+	}
+}
+
+func TestServerCancelAndQueueLimits(t *testing.T) {
+	s := New(Options{Token: testToken, MaxConcurrent: 1, MaxQueued: 1, Scan: fakeScan(make(chan struct{}), 1)})
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	var first, second JobInfo
+	request(t, srv, http.MethodPost, "/scans", `{"domains": ["a.example.com"]}`, &first)
+	waitForStatus(t, srv, first.ID, StatusRunning)
+	request(t, srv, http.MethodPost, "/scans", `{"domains": ["b.example.com"]}`, &second)
+
+	// One running, one queued: the queue is full
+	if code := request(t, srv, http.MethodPost, "/scans", `{"domains": ["c.example.com"]}`, nil); code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 when the queue is full, got %d", code)
+	}
+
+	// Cancelling the queued job frees its queue slot without running it
+	if code := request(t, srv, http.MethodDelete, "/scans/"+second.ID, "", nil); code != http.StatusAccepted {
+		t.Errorf("Expected 202 when cancelling, got %d", code)
+	}
+	if info := waitForStatus(t, srv, second.ID, StatusCancelled); !info.StartedAt.IsZero() {
+		t.Error("Cancelled queued job should never start")
+	}
+
+	// Cancelling the running job keeps its partial results
+	request(t, srv, http.MethodDelete, "/scans/"+first.ID, "", nil)
+	waitForStatus(t, srv, first.ID, StatusCancelled)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var page ResultsPage
+		if request(t, srv, http.MethodGet, "/scans/"+first.ID+"/results", "", &page) == http.StatusOK {
+			if page.Status != StatusCancelled || page.Total != 1 {
+				t.Errorf("Unexpected partial results: %+v", page)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("partial results never became available")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown() returned error: %v", err)
+	}
+}
+
+func TestServerCancelFreesSlot(t *testing.T) {
+	s := New(Options{Token: testToken, MaxConcurrent: 1, MaxQueued: 1, Scan: fakeScan(make(chan struct{}), 1)})
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	var first, second JobInfo
+	request(t, srv, http.MethodPost, "/scans", `{"domains": ["a.example.com"]}`, &first)
+	waitForStatus(t, srv, first.ID, StatusRunning)
+	request(t, srv, http.MethodPost, "/scans", `{"domains": ["b.example.com"]}`, &second)
+	if info := waitForStatus(t, srv, second.ID, StatusQueued); !info.StartedAt.IsZero() {
+		t.Fatal("Second job should wait while the only slot is taken")
+	}
+
+	// The scan stops when its job is cancelled, so the queued job gets the slot
+	request(t, srv, http.MethodDelete, "/scans/"+first.ID, "", nil)
+	waitForStatus(t, srv, first.ID, StatusCancelled)
+	waitForStatus(t, srv, second.ID, StatusRunning)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown() returned error: %v", err)
+	}
+}
@@ -0,0 +1,544 @@
+// Package store persists discovery results in an embedded SQLite database so that
+// scans of many apex domains can be kept over time and queried relationally.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/types"
+	"github.com/valllabh/domain-scan/pkg/utils"
+
+	// Pure-Go SQLite driver, registered as "sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// schemaVersion is stored in PRAGMA user_version and bumped on schema changes
+const schemaVersion = 3
+
+// schema creates the tables on first use. Times are stored as Unix seconds (UTC), 0 when unknown.
+const schema = `
+CREATE TABLE IF NOT EXISTS scans (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at    INTEGER NOT NULL,
+	finished_at   INTEGER NOT NULL,
+	targets       TEXT    NOT NULL, -- JSON array
+	keywords      TEXT    NOT NULL, -- JSON array
+	total_domains INTEGER NOT NULL,
+	live_domains  INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS domains (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	name          TEXT    NOT NULL UNIQUE,
+	first_seen    INTEGER NOT NULL,
+	last_seen     INTEGER NOT NULL,
+	first_scan_id INTEGER NOT NULL REFERENCES scans(id),
+	last_scan_id  INTEGER NOT NULL REFERENCES scans(id)
+);
+
+CREATE TABLE IF NOT EXISTS certificates (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	subject    TEXT    NOT NULL,
+	issuer     TEXT    NOT NULL,
+	issued_on  INTEGER NOT NULL,
+	expires_on INTEGER NOT NULL,
+	untrusted  INTEGER NOT NULL,
+	UNIQUE (subject, issuer, issued_on, expires_on)
+);
+
+CREATE TABLE IF NOT EXISTS ips (
+	id      INTEGER PRIMARY KEY AUTOINCREMENT,
+	address TEXT NOT NULL UNIQUE
+);
+
+-- State of a domain as observed by one scan
+CREATE TABLE IF NOT EXISTS observations (
+	scan_id        INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+	domain_id      INTEGER NOT NULL REFERENCES domains(id),
+	url            TEXT    NOT NULL,
+	status         INTEGER NOT NULL,
+	reachable      INTEGER NOT NULL,
+	redirect       TEXT, -- JSON RedirectInfo
+	cnames         TEXT, -- JSON array of the CNAME chain
+	probed_at      INTEGER NOT NULL DEFAULT 0,
+	certificate_id INTEGER REFERENCES certificates(id),
+	PRIMARY KEY (scan_id, domain_id)
+);
+
+CREATE TABLE IF NOT EXISTS domain_ips (
+	scan_id   INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+	domain_id INTEGER NOT NULL REFERENCES domains(id),
+	ip_id     INTEGER NOT NULL REFERENCES ips(id),
+	PRIMARY KEY (scan_id, domain_id, ip_id)
+);
+
+CREATE TABLE IF NOT EXISTS sources (
+	scan_id        INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+	domain_id      INTEGER NOT NULL REFERENCES domains(id),
+	name           TEXT    NOT NULL,
+	type           TEXT    NOT NULL,
+	parent         TEXT    NOT NULL,
+	seed           TEXT    NOT NULL,
+	depth          INTEGER NOT NULL,
+	stage          TEXT    NOT NULL,
+	discovered_at  INTEGER NOT NULL,
+	certificate_id INTEGER REFERENCES certificates(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sources_domain ON sources (domain_id, scan_id);
+CREATE INDEX IF NOT EXISTS idx_domain_ips_domain ON domain_ips (domain_id, scan_id);
+CREATE INDEX IF NOT EXISTS idx_certificates_expires ON certificates (expires_on);
+`
+
+// migrations upgrade databases written by earlier versions; migrations[i] moves version i+1 to i+2.
+// New databases get the current schema directly.
+var migrations = []string{
+	`ALTER TABLE observations ADD COLUMN cnames TEXT`,
+	`ALTER TABLE observations ADD COLUMN probed_at INTEGER NOT NULL DEFAULT 0`,
+}
+
+// Store is a SQLite-backed repository of scans and the domains they found
+type Store struct {
+	db *sql.DB
+}
+
+// Scan summarises a stored scan
+type Scan struct {
+	ID           int64     `json:"id"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	Targets      []string  `json:"targets"`
+	Keywords     []string  `json:"keywords,omitempty"`
+	TotalDomains int       `json:"total_domains"`
+	LiveDomains  int       `json:"live_domains"`
+}
+
+// Record is a domain as last observed, with the times it was first and last seen
+type Record struct {
+	Entry     *domainscan.DomainEntry `json:"entry"`
+	ScanID    int64                   `json:"scan_id"`    // Scan the entry was observed in
+	FirstSeen time.Time               `json:"first_seen"` // Start of the first scan that found the domain
+	LastSeen  time.Time               `json:"last_seen"`  // Start of the latest scan that found the domain
+}
+
+// Query selects domains. Zero-valued fields don't filter.
+type Query struct {
+	ScanID         int64     // Domains as observed by this scan instead of their latest observation
+	Live           bool      // Only reachable domains
+	ExpiresBefore  time.Time // Only domains whose certificate expires before this time, including expired ones
+	FirstSeenSince time.Time // Only domains first seen at or after this time
+	LastSeenSince  time.Time // Only domains seen at or after this time
+	Domain         string    // Only this domain and its subdomains
+	Source         string    // Only domains found by this source name, e.g. "certificate-san"
+	Limit          int       // Maximum number of records (0 = unlimited)
+}
+
+// Open opens (creating if needed) the database at path and migrates its schema
+func Open(path string) (*Store, error) {
+	dsn := "file:" + path + "?" + url.Values{"_pragma": {"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"}}.Encode()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// migrate creates the schema, refusing databases written by a newer version
+func (s *Store) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read database version: %w", err)
+	}
+	if version > schemaVersion {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, schemaVersion)
+	}
+	if _, err := s.db.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create database schema: %w", err)
+	}
+	for v := version; v > 0 && v < schemaVersion; v++ {
+		if _, err := s.db.Exec(migrations[v-1]); err != nil {
+			return fmt.Errorf("failed to migrate database to version %d: %w", v+1, err)
+		}
+	}
+	if _, err := s.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)); err != nil {
+		return fmt.Errorf("failed to set database version: %w", err)
+	}
+	return nil
+}
+
+// SaveScan stores a finished scan and every domain in its result, returning the scan ID.
+// Domains keep their earliest first-seen time across scans, and their latest observation
+// (used by Query) is the one from the most recently started scan.
+func (s *Store) SaveScan(ctx context.Context, targets, keywords []string, startedAt time.Time, result *domainscan.AssetDiscoveryResult) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	targetsJSON, _ := json.Marshal(nonNil(targets))
+	keywordsJSON, _ := json.Marshal(nonNil(keywords))
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO scans (started_at, finished_at, targets, keywords, total_domains, live_domains) VALUES (?, ?, ?, ?, ?, ?)`,
+		unix(startedAt), unix(time.Now()), string(targetsJSON), string(keywordsJSON),
+		result.Statistics.TotalSubdomains, result.Statistics.ActiveServices)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert scan: %w", err)
+	}
+	scanID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert in a stable order so IDs are reproducible
+	names := make([]string, 0, len(result.Domains))
+	for name := range result.Domains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := saveEntry(ctx, tx, scanID, startedAt, result.Domains[name]); err != nil {
+			return 0, fmt.Errorf("failed to save %s: %w", name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit scan: %w", err)
+	}
+	return scanID, nil
+}
+
+// saveEntry stores one domain entry observed by scanID
+func saveEntry(ctx context.Context, tx *sql.Tx, scanID int64, seenAt time.Time, entry *domainscan.DomainEntry) error {
+	var domainID int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO domains (name, first_seen, last_seen, first_scan_id, last_scan_id) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			first_scan_id = CASE WHEN excluded.first_seen < first_seen THEN excluded.first_scan_id ELSE first_scan_id END,
+			first_seen = min(first_seen, excluded.first_seen),
+			last_scan_id = CASE WHEN excluded.last_seen >= last_seen THEN excluded.last_scan_id ELSE last_scan_id END,
+			last_seen = max(last_seen, excluded.last_seen)
+		RETURNING id`,
+		entry.Domain, unix(seenAt), unix(seenAt), scanID, scanID).Scan(&domainID)
+	if err != nil {
+		return err
+	}
+
+	certificateID, err := saveCertificate(ctx, tx, entry.Certificate)
+	if err != nil {
+		return err
+	}
+	var redirect interface{}
+	if entry.Redirect != nil {
+		data, _ := json.Marshal(entry.Redirect)
+		redirect = string(data)
+	}
+	var cnames interface{}
+	if len(entry.CNAMEs) > 0 {
+		data, _ := json.Marshal(entry.CNAMEs)
+		cnames = string(data)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO observations (scan_id, domain_id, url, status, reachable, redirect, cnames, probed_at, certificate_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		scanID, domainID, entry.URL, entry.Status, entry.Reachable, redirect, cnames, unix(entry.ProbedAt), certificateID); err != nil {
+		return err
+	}
+
+	if entry.IP != "" {
+		var ipID int64
+		if err := tx.QueryRowContext(ctx,
+			`INSERT INTO ips (address) VALUES (?) ON CONFLICT (address) DO UPDATE SET address = excluded.address RETURNING id`,
+			entry.IP).Scan(&ipID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO domain_ips (scan_id, domain_id, ip_id) VALUES (?, ?, ?)`, scanID, domainID, ipID); err != nil {
+			return err
+		}
+	}
+
+	for _, source := range entry.Sources {
+		sourceCertificateID, err := saveCertificate(ctx, tx, source.Certificate)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO sources (scan_id, domain_id, name, type, parent, seed, depth, stage, discovered_at, certificate_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			scanID, domainID, source.Name, source.Type, source.Parent, source.Seed, source.Depth, source.Stage,
+			unix(source.DiscoveredAt), sourceCertificateID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveCertificate stores a certificate once and returns its ID, or nil for no certificate
+func saveCertificate(ctx context.Context, tx *sql.Tx, cert *types.CertificateInfo) (interface{}, error) {
+	if cert == nil {
+		return nil, nil
+	}
+	var id int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO certificates (subject, issuer, issued_on, expires_on, untrusted) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (subject, issuer, issued_on, expires_on) DO UPDATE SET untrusted = excluded.untrusted
+		RETURNING id`,
+		cert.Subject, cert.Issuer, unix(cert.IssuedOn), unix(cert.ExpiresOn), cert.Untrusted).Scan(&id)
+	return id, err
+}
+
+// Scans lists stored scans, newest first
+func (s *Store) Scans(ctx context.Context) ([]Scan, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, started_at, finished_at, targets, keywords, total_domains, live_domains FROM scans ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scans: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var scans []Scan
+	for rows.Next() {
+		var scan Scan
+		var startedAt, finishedAt int64
+		var targets, keywords string
+		if err := rows.Scan(&scan.ID, &startedAt, &finishedAt, &targets, &keywords, &scan.TotalDomains, &scan.LiveDomains); err != nil {
+			return nil, err
+		}
+		scan.StartedAt, scan.FinishedAt = fromUnix(startedAt), fromUnix(finishedAt)
+		_ = json.Unmarshal([]byte(targets), &scan.Targets)
+		_ = json.Unmarshal([]byte(keywords), &scan.Keywords)
+		scans = append(scans, scan)
+	}
+	return scans, rows.Err()
+}
+
+// Query returns the domains matching q, ordered by name
+func (s *Store) Query(ctx context.Context, q Query) ([]Record, error) {
+	var where []string
+	var args []interface{}
+
+	// Join each domain with its latest observation, or the one from the requested scan
+	observation := "o.scan_id = d.last_scan_id"
+	if q.ScanID != 0 {
+		observation = "o.scan_id = ?"
+		args = append(args, q.ScanID)
+	}
+	if q.Live {
+		where = append(where, "o.reachable = 1")
+	}
+	if !q.ExpiresBefore.IsZero() {
+		where = append(where, "c.expires_on > 0 AND c.expires_on < ?")
+		args = append(args, unix(q.ExpiresBefore))
+	}
+	if !q.FirstSeenSince.IsZero() {
+		where = append(where, "d.first_seen >= ?")
+		args = append(args, unix(q.FirstSeenSince))
+	}
+	if !q.LastSeenSince.IsZero() {
+		where = append(where, "d.last_seen >= ?")
+		args = append(args, unix(q.LastSeenSince))
+	}
+	if q.Domain != "" {
+		domain := strings.ToLower(strings.TrimSuffix(q.Domain, "."))
+		if normalized := utils.NormalizeTarget(q.Domain); normalized != "" {
+			domain = normalized // Matches IDNs given in Unicode and hosts given as URLs
+		}
+		where = append(where, "(d.name = ? OR d.name LIKE ? ESCAPE '\\')")
+		args = append(args, domain, "%."+escapeLike(domain))
+	}
+	if q.Source != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM sources s WHERE s.scan_id = o.scan_id AND s.domain_id = d.id AND s.name = ?)")
+		args = append(args, q.Source)
+	}
+
+	query := `
+		SELECT d.id, d.name, d.first_seen, d.last_seen, o.scan_id, o.url, o.status, o.reachable, o.redirect, o.cnames, o.probed_at,
+			c.subject, c.issuer, c.issued_on, c.expires_on, c.untrusted,
+			(SELECT i.address FROM domain_ips di JOIN ips i ON i.id = di.ip_id
+				WHERE di.scan_id = o.scan_id AND di.domain_id = d.id ORDER BY i.address LIMIT 1)
+		FROM domains d
+		JOIN observations o ON o.domain_id = d.id AND ` + observation + `
+		LEFT JOIN certificates c ON c.id = o.certificate_id`
+	if len(where) > 0 {
+		query += "\nWHERE " + strings.Join(where, " AND ")
+	}
+	query += "\nORDER BY d.name"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query domains: %w", err)
+	}
+	var records []Record
+	var domainIDs []int64
+	for rows.Next() {
+		record, domainID, err := scanRecord(rows)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		records = append(records, record)
+		domainIDs = append(domainIDs, domainID)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		sources, err := s.sources(ctx, records[i].ScanID, domainIDs[i])
+		if err != nil {
+			return nil, err
+		}
+		records[i].Entry.Sources = sources
+	}
+	return records, nil
+}
+
+// Result converts records into a discovery result, e.g. for the output formatters
+func Result(records []Record) *domainscan.AssetDiscoveryResult {
+	result := &domainscan.AssetDiscoveryResult{Domains: make(map[string]*domainscan.DomainEntry, len(records))}
+	for _, record := range records {
+		result.Domains[record.Entry.Domain] = record.Entry
+	}
+	result.TagSeeds(nil)
+	result.UpdateStatistics()
+	return result
+}
+
+// scanRecord reads one row of the Query select list
+func scanRecord(rows *sql.Rows) (Record, int64, error) {
+	var (
+		domainID, firstSeen, lastSeen, probedAt int64
+		record                                  = Record{Entry: &domainscan.DomainEntry{}}
+		redirect, cnames, subject, issuer, ip   sql.NullString
+		issuedOn, expiresOn                     sql.NullInt64
+		untrusted                               sql.NullBool
+	)
+	err := rows.Scan(&domainID, &record.Entry.Domain, &firstSeen, &lastSeen, &record.ScanID,
+		&record.Entry.URL, &record.Entry.Status, &record.Entry.Reachable, &redirect, &cnames, &probedAt,
+		&subject, &issuer, &issuedOn, &expiresOn, &untrusted, &ip)
+	if err != nil {
+		return Record{}, 0, fmt.Errorf("failed to read domain: %w", err)
+	}
+	record.FirstSeen, record.LastSeen = fromUnix(firstSeen), fromUnix(lastSeen)
+	record.Entry.UnicodeDomain = utils.ToUnicode(record.Entry.Domain)
+	record.Entry.ProbedAt = fromUnix(probedAt)
+	record.Entry.IP = ip.String
+	if redirect.Valid {
+		record.Entry.Redirect = &types.RedirectInfo{}
+		if err := json.Unmarshal([]byte(redirect.String), record.Entry.Redirect); err != nil {
+			return Record{}, 0, fmt.Errorf("invalid redirect for %s: %w", record.Entry.Domain, err)
+		}
+	}
+	if cnames.Valid {
+		if err := json.Unmarshal([]byte(cnames.String), &record.Entry.CNAMEs); err != nil {
+			return Record{}, 0, fmt.Errorf("invalid CNAMEs for %s: %w", record.Entry.Domain, err)
+		}
+	}
+	if subject.Valid {
+		record.Entry.Certificate = &types.CertificateInfo{
+			Subject:   subject.String,
+			Issuer:    issuer.String,
+			IssuedOn:  fromUnix(issuedOn.Int64),
+			ExpiresOn: fromUnix(expiresOn.Int64),
+			Untrusted: untrusted.Bool,
+		}
+	}
+	return record, domainID, nil
+}
+
+// sources loads the sources recorded for a domain by a scan
+func (s *Store) sources(ctx context.Context, scanID, domainID int64) ([]types.Source, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.name, s.type, s.parent, s.seed, s.depth, s.stage, s.discovered_at,
+			c.subject, c.issuer, c.issued_on, c.expires_on, c.untrusted
+		FROM sources s
+		LEFT JOIN certificates c ON c.id = s.certificate_id
+		WHERE s.scan_id = ? AND s.domain_id = ?
+		ORDER BY s.rowid`, scanID, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sources: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var sources []types.Source
+	for rows.Next() {
+		var (
+			source              types.Source
+			discoveredAt        int64
+			subject, issuer     sql.NullString
+			issuedOn, expiresOn sql.NullInt64
+			untrusted           sql.NullBool
+		)
+		if err := rows.Scan(&source.Name, &source.Type, &source.Parent, &source.Seed, &source.Depth, &source.Stage,
+			&discoveredAt, &subject, &issuer, &issuedOn, &expiresOn, &untrusted); err != nil {
+			return nil, err
+		}
+		source.DiscoveredAt = fromUnix(discoveredAt)
+		if subject.Valid {
+			source.Certificate = &types.CertificateInfo{
+				Subject:   subject.String,
+				Issuer:    issuer.String,
+				IssuedOn:  fromUnix(issuedOn.Int64),
+				ExpiresOn: fromUnix(expiresOn.Int64),
+				Untrusted: untrusted.Bool,
+			}
+		}
+		sources = append(sources, source)
+	}
+	return sources, rows.Err()
+}
+
+// unix converts t to Unix seconds, 0 for the zero time
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// fromUnix converts Unix seconds to UTC time, the zero time for 0
+func fromUnix(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
+
+// escapeLike escapes LIKE wildcards in s for use with ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// nonNil returns an empty slice for nil so it encodes as [] rather than null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
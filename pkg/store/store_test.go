@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/valllabh/domain-scan/pkg/domainscan"
+	"github.com/valllabh/domain-scan/pkg/types"
+)
+
+// scanResult builds a result from entries, recomputing statistics
+func scanResult(entries ...*domainscan.DomainEntry) *domainscan.AssetDiscoveryResult {
+	result := &domainscan.AssetDiscoveryResult{Domains: make(map[string]*domainscan.DomainEntry)}
+	for _, entry := range entries {
+		result.Domains[entry.Domain] = entry
+	}
+	result.UpdateStatistics()
+	return result
+}
+
+// names returns the sorted domain names of records
+func names(records []Record) []string {
+	var out []string
+	for _, record := range records {
+		out = append(out, record.Entry.Domain)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestStoreSaveAndQuery(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "scans.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	lastWeek := now.Add(-10 * 24 * time.Hour)
+	expiringCert := &types.CertificateInfo{Subject: "CN=api.example.com", Issuer: "CN=Test CA", ExpiresOn: now.Add(10 * 24 * time.Hour)}
+	freshCert := &types.CertificateInfo{Subject: "CN=www.example.com", Issuer: "CN=Test CA", ExpiresOn: now.Add(300 * 24 * time.Hour)}
+
+	// First scan: api and www are live, old is not
+	first := scanResult(
+		&domainscan.DomainEntry{Domain: "api.example.com", URL: "https://api.example.com", Status: 200, Reachable: true, IP: "192.0.2.10", Certificate: expiringCert,
+			Sources: []types.Source{{Name: "certificate-san", Type: "certificate", Parent: "example.com", Certificate: expiringCert, DiscoveredAt: lastWeek}}},
+		&domainscan.DomainEntry{Domain: "www.example.com", URL: "https://www.example.com", Status: 301, Reachable: true, Certificate: freshCert,
+			CNAMEs:   []string{"example.github.io"},
+			Redirect: &types.RedirectInfo{IsRedirect: true, RedirectsTo: "https://example.com/", StatusCodes: []int{301}}},
+		&domainscan.DomainEntry{Domain: "old.example.com", Sources: []types.Source{{Name: "subfinder", Type: "passive"}}},
+	)
+	if _, err := s.SaveScan(ctx, []string{"example.com"}, nil, lastWeek, first); err != nil {
+		t.Fatal(err)
+	}
+
+	// Second scan: api went dark, new appeared
+	second := scanResult(
+		&domainscan.DomainEntry{Domain: "api.example.com", Certificate: expiringCert},
+		&domainscan.DomainEntry{Domain: "new.example.com", URL: "https://new.example.com", Status: 200, Reachable: true},
+		&domainscan.DomainEntry{Domain: "other.test", Reachable: true},
+	)
+	secondID, err := s.SaveScan(ctx, []string{"example.com", "other.test"}, []string{"example"}, now, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{name: "all domains", query: Query{}, expected: []string{"api.example.com", "new.example.com", "old.example.com", "other.test", "www.example.com"}},
+		{name: "live uses latest observation", query: Query{Live: true}, expected: []string{"new.example.com", "other.test", "www.example.com"}},
+		{name: "certificates expiring in 30 days", query: Query{ExpiresBefore: now.Add(30 * 24 * time.Hour)}, expected: []string{"api.example.com"}},
+		{name: "live with expiring certificates", query: Query{Live: true, ExpiresBefore: now.Add(30 * 24 * time.Hour)}, expected: nil},
+		{name: "first seen this week", query: Query{FirstSeenSince: now.Add(-7 * 24 * time.Hour)}, expected: []string{"new.example.com", "other.test"}},
+		{name: "seen this week", query: Query{LastSeenSince: now.Add(-7 * 24 * time.Hour)}, expected: []string{"api.example.com", "new.example.com", "other.test"}},
+		{name: "domain and subdomains", query: Query{Domain: "Example.com."}, expected: []string{"api.example.com", "new.example.com", "old.example.com", "www.example.com"}},
+		{name: "by source", query: Query{Source: "certificate-san", ScanID: secondID - 1}, expected: []string{"api.example.com"}},
+		{name: "specific scan", query: Query{ScanID: secondID - 1, Live: true}, expected: []string{"api.example.com", "www.example.com"}},
+		{name: "limit", query: Query{Limit: 2}, expected: []string{"api.example.com", "new.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := s.Query(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := names(records)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("Expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+
+	// Entries round-trip with their certificates, redirects, IPs and sources
+	records, err := s.Query(ctx, Query{ScanID: secondID - 1, Domain: "api.example.com"})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("Expected api.example.com from the first scan, got %v (err %v)", records, err)
+	}
+	api := records[0]
+	if api.Entry.IP != "192.0.2.10" || api.Entry.Certificate == nil || !api.Entry.Certificate.ExpiresOn.Equal(expiringCert.ExpiresOn) {
+		t.Errorf("Unexpected entry: %+v", api.Entry)
+	}
+	if len(api.Entry.Sources) != 1 || api.Entry.Sources[0].Parent != "example.com" || api.Entry.Sources[0].Certificate == nil || !api.Entry.Sources[0].DiscoveredAt.Equal(lastWeek) {
+		t.Errorf("Unexpected sources: %+v", api.Entry.Sources)
+	}
+	if !api.FirstSeen.Equal(lastWeek) || !api.LastSeen.Equal(now) {
+		t.Errorf("Expected first seen %v and last seen %v, got %v and %v", lastWeek, now, api.FirstSeen, api.LastSeen)
+	}
+	www, _ := s.Query(ctx, Query{Domain: "www.example.com"})
+	if len(www) != 1 || www[0].Entry.Redirect == nil || www[0].Entry.Redirect.RedirectsTo != "https://example.com/" {
+		t.Errorf("Expected redirect to round-trip, got %+v", www)
+	}
+	if len(www) != 1 || len(www[0].Entry.CNAMEs) != 1 || www[0].Entry.CNAMEs[0] != "example.github.io" {
+		t.Errorf("Expected CNAMEs to round-trip, got %+v", www)
+	}
+	if api.Entry.CNAMEs != nil {
+		t.Errorf("Expected no CNAMEs for api.example.com, got %v", api.Entry.CNAMEs)
+	}
+
+	scans, err := s.Scans(ctx)
+	if err != nil || len(scans) != 2 {
+		t.Fatalf("Expected two scans, got %v (err %v)", scans, err)
+	}
+	if scans[0].ID != secondID || len(scans[0].Targets) != 2 || scans[0].LiveDomains != 2 || scans[0].Keywords[0] != "example" {
+		t.Errorf("Unexpected latest scan: %+v", scans[0])
+	}
+
+	// Reopening an existing database keeps its contents
+	_ = s.Close()
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records, _ := s.Query(ctx, Query{}); len(records) != 5 {
+		t.Errorf("Expected 5 domains after reopening, got %d", len(records))
+	}
+}
+
+func TestStoreRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scans.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.db.Exec("PRAGMA user_version = 99"); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+
+	if _, err := Open(path); err == nil {
+		t.Error("Expected an error opening a database with a newer schema")
+	}
+}
+
+func TestStoreMigratesVersion1(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "scans.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Recreate a version 1 database, written before CNAME chains and probe times were stored
+	if _, err := s.db.Exec("ALTER TABLE observations DROP COLUMN cnames; ALTER TABLE observations DROP COLUMN probed_at; PRAGMA user_version = 1"); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open a version 1 database: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	entry := &domainscan.DomainEntry{Domain: "shop.example.com", Status: 404, Reachable: true, CNAMEs: []string{"shops.myshopify.com"}}
+	if _, err := s.SaveScan(ctx, []string{"example.com"}, nil, time.Now(), scanResult(entry)); err != nil {
+		t.Fatal(err)
+	}
+	records, err := s.Query(ctx, Query{})
+	if err != nil || len(records) != 1 || len(records[0].Entry.CNAMEs) != 1 {
+		t.Errorf("Expected CNAMEs after migrating, got %+v (err %v)", records, err)
+	}
+}
+
+func TestStoreRoundTripsProbeTimeAndUnicode(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(filepath.Join(t.TempDir(), "scans.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+
+	probedAt := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+	result := scanResult(
+		&domainscan.DomainEntry{Domain: "xn--bcher-kva.example.com", UnicodeDomain: "bücher.example.com", Reachable: true, ProbedAt: probedAt},
+		&domainscan.DomainEntry{Domain: "traced.example.com"},
+	)
+	if _, err := s.SaveScan(ctx, []string{"example.com"}, nil, time.Now(), result); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := s.Query(ctx, Query{})
+	if err != nil || len(records) != 2 {
+		t.Fatalf("Expected two records, got %v (err %v)", records, err)
+	}
+	traced, idn := records[0].Entry, records[1].Entry
+	if !idn.ProbedAt.Equal(probedAt) || idn.UnicodeDomain != "bücher.example.com" {
+		t.Errorf("Expected probe time %v and Unicode form, got %+v", probedAt, idn)
+	}
+	if !traced.ProbedAt.IsZero() || traced.UnicodeDomain != "" {
+		t.Errorf("Expected an unprobed ASCII entry, got %+v", traced)
+	}
+}
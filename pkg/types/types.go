@@ -7,6 +7,13 @@ type Source struct {
 	Name        string           `json:"name"`                   // e.g., "subfinder", "certificate", "httpx"
 	Type        string           `json:"type"`                   // e.g., "passive", "certificate", "http"
 	Certificate *CertificateInfo `json:"certificate,omitempty"` // Certificate info if discovered from certificate SAN
+
+	// Provenance of discovery sources; empty for verification sources such as httpx
+	Parent       string    `json:"parent,omitempty"`        // Domain (or IP) whose scan produced this domain
+	Seed         string    `json:"seed,omitempty"`          // Input target the discovery chain started from
+	Depth        int       `json:"depth,omitempty"`         // Recursion depth at which the domain was found
+	Stage        string    `json:"stage,omitempty"`         // Discovery stage, e.g. "seed", "passive", "certificate", "ip-pivot", "active", "content"
+	DiscoveredAt time.Time `json:"discovered_at,omitzero"`  // When the domain was found by this source
 }
 
 // CertificateInfo contains TLS certificate metadata
@@ -15,23 +22,37 @@ type CertificateInfo struct {
 	ExpiresOn time.Time `json:"expires_on,omitempty"` // Certificate not after date
 	Issuer    string    `json:"issuer,omitempty"`     // Certificate issuer
 	Subject   string    `json:"subject,omitempty"`    // Certificate subject
+	Untrusted bool      `json:"untrusted,omitempty"`  // Certificate failed verification against the trust store
 }
 
 // RedirectInfo contains HTTP redirect information
 type RedirectInfo struct {
-	IsRedirect  bool   `json:"is_redirect"`            // Whether this domain redirects
-	RedirectsTo string `json:"redirects_to,omitempty"` // Final URL after all redirects
-	StatusCodes []int  `json:"status_codes,omitempty"` // HTTP status codes in redirect chain
+	IsRedirect  bool          `json:"is_redirect"`            // Whether this domain redirects
+	RedirectsTo string        `json:"redirects_to,omitempty"` // Final URL after all redirects
+	StatusCodes []int         `json:"status_codes,omitempty"` // HTTP status codes in redirect chain
+	Chain       []RedirectHop `json:"chain,omitempty"`        // Every response in the redirect chain, in order
+}
+
+// RedirectHop is one response in a redirect chain
+type RedirectHop struct {
+	URL      string `json:"url"`                // Requested URL
+	Status   int    `json:"status"`             // HTTP status code of the response
+	Host     string `json:"host"`               // Bare host of the requested URL
+	Location string `json:"location,omitempty"` // Location header of a redirect response
 }
 
 // DomainEntry represents a single domain with its protocol, port, and status
 type DomainEntry struct {
-	Domain      string           `json:"domain"`                // Bare domain (e.g., "example.com")
-	URL         string           `json:"url,omitempty"`         // Full URL if HTTP verified (e.g., "https://example.com")
-	Status      int              `json:"status"`                // HTTP status code
-	Reachable   bool             `json:"reachable"`             // Whether domain is reachable
-	IP          string           `json:"ip,omitempty"`          // IP address if resolved
-	Redirect    *RedirectInfo    `json:"redirect,omitempty"`    // Redirect information if domain redirects
-	Sources     []Source         `json:"sources,omitempty"`     // Discovery sources for this domain
-	Certificate *CertificateInfo `json:"certificate,omitempty"` // TLS certificate info if available
+	Domain        string           `json:"domain"`                   // Bare ASCII domain; IDNs in punycode (e.g., "xn--bcher-kva.example")
+	UnicodeDomain string           `json:"unicode_domain,omitempty"` // Unicode form of an internationalised domain (e.g., "bücher.example")
+	URL           string           `json:"url,omitempty"`     // Full URL if HTTP verified (e.g., "https://example.com")
+	Status        int              `json:"status"`            // HTTP status code
+	Reachable     bool             `json:"reachable"`         // Whether domain is reachable
+	IP            string           `json:"ip,omitempty"`      // IP address if resolved
+	CNAMEs        []string         `json:"cnames,omitempty"`  // CNAME chain the domain resolved through
+	Redirect      *RedirectInfo    `json:"redirect,omitempty"` // Redirect information if domain redirects
+	Sources       []Source         `json:"sources,omitempty"` // Discovery sources for this domain
+	Seeds         []string         `json:"seeds,omitempty"`   // Scan targets whose discovery chains reached this domain
+	Certificate   *CertificateInfo `json:"certificate,omitempty"` // TLS certificate info if available
+	ProbedAt      time.Time        `json:"probed_at,omitzero"` // When the HTTP/TLS fields were last probed
 }
@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Host is the host part of a URL, "host:port" pair, bare domain or IP address
+type Host struct {
+	Name string // Lowercase ASCII domain (IDNs in punycode) or canonical IP address
+	Port int    // Explicit port; 0 when none was given
+	IP   bool   // Whether Name is an IP address
+}
+
+// String returns the host with its port, bracketing IPv6 addresses when a port is present
+func (h Host) String() string {
+	if h.Port == 0 {
+		return h.Name
+	}
+	return net.JoinHostPort(h.Name, strconv.Itoa(h.Port))
+}
+
+// ParseHost extracts and normalises the host of a URL or host reference. Any scheme
+// ("https://", "wss://", "//"), userinfo, path, query and fragment are removed; ports
+// are split off, including after bracketed IPv6 literals ("[2001:db8::1]:8443"), while
+// bare IPv6 addresses keep all their colons. Domains are lowercased, converted to punycode,
+// stripped of a trailing dot and checked with ValidateHostname; IP addresses are returned
+// in canonical form without zones, IPv4-mapped IPv6 addresses as IPv4.
+//
+// Examples: "HTTPS://user@Example.COM.:8080/path?q" -> example.com port 8080,
+// "[2001:DB8::1]:443" -> 2001:db8::1 port 443, "Bücher.example" -> xn--bcher-kva.example
+func ParseHost(raw string) (Host, error) {
+	s := strings.TrimSpace(raw)
+	if i := strings.Index(s, "://"); i > 0 && isScheme(s[:i]) {
+		s = s[i+3:]
+	} else if strings.HasPrefix(s, "//") {
+		s = s[2:]
+	}
+	// The authority ends at the path, query or fragment; browsers treat '\' like '/'
+	if i := strings.IndexAny(s, `/?#\`); i != -1 {
+		s = s[:i]
+	}
+	if i := strings.LastIndex(s, "@"); i != -1 {
+		s = s[i+1:]
+	}
+	if s == "" {
+		return Host{}, fmt.Errorf("invalid host %q: empty host", raw)
+	}
+
+	name, port, bracketed, err := splitHostPort(s)
+	if err != nil {
+		return Host{}, fmt.Errorf("invalid host %q: %w", raw, err)
+	}
+	host := Host{Port: port}
+
+	if i := strings.Index(name, "%"); i != -1 && strings.Contains(name, ":") {
+		name = name[:i] // IPv6 zone, possibly URL-encoded as %25
+	}
+	if addr, err := netip.ParseAddr(name); err == nil {
+		host.Name = addr.Unmap().String()
+		host.IP = true
+		return host, nil
+	}
+	if bracketed || strings.Contains(name, ":") {
+		return Host{}, fmt.Errorf("invalid host %q: invalid IPv6 address", raw)
+	}
+
+	name = strings.TrimSuffix(name, ".")
+	ascii, err := ToASCII(name)
+	if err != nil {
+		return Host{}, fmt.Errorf("invalid host %q: %w", raw, err)
+	}
+	if err := ValidateHostname(ascii); err != nil {
+		return Host{}, fmt.Errorf("invalid host %q: %w", raw, err)
+	}
+	host.Name = ascii
+	return host, nil
+}
+
+// splitHostPort splits an authority into host and port. Bracketed hosts may be followed by
+// a port; unbracketed hosts with several colons are bare IPv6 addresses without one.
+func splitHostPort(s string) (host string, port int, bracketed bool, err error) {
+	rest := ""
+	switch {
+	case strings.HasPrefix(s, "["):
+		end := strings.Index(s, "]")
+		if end == -1 {
+			return "", 0, true, errors.New("missing ']' in address")
+		}
+		host, rest, bracketed = s[1:end], s[end+1:], true
+		if rest != "" && !strings.HasPrefix(rest, ":") {
+			return "", 0, true, fmt.Errorf("unexpected %q after address", rest)
+		}
+	case strings.Count(s, ":") > 1:
+		return s, 0, false, nil
+	default:
+		host = s
+		if i := strings.Index(s, ":"); i != -1 {
+			host, rest = s[:i], s[i:]
+		}
+	}
+
+	if rest = strings.TrimPrefix(rest, ":"); rest != "" {
+		value, parseErr := strconv.ParseUint(rest, 10, 16)
+		if parseErr != nil || value == 0 {
+			return "", 0, bracketed, fmt.Errorf("invalid port %q", rest)
+		}
+		port = int(value)
+	}
+	if host == "" {
+		return "", 0, bracketed, errors.New("empty host")
+	}
+	return host, port, bracketed, nil
+}
+
+// isScheme reports whether s is a valid URL scheme (RFC 3986: a letter followed by
+// letters, digits, '+', '-' or '.')
+func isScheme(s string) bool {
+	for i, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// ValidateHostname checks the syntax of a normalised (lowercase ASCII) hostname: at most
+// 253 characters of 1-63 character labels made of letters, digits, hyphens and underscores
+// (as in _dmarc service names), not starting or ending with a hyphen. The leftmost label
+// may be a certificate wildcard "*". The last label can't be all digits, so malformed
+// IPv4 addresses like 192.0.2.300 are rejected.
+func ValidateHostname(name string) error {
+	if name == "" {
+		return errors.New("empty hostname")
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("hostname is %d characters long, at most 253 allowed", len(name))
+	}
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if label == "*" && i == 0 && len(labels) > 1 {
+			continue
+		}
+		if label == "" {
+			return errors.New("empty label")
+		}
+		if len(label) > 63 {
+			return fmt.Errorf("label %q is longer than 63 characters", label)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("label %q starts or ends with a hyphen", label)
+		}
+		for j := 0; j < len(label); j++ {
+			c := label[j]
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return fmt.Errorf("label %q contains invalid character %q", label, c)
+			}
+		}
+	}
+	last := labels[len(labels)-1]
+	if strings.Trim(last, "0123456789") == "" {
+		return fmt.Errorf("top-level label %q is numeric", last)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// idnaProfile maps names the way resolvers look them up (UTS #46 non-transitional
+// processing: case folding, width and compatibility mapping) without the STD3 rules,
+// which would reject the underscores of service names like _dmarc.example.com
+var idnaProfile = idna.New(
+	idna.MapForLookup(),
+	idna.Transitional(false),
+	idna.StrictDomainName(false),
+	idna.BidiRule(),
+)
+
+// ToASCII returns the lowercase ASCII (punycode) form of a domain, e.g.
+// "Bücher.example" -> "xn--bcher-kva.example". ASCII names are only lowercased.
+func ToASCII(domain string) (string, error) {
+	if lower := strings.ToLower(domain); isASCII(domain) && !strings.Contains(lower, "xn--") {
+		return lower, nil
+	}
+	if !utf8.ValidString(domain) {
+		return strings.ToLower(domain), fmt.Errorf("idna: invalid UTF-8 in %q", domain)
+	}
+	ascii, err := idnaProfile.ToASCII(domain)
+	if err == nil && hasEmptyLabel(ascii) && !hasEmptyLabel(domain) {
+		err = fmt.Errorf("idna: empty label in %q", domain) // "xn--" decodes to nothing
+	}
+	if err != nil {
+		return strings.ToLower(domain), err
+	}
+	return ascii, nil
+}
+
+// ToUnicode returns the Unicode form of a domain with punycode labels, e.g.
+// "xn--bcher-kva.example" -> "bücher.example", or "" when the domain has none
+// or they don't decode
+func ToUnicode(domain string) string {
+	domain = strings.ToLower(domain)
+	if !strings.Contains(domain, "xn--") {
+		return ""
+	}
+	unicode, err := idnaProfile.ToUnicode(domain)
+	if err != nil || unicode == domain || hasEmptyLabel(unicode) {
+		return ""
+	}
+	return unicode
+}
+
+// domainForms returns a lowercase domain followed by its other IDN form when it has one,
+// so names match whether they were written in Unicode or punycode
+func domainForms(domain string) []string {
+	domain = strings.ToLower(domain)
+	if unicode := ToUnicode(domain); unicode != "" {
+		return []string{domain, unicode}
+	}
+	if !isASCII(domain) {
+		if ascii, err := ToASCII(domain); err == nil && ascii != domain {
+			return []string{domain, ascii}
+		}
+	}
+	return []string{domain}
+}
+
+// hasEmptyLabel reports whether a domain has an empty label other than a trailing root label
+func hasEmptyLabel(domain string) bool {
+	domain = strings.TrimSuffix(domain, ".")
+	return domain == "" || strings.HasPrefix(domain, ".") || strings.Contains(domain, "..")
+}
+
+// isASCII reports whether s contains only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package utils
+
+import "testing"
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"example.com", "example.com", false},
+		{"Example.COM", "example.com", false},
+		{"Bücher.example", "xn--bcher-kva.example", false},
+		{"BÜCHER.example", "xn--bcher-kva.example", false},
+		{"xn--bcher-kva.example", "xn--bcher-kva.example", false},
+		{"ＥＸＡＭＰＬＥ.com", "example.com", false},
+		{"faß.de", "xn--fa-hia.de", false},
+		{"_dmarc.bücher.example", "_dmarc.xn--bcher-kva.example", false},
+		{"xn--a.example", "xn--a.example", true},
+		{"XN--.example", "xn--.example", true},
+		{"Xn--00000.example", "xn--00000.example", true},
+		{"\x8b.example", "\uFFFD.example", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ToASCII(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToASCII(%q) error = %v, wantErr %t", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ToASCII(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToUnicode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"xn--bcher-kva.example", "bücher.example"},
+		{"shop.XN--BCHER-KVA.example", "shop.bücher.example"},
+		{"example.com", ""},
+		{"bücher.example", ""},
+		{"xn--a.example", ""},
+		{"xn--.example", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ToUnicode(tt.input); got != tt.expected {
+				t.Errorf("ToUnicode(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
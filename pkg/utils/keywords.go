@@ -3,6 +3,7 @@ package utils
 import (
 	_ "embed"
 	"encoding/json"
+	"sort"
 	"strings"
 )
 
@@ -39,13 +40,24 @@ func getTLDs() map[string]bool {
 	return tldSet
 }
 
+// TLDs returns the embedded public suffixes (e.g. "com", "co.uk"), sorted
+func TLDs() []string {
+	tlds := make([]string, 0, len(getTLDs()))
+	for tld := range getTLDs() {
+		tlds = append(tlds, tld)
+	}
+	sort.Strings(tlds)
+	return tlds
+}
+
 // ExtractKeywordsFromDomains extracts keywords from domain names
 func ExtractKeywordsFromDomains(domains []string) []string {
 	keywordMap := make(map[string]bool)
 	tlds := getTLDs()
 
 	for _, domain := range domains {
-		domain = strings.ToLower(domain)
+		// Work on the punycode form so IDN suffixes match the TLD list
+		domain, _ = ToASCII(domain)
 
 		// Remove TLDs from the end efficiently
 		domain = removeTLDs(domain, tlds)
@@ -54,6 +66,11 @@ func ExtractKeywordsFromDomains(domains []string) []string {
 			continue
 		}
 
+		// Extract keywords from the readable form of internationalised labels
+		if unicode := ToUnicode(domain); unicode != "" {
+			domain = unicode
+		}
+
 		// Now explode by dots and take the last element
 		parts := strings.Split(domain, ".")
 		if len(parts) == 0 {
@@ -91,11 +108,16 @@ func LoadKeywords(domains []string, keywordsInArgument []string) []string {
 		keywordMap[keyword] = true
 	}
 
-	// Add keywords from arguments
+	// Add keywords from arguments; regex keywords keep their case (e.g. \D)
 	for _, keyword := range keywordsInArgument {
-		if keyword != "" {
-			keywordMap[strings.ToLower(strings.TrimSpace(keyword))] = true
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		if parseKeyword(keyword).kind != KeywordRegex {
+			keyword = strings.ToLower(keyword)
 		}
+		keywordMap[keyword] = true
 	}
 
 	// Convert back to slice with pre-allocation
@@ -107,25 +129,23 @@ func LoadKeywords(domains []string, keywordsInArgument []string) []string {
 	return finalKeywords
 }
 
-// MatchesKeywords checks if a domain matches any of the provided keywords
-// and filters out invalid domains like wildcards
+// MatchesKeywords checks if a domain matches the provided keywords (see ExplainKeywords
+// for the keyword syntax) and filters out invalid domains like wildcards
 func MatchesKeywords(domain string, keywords []string) bool {
-	// Filter out wildcard domains
-	if strings.Contains(domain, "*") {
-		return false
-	}
-
-	if len(keywords) == 0 {
-		return true // Accept all if no keywords specified
-	}
+	return ExplainKeywords(domain, keywords).Accepted
+}
 
-	domainLower := strings.ToLower(domain)
-	for _, keyword := range keywords {
-		if strings.Contains(domainLower, strings.ToLower(keyword)) {
-			return true
-		}
+// ExtractBareDomain extracts the bare domain from a URL or domain string
+// Examples: "https://example.com" -> "example.com", "http://sub.example.com:8080" -> "sub.example.com"
+// Returns "" when no valid host can be extracted.
+//
+// Deprecated: Use ParseHost, which also reports why a host is invalid.
+func ExtractBareDomain(urlOrDomain string) string {
+	host, err := ParseHost(urlOrDomain)
+	if err != nil {
+		return ""
 	}
-	return false
+	return host.Name
 }
 
 // removeTLDs removes the longest matching TLD suffix from a domain
@@ -154,26 +174,17 @@ func removeTLDs(domain string, tlds map[string]bool) string {
 	return domain
 }
 
-// ExtractBareDomain extracts the bare domain from a URL or domain string
-// Examples: "https://example.com" -> "example.com", "http://sub.example.com:8080" -> "sub.example.com"
-func ExtractBareDomain(urlOrDomain string) string {
-	// Remove protocol if present
-	domain := urlOrDomain
-	if strings.HasPrefix(domain, "http://") {
-		domain = strings.TrimPrefix(domain, "http://")
-	} else if strings.HasPrefix(domain, "https://") {
-		domain = strings.TrimPrefix(domain, "https://")
-	}
-
-	// Remove port if present
-	if idx := strings.Index(domain, ":"); idx != -1 {
-		domain = domain[:idx]
-	}
-
-	// Remove path if present
-	if idx := strings.Index(domain, "/"); idx != -1 {
-		domain = domain[:idx]
-	}
-
-	return domain
+// RegistrableDomain returns the organisational part of a domain: the label directly
+// before the longest matching TLD plus that TLD.
+// Examples: "api.example.co.uk" -> "example.co.uk", "a.b.example.com" -> "example.com"
+// Returns the domain unchanged if no known TLD matches.
+func RegistrableDomain(domain string) string {
+	domain = strings.ToLower(domain)
+	stripped := removeTLDs(domain, getTLDs())
+	if stripped == "" || stripped == domain {
+		return domain
+	}
+	tld := domain[len(stripped)+1:]
+	parts := strings.Split(stripped, ".")
+	return parts[len(parts)-1] + "." + tld
 }
@@ -281,6 +281,18 @@ func TestExtractKeywordsFromDomains(t *testing.T) {
 			domains:  []string{"UPPERCASE.COM"},
 			expected: []string{"uppercase"},
 		},
+
+		// Internationalised domains
+		{
+			name:     "unicode domain",
+			domains:  []string{"shop.Bücher.de"},
+			expected: []string{"bücher"},
+		},
+		{
+			name:     "punycode domain",
+			domains:  []string{"xn--bcher-kva.co.uk"},
+			expected: []string{"bücher"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -311,3 +323,44 @@ func stringSliceEqual(a, b []string) bool {
 
 	return reflect.DeepEqual(mapA, mapB)
 }
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct {
+		domain   string
+		expected string
+	}{
+		{"example.com", "example.com"},
+		{"api.example.com", "example.com"},
+		{"a.b.Example.co.uk", "example.co.uk"},
+		{"portal.nrega.gov.in", "nrega.gov.in"},
+		{"localhost", "localhost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			if got := RegistrableDomain(tt.domain); got != tt.expected {
+				t.Errorf("RegistrableDomain(%q) = %q, expected %q", tt.domain, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractBareDomain(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://example.com", "example.com"},
+		{"http://sub.example.com:8080", "sub.example.com"},
+		{"Example.COM./path", "example.com"},
+		{"not a domain!", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ExtractBareDomain(tt.input); got != tt.expected {
+				t.Errorf("ExtractBareDomain(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
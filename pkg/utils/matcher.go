@@ -0,0 +1,328 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Keyword rule kinds. A keyword's prefix selects its kind:
+//
+//	example      substring anywhere in the domain (default)
+//	=hp          whole label or hyphen/underscore separated token ("hp.com", "hp-cdn.net", not "php.net")
+//	/^api\d+\./  regular expression, case-insensitive
+//	~paypal      brand match tolerating typos and look-alike characters ("paypa1", "pay-pal", "paypall")
+//	!partner-    exclusion: domains matching the rule are rejected even if other keywords match
+const (
+	KeywordSubstring = "substring"
+	KeywordLabel     = "label"
+	KeywordRegex     = "regex"
+	KeywordFuzzy     = "fuzzy"
+)
+
+// keywordRule is a parsed keyword
+type keywordRule struct {
+	raw     string
+	kind    string
+	value   string // Lowercase keyword without its prefix (the folded skeleton for fuzzy rules)
+	exclude bool
+	pattern *regexp.Regexp // Regex and label rules
+	err     error          // Set when the keyword is invalid; invalid rules never match
+}
+
+// KeywordMatch explains why a domain was accepted or rejected by a set of keywords
+type KeywordMatch struct {
+	Accepted bool
+	Keyword  string // Keyword that decided the outcome; empty when none did
+	Kind     string // Kind of that keyword
+	Reason   string
+}
+
+// Matcher matches domains against a set of keywords parsed once, so regexes aren't
+// recompiled for every discovered domain. A nil Matcher accepts every domain but wildcards.
+type Matcher struct {
+	includes []*keywordRule
+	excludes []*keywordRule
+}
+
+// NewMatcher parses keywords (see ExplainKeywords for the syntax), ignoring empty ones.
+// Returns an error for the first invalid keyword, e.g. a regex that doesn't compile.
+func NewMatcher(keywords []string) (*Matcher, error) {
+	matcher, err := newMatcher(keywords)
+	if err != nil {
+		return nil, err
+	}
+	return matcher, nil
+}
+
+// newMatcher parses keywords, skipping invalid ones and returning the first error
+func newMatcher(keywords []string) (*Matcher, error) {
+	matcher := &Matcher{}
+	var firstErr error
+	for _, keyword := range keywords {
+		if keyword == "" {
+			continue
+		}
+		rule := parseKeyword(keyword)
+		switch {
+		case rule.err != nil:
+			if firstErr == nil {
+				firstErr = rule.err
+			}
+		case rule.exclude:
+			matcher.excludes = append(matcher.excludes, rule)
+		default:
+			matcher.includes = append(matcher.includes, rule)
+		}
+	}
+	return matcher, firstErr
+}
+
+// parseKeyword parses a keyword
+func parseKeyword(raw string) *keywordRule {
+	rule := &keywordRule{raw: raw, kind: KeywordSubstring}
+	value := strings.TrimSpace(raw)
+	if strings.HasPrefix(value, "!") {
+		rule.exclude = true
+		value = value[1:]
+	}
+
+	switch {
+	case len(value) >= 2 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/"):
+		rule.kind = KeywordRegex
+		rule.value = value[1 : len(value)-1]
+		rule.pattern, rule.err = regexp.Compile("(?i)" + rule.value)
+		if rule.err != nil {
+			rule.err = fmt.Errorf("invalid keyword %q: %w", raw, rule.err)
+		}
+	case strings.HasPrefix(value, "="):
+		rule.kind = KeywordLabel
+		rule.value = strings.ToLower(value[1:])
+		rule.pattern = regexp.MustCompile(`(^|[._-])` + regexp.QuoteMeta(rule.value) + `($|[._-])`)
+	case strings.HasPrefix(value, "~"):
+		rule.kind = KeywordFuzzy
+		rule.value = foldHomoglyphs(strings.ToLower(value[1:]))
+	default:
+		rule.value = strings.ToLower(value)
+	}
+	if rule.err == nil && rule.value == "" {
+		rule.err = fmt.Errorf("invalid keyword %q: empty %s keyword", raw, rule.kind)
+	}
+	return rule
+}
+
+// ValidateKeywords checks that every keyword parses, e.g. that regex keywords compile
+func ValidateKeywords(keywords []string) error {
+	_, err := newMatcher(keywords)
+	return err
+}
+
+// SplitKeywords splits comma-separated keyword lists such as "staging,prod", trimming spaces
+// and dropping empty keywords. Commas inside /regex/ keywords ("/^a{1,3}\./") don't split.
+func SplitKeywords(values []string) []string {
+	var keywords []string
+	for _, value := range values {
+		for rest := value; rest != ""; {
+			end := keywordEnd(rest)
+			if keyword := strings.TrimSpace(rest[:end]); keyword != "" {
+				keywords = append(keywords, keyword)
+			}
+			if end == len(rest) {
+				break
+			}
+			rest = rest[end+1:]
+		}
+	}
+	return keywords
+}
+
+// keywordEnd returns the index of the comma ending the first keyword of s, or len(s).
+// A regex keyword runs to the first '/' followed by a comma or the end of s.
+func keywordEnd(s string) int {
+	trimmed := strings.TrimLeft(s, " \t")
+	if body := strings.TrimPrefix(trimmed, "!"); strings.HasPrefix(body, "/") {
+		for i := len(s) - len(body) + 1; i < len(s); i++ {
+			if s[i] != '/' {
+				continue
+			}
+			after := strings.TrimLeft(s[i+1:], " \t")
+			if after == "" {
+				return len(s)
+			}
+			if after[0] == ',' {
+				return len(s) - len(after)
+			}
+		}
+		// Unterminated regexes split at the next comma like plain keywords
+	}
+	if i := strings.IndexByte(s, ','); i >= 0 {
+		return i
+	}
+	return len(s)
+}
+
+// ExplainKeywords matches a domain against keywords and explains the outcome. Exclusions
+// are checked first; a domain is then accepted by the first keyword it matches. Without
+// any (non-exclusion) keywords every domain is accepted. Wildcard names are always rejected.
+// Internationalised domains match in either form: "xn--bcher-kva.example" matches "bücher"
+// and "bücher.example" matches "xn--bcher". Invalid keywords are ignored; use a Matcher
+// to match many domains against the same keywords.
+func ExplainKeywords(domain string, keywords []string) KeywordMatch {
+	matcher, _ := newMatcher(keywords)
+	return matcher.Explain(domain)
+}
+
+// Explain matches a domain against the keywords and explains the outcome (see ExplainKeywords)
+func (m *Matcher) Explain(domain string) KeywordMatch {
+	if strings.Contains(domain, "*") {
+		return KeywordMatch{Reason: "wildcard names are never in scope"}
+	}
+	if m == nil {
+		return KeywordMatch{Accepted: true, Reason: "no keywords specified"}
+	}
+	forms := domainForms(domain)
+
+	for _, rule := range m.excludes {
+		if detail, ok := rule.matchForms(forms); ok {
+			return KeywordMatch{Keyword: rule.raw, Kind: rule.kind, Reason: fmt.Sprintf(`excluded by "%s" (%s)`, rule.raw, detail)}
+		}
+	}
+
+	if len(m.includes) == 0 {
+		return KeywordMatch{Accepted: true, Reason: "no keywords specified"}
+	}
+	for _, rule := range m.includes {
+		if detail, ok := rule.matchForms(forms); ok {
+			return KeywordMatch{Accepted: true, Keyword: rule.raw, Kind: rule.kind, Reason: fmt.Sprintf(`matched "%s" (%s)`, rule.raw, detail)}
+		}
+	}
+	return KeywordMatch{Reason: fmt.Sprintf("matched none of %d keywords", len(m.includes))}
+}
+
+// Matches reports whether a domain is accepted by the keywords
+func (m *Matcher) Matches(domain string) bool {
+	return m.Explain(domain).Accepted
+}
+
+// matchForms reports whether any form of a domain matches the rule
+func (r *keywordRule) matchForms(forms []string) (string, bool) {
+	for _, form := range forms {
+		if detail, ok := r.match(form); ok {
+			return detail, true
+		}
+	}
+	return "", false
+}
+
+// match reports whether a lowercase domain matches the rule, with a description of the match
+func (r *keywordRule) match(domain string) (string, bool) {
+	switch r.kind {
+	case KeywordRegex:
+		if loc := r.pattern.FindStringIndex(domain); loc != nil {
+			return fmt.Sprintf(`regex matched "%s"`, domain[loc[0]:loc[1]]), true
+		}
+	case KeywordLabel:
+		if r.pattern.MatchString(domain) {
+			return fmt.Sprintf("label %q", r.value), true
+		}
+	case KeywordFuzzy:
+		return r.matchFuzzy(domain)
+	default:
+		if strings.Contains(domain, r.value) {
+			return fmt.Sprintf("substring %q", r.value), true
+		}
+	}
+	return "", false
+}
+
+// minFuzzyLength is the shortest brand and label compared by edit distance. Shorter ones
+// are an edit away from too many unrelated words, so they only match by containment.
+const minFuzzyLength = 5
+
+// matchFuzzy compares the brand against every label and label token of the domain after
+// folding look-alike characters, allowing a small edit distance
+func (r *keywordRule) matchFuzzy(domain string) (string, bool) {
+	maxDistance := 1
+	if utf8.RuneCountInString(r.value) >= 10 {
+		maxDistance = 2
+	}
+
+	for _, candidate := range fuzzyCandidates(domain) {
+		skeleton := foldHomoglyphs(candidate)
+		if strings.Contains(skeleton, r.value) {
+			return fmt.Sprintf("%q contains the brand", candidate), true
+		}
+		if utf8.RuneCountInString(r.value) < minFuzzyLength || utf8.RuneCountInString(skeleton) < minFuzzyLength {
+			continue
+		}
+		if distance := editDistance(skeleton, r.value); distance <= maxDistance {
+			return fmt.Sprintf("%q is %d edit(s) from the brand", candidate, distance), true
+		}
+	}
+	return "", false
+}
+
+// fuzzyCandidates returns the labels of a domain, their hyphen/underscore separated
+// tokens, and each label with its separators removed ("pay-pal" -> "paypal")
+func fuzzyCandidates(domain string) []string {
+	var candidates []string
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" {
+			continue
+		}
+		candidates = append(candidates, label)
+		tokens := strings.FieldsFunc(label, func(r rune) bool { return r == '-' || r == '_' })
+		if len(tokens) > 1 {
+			candidates = append(candidates, tokens...)
+			candidates = append(candidates, strings.Join(tokens, ""))
+		}
+	}
+	return candidates
+}
+
+// homoglyphs maps characters commonly substituted for look-alikes to a canonical form
+var homoglyphs = map[rune]rune{
+	'0': 'o', '1': 'l', 'i': 'l', '|': 'l', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b', '9': 'g',
+	// Cyrillic and Greek letters rendered like Latin ones
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'х': 'x', 'у': 'y', 'і': 'l', 'ј': 'j',
+	'ԁ': 'd', 'ѕ': 's', 'ԛ': 'q', 'ԝ': 'w', 'ɡ': 'g', 'ο': 'o', 'α': 'a', 'ν': 'v', 'ι': 'l',
+	'κ': 'k', 'τ': 't', 'ρ': 'p', 'ε': 'e',
+}
+
+// homoglyphSequences are multi-character look-alikes, folded before single characters
+var homoglyphSequences = strings.NewReplacer("rn", "m", "vv", "w", "cl", "d")
+
+// foldHomoglyphs reduces a string to a skeleton in which look-alike spellings are equal,
+// e.g. "paypa1", "pаypal" (Cyrillic а) and "paypal" all fold to "paypal"
+func foldHomoglyphs(s string) string {
+	s = homoglyphSequences.Replace(s)
+	return strings.Map(func(r rune) rune {
+		if folded, ok := homoglyphs[r]; ok {
+			return folded
+		}
+		return r
+	}, s)
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
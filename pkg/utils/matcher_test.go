@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		domain   string
+		keywords []string
+		accepted bool
+		keyword  string
+	}{
+		{name: "no keywords accept all", domain: "anything.net", keywords: nil, accepted: true},
+		{name: "wildcards rejected", domain: "*.example.com", keywords: nil, accepted: false},
+		{name: "substring", domain: "shop.example.com", keywords: []string{"Example"}, accepted: true, keyword: "Example"},
+		{name: "substring matches inside words", domain: "php.example.com", keywords: []string{"hp"}, accepted: true, keyword: "hp"},
+		{name: "label boundary rejects inside words", domain: "php.example.com", keywords: []string{"=hp"}, accepted: false},
+		{name: "label boundary whole label", domain: "support.hp.com", keywords: []string{"=hp"}, accepted: true, keyword: "=hp"},
+		{name: "label boundary hyphen token", domain: "hp-cdn.net", keywords: []string{"=hp"}, accepted: true, keyword: "=hp"},
+		{name: "regex", domain: "api12.example.com", keywords: []string{`/^api\d+\./`}, accepted: true, keyword: `/^api\d+\./`},
+		{name: "regex no match", domain: "api.example.com", keywords: []string{`/^api\d+\./`}, accepted: false},
+		{name: "regex case insensitive", domain: "VPN.Example.com", keywords: []string{`/^vpn\./`}, accepted: true, keyword: `/^vpn\./`},
+		{name: "exclusion wins", domain: "partner-portal.example.com", keywords: []string{"example", "!partner-"}, accepted: false, keyword: "!partner-"},
+		{name: "exclusion only", domain: "www.example.com", keywords: []string{"!partner-"}, accepted: true},
+		{name: "regex exclusion", domain: "test1.example.com", keywords: []string{"example", `!/^test\d/`}, accepted: false, keyword: `!/^test\d/`},
+		{name: "fuzzy exact", domain: "paypal.com", keywords: []string{"~paypal"}, accepted: true, keyword: "~paypal"},
+		{name: "fuzzy digit homoglyph", domain: "login.paypa1.com", keywords: []string{"~paypal"}, accepted: true, keyword: "~paypal"},
+		{name: "fuzzy cyrillic homoglyph", domain: "pаypal.com", keywords: []string{"~paypal"}, accepted: true, keyword: "~paypal"},
+		{name: "fuzzy hyphenated", domain: "pay-pal-secure.net", keywords: []string{"~paypal"}, accepted: true, keyword: "~paypal"},
+		{name: "fuzzy typo", domain: "paypall.com", keywords: []string{"~paypal"}, accepted: true, keyword: "~paypal"},
+		{name: "fuzzy rn for m", domain: "rnicrosoft-login.com", keywords: []string{"~microsoft"}, accepted: true, keyword: "~microsoft"},
+		{name: "fuzzy unrelated", domain: "playpen.org", keywords: []string{"~paypal"}, accepted: false},
+		{name: "fuzzy short brand needs containment", domain: "acne.org", keywords: []string{"~acme"}, accepted: false},
+		{name: "fuzzy short brand contained", domain: "acme-login.net", keywords: []string{"~acme"}, accepted: true, keyword: "~acme"},
+		{name: "fuzzy short label", domain: "ap.appl.io", keywords: []string{"~apple"}, accepted: false},
+		{name: "unicode keyword matches punycode", domain: "shop.xn--bcher-kva.example", keywords: []string{"bücher"}, accepted: true, keyword: "bücher"},
+		{name: "punycode keyword matches unicode", domain: "shop.bücher.example", keywords: []string{"xn--bcher"}, accepted: true, keyword: "xn--bcher"},
+		{name: "unicode exclusion on punycode", domain: "xn--bcher-kva.example", keywords: []string{"example", "!=bücher"}, accepted: false, keyword: "!=bücher"},
+		{name: "fuzzy mixed-script punycode", domain: "xn--pypal-4ve.com", keywords: []string{"~paypal"}, accepted: true, keyword: "~paypal"},
+		{name: "invalid keywords ignored", domain: "example.com", keywords: []string{"/[/", "example"}, accepted: true, keyword: "example"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExplainKeywords(tt.domain, tt.keywords)
+			if got.Accepted != tt.accepted || got.Keyword != tt.keyword {
+				t.Errorf("ExplainKeywords(%q, %v) = %+v, want accepted=%t keyword=%q", tt.domain, tt.keywords, got, tt.accepted, tt.keyword)
+			}
+			if got.Reason == "" {
+				t.Error("Expected an explanation")
+			}
+			if MatchesKeywords(tt.domain, tt.keywords) != tt.accepted {
+				t.Errorf("MatchesKeywords disagrees with ExplainKeywords for %q", tt.domain)
+			}
+		})
+	}
+}
+
+func TestExplainKeywordsReason(t *testing.T) {
+	match := ExplainKeywords("partner-portal.example.com", []string{"example", "!partner-"})
+	if !strings.Contains(match.Reason, `excluded by "!partner-"`) {
+		t.Errorf("Unexpected exclusion reason: %s", match.Reason)
+	}
+	match = ExplainKeywords("login.paypa1.com", []string{"~paypal"})
+	if match.Kind != KeywordFuzzy || !strings.Contains(match.Reason, `"paypa1"`) {
+		t.Errorf("Expected the fuzzy reason to name the matching label, got %+v", match)
+	}
+}
+
+func TestValidateKeywords(t *testing.T) {
+	if err := ValidateKeywords([]string{"example", "=hp", `/^api\d+/`, "~brand", "!partner-", ""}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	for _, invalid := range []string{"/[unclosed/", "!", "=", "~", "!//"} {
+		if err := ValidateKeywords([]string{invalid}); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestLoadKeywordsKeepsRegexCase(t *testing.T) {
+	keywords := LoadKeywords(nil, []string{`/^API\D/`, " Brand "})
+	found := make(map[string]bool)
+	for _, keyword := range keywords {
+		found[keyword] = true
+	}
+	if !found[`/^API\D/`] || !found["brand"] {
+		t.Errorf("Expected the regex keyword to keep its case and others to be lowercased, got %v", keywords)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"paypal", "paypal", 0},
+		{"paypall", "paypal", 1},
+		{"paypa", "paypal", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	if _, err := NewMatcher([]string{"example", "/[unclosed/"}); err == nil {
+		t.Error("Expected an invalid keyword to be rejected")
+	}
+
+	matcher, err := NewMatcher([]string{"example", `/^api\d+\./`, "!partner-", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for domain, expected := range map[string]bool{
+		"www.example.com":            true,
+		"api7.other.net":             true,
+		"partner-portal.example.com": false,
+		"unrelated.org":              false,
+		"*.example.com":              false,
+	} {
+		if got := matcher.Matches(domain); got != expected {
+			t.Errorf("Matches(%q) = %t, want %t", domain, got, expected)
+		}
+	}
+
+	var none *Matcher
+	if !none.Matches("anything.net") || none.Matches("*.anything.net") {
+		t.Error("Expected a nil matcher to accept every domain but wildcards")
+	}
+}
+
+func TestSplitKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected []string
+	}{
+		{name: "comma separated", values: []string{"staging,prod"}, expected: []string{"staging", "prod"}},
+		{name: "repeated and spaced", values: []string{"api", " admin , =hp,", ""}, expected: []string{"api", "admin", "=hp"}},
+		{name: "regex with commas", values: []string{`/^a{1,3}\./`}, expected: []string{`/^a{1,3}\./`}},
+		{name: "regex within a list", values: []string{`staging,/^(dev|qa){1,2}-/,!/x,y/,prod`}, expected: []string{"staging", `/^(dev|qa){1,2}-/`, `!/x,y/`, "prod"}},
+		{name: "unterminated regex", values: []string{"/api,prod"}, expected: []string{"/api", "prod"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitKeywords(tt.values)
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") || len(got) != len(tt.expected) {
+				t.Errorf("SplitKeywords(%q) = %q, want %q", tt.values, got, tt.expected)
+			}
+		})
+	}
+}
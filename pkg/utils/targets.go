@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// MaxCIDRAddresses caps how many addresses a single CIDR seed may expand to (a /16 in IPv4)
+const MaxCIDRAddresses = 65536
+
+// ParseTargets reads scan targets from r, one per line.
+// Blank lines and '#' comments are skipped. Only the first whitespace or comma
+// separated field of each line is used, so output from other tools (e.g. massdns
+// or httpx) can be piped in directly. Targets are normalized and deduplicated.
+// Invalid targets are skipped and reported in the returned error with their line
+// number, alongside the valid targets.
+func ParseTargets(r io.Reader) ([]string, error) {
+	seen := make(map[string]bool)
+	var targets []string
+	var errs []error
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ','
+		})
+		if len(fields) == 0 {
+			continue
+		}
+		target, err := ParseTarget(fields[0])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", lineNumber, err))
+			continue
+		}
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		targets = append(targets, target)
+	}
+	if err := scanner.Err(); err != nil {
+		return targets, fmt.Errorf("failed to read targets: %w", err)
+	}
+	return targets, errors.Join(errs...)
+}
+
+// NormalizeTarget converts a raw target into a bare lowercase domain, IP address or CIDR range.
+// Hosts are parsed with ParseHost, so schemes, userinfo, ports, paths and trailing dots are
+// removed and internationalised domains are converted to their ASCII (punycode) form.
+// Returns "" for empty targets and hosts that aren't valid; use ParseTarget for the reason.
+func NormalizeTarget(target string) string {
+	normalized, _ := ParseTarget(target)
+	return normalized
+}
+
+// ParseTarget is NormalizeTarget returning why a target isn't valid
+func ParseTarget(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if prefix, err := netip.ParsePrefix(target); err == nil {
+		return prefix.Masked().String(), nil
+	}
+	if addr, err := netip.ParseAddr(target); err == nil {
+		return addr.String(), nil
+	}
+	host, err := ParseHost(target)
+	if err != nil {
+		return "", err
+	}
+	return host.Name, nil
+}
+
+// IsIPTarget reports whether target is an IP address or CIDR range rather than a domain
+func IsIPTarget(target string) bool {
+	if _, err := netip.ParsePrefix(target); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(target)
+	return err == nil
+}
+
+// SplitTargets separates domain targets from IP targets, expanding CIDR ranges into
+// individual addresses. Both result slices are deduplicated and keep input order.
+// Invalid targets are skipped and reported in the returned error; a CIDR range that
+// is too large stops the split.
+func SplitTargets(targets []string) (domains []string, ips []string, err error) {
+	seenDomains := make(map[string]bool)
+	seenIPs := make(map[string]bool)
+	var errs []error
+
+	for _, raw := range targets {
+		target, parseErr := ParseTarget(raw)
+		if parseErr != nil {
+			errs = append(errs, parseErr)
+			continue
+		}
+		if !IsIPTarget(target) {
+			if !seenDomains[target] {
+				seenDomains[target] = true
+				domains = append(domains, target)
+			}
+			continue
+		}
+		expanded, expandErr := ExpandCIDR(target)
+		if expandErr != nil {
+			return domains, ips, errors.Join(append(errs, expandErr)...)
+		}
+		for _, ip := range expanded {
+			if !seenIPs[ip] {
+				seenIPs[ip] = true
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return domains, ips, errors.Join(errs...)
+}
+
+// ExpandCIDR returns every address in a CIDR range, or the address itself for a single IP.
+// Returns an error for ranges larger than MaxCIDRAddresses.
+func ExpandCIDR(cidr string) ([]string, error) {
+	if addr, err := netip.ParseAddr(cidr); err == nil {
+		return []string{addr.String()}, nil
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+	}
+	prefix = prefix.Masked()
+
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits > 16 {
+		return nil, fmt.Errorf("CIDR %s is too large: at most %d addresses per range", cidr, MaxCIDRAddresses)
+	}
+
+	ips := make([]string, 0, 1<<hostBits)
+	for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+		ips = append(ips, addr.String())
+		if !addr.Next().IsValid() {
+			break
+		}
+	}
+	return ips, nil
+}
+
+// ContainingCIDR returns the network of the given prefix length that contains ip.
+// IPv6 addresses are returned as a single-address range since their networks are too large to sweep.
+func ContainingCIDR(ip string, prefixLen int) (string, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "", fmt.Errorf("invalid IP %q: %w", ip, err)
+	}
+	addr = addr.Unmap()
+	if !addr.Is4() {
+		prefixLen = addr.BitLen()
+	}
+	prefix, err := addr.Prefix(prefixLen)
+	if err != nil {
+		return "", fmt.Errorf("invalid prefix length %d: %w", prefixLen, err)
+	}
+	return prefix.String(), nil
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	input := `# asset list
+example.com
+https://API.example.com:8443/login
+sub.example.com. A 192.0.2.10
+
+example.com
+192.0.2.1
+198.51.100.0/30, from-scanner
+`
+	expected := []string{
+		"example.com",
+		"api.example.com",
+		"sub.example.com",
+		"192.0.2.1",
+		"198.51.100.0/30",
+	}
+
+	targets, err := ParseTargets(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseTargets() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("ParseTargets() = %v, expected %v", targets, expected)
+	}
+}
+
+func TestParseTargetsInvalid(t *testing.T) {
+	targets, err := ParseTargets(strings.NewReader("example.com\n\nexample..com\n# comment\nhttps://:8080/\napi.example.com\n"))
+	if !reflect.DeepEqual(targets, []string{"example.com", "api.example.com"}) {
+		t.Errorf("Expected the valid targets to be kept, got %v", targets)
+	}
+	if err == nil || !strings.Contains(err.Error(), "line 3: ") || !strings.Contains(err.Error(), "line 5: ") || strings.Contains(err.Error(), "line 6") {
+		t.Errorf("Expected errors for lines 3 and 5, got %v", err)
+	}
+}
+
+func TestNormalizeTarget(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Example.COM", "example.com"},
+		{"http://example.com/path", "example.com"},
+		{"example.com.", "example.com"},
+		{"https://Bücher.example/", "xn--bcher-kva.example"},
+		{"XN--BCHER-KVA.example", "xn--bcher-kva.example"},
+		{"_dmarc.example.com", "_dmarc.example.com"},
+		{"192.0.2.1", "192.0.2.1"},
+		{"192.0.2.77/24", "192.0.2.0/24"},
+		{"2001:db8::1", "2001:db8::1"},
+		{"  ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := NormalizeTarget(tt.input); got != tt.expected {
+				t.Errorf("NormalizeTarget(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitTargets(t *testing.T) {
+	domains, ips, err := SplitTargets([]string{"example.com", "192.0.2.0/31", "192.0.2.1", "Example.com", "2001:db8::/127"})
+	if err != nil {
+		t.Fatalf("SplitTargets() returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(domains, []string{"example.com"}) {
+		t.Errorf("Expected domains [example.com], got %v", domains)
+	}
+	expectedIPs := []string{"192.0.2.0", "192.0.2.1", "2001:db8::", "2001:db8::1"}
+	if !reflect.DeepEqual(ips, expectedIPs) {
+		t.Errorf("Expected IPs %v, got %v", expectedIPs, ips)
+	}
+}
+
+func TestSplitTargetsInvalid(t *testing.T) {
+	domains, _, err := SplitTargets([]string{"example.com", "not a domain!", "api.example.com", ""})
+	if !reflect.DeepEqual(domains, []string{"example.com", "api.example.com"}) {
+		t.Errorf("Expected the valid domains to be kept, got %v", domains)
+	}
+	if err == nil || !strings.Contains(err.Error(), `"not a domain!"`) || !strings.Contains(err.Error(), "empty host") {
+		t.Errorf("Expected errors for both invalid targets, got %v", err)
+	}
+}
+
+func TestExpandCIDR(t *testing.T) {
+	tests := []struct {
+		name        string
+		cidr        string
+		expectCount int
+		expectError bool
+	}{
+		{name: "single IP", cidr: "192.0.2.5", expectCount: 1},
+		{name: "slash 24", cidr: "192.0.2.0/24", expectCount: 256},
+		{name: "slash 32", cidr: "192.0.2.9/32", expectCount: 1},
+		{name: "slash 16 at limit", cidr: "10.1.0.0/16", expectCount: 65536},
+		{name: "slash 15 too large", cidr: "10.0.0.0/15", expectError: true},
+		{name: "IPv6 slash 64 too large", cidr: "2001:db8::/64", expectError: true},
+		{name: "end of address space", cidr: "255.255.255.254/31", expectCount: 2},
+		{name: "invalid", cidr: "not-a-cidr", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ips, err := ExpandCIDR(tt.cidr)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(ips) != tt.expectCount {
+				t.Errorf("Expected %d addresses, got %d", tt.expectCount, len(ips))
+			}
+		})
+	}
+}
+
+func TestContainingCIDR(t *testing.T) {
+	tests := []struct {
+		ip          string
+		prefixLen   int
+		expected    string
+		expectError bool
+	}{
+		{ip: "192.0.2.77", prefixLen: 24, expected: "192.0.2.0/24"},
+		{ip: "192.0.2.77", prefixLen: 32, expected: "192.0.2.77/32"},
+		{ip: "10.20.30.40", prefixLen: 16, expected: "10.20.0.0/16"},
+		{ip: "2001:db8::1", prefixLen: 24, expected: "2001:db8::1/128"},
+		{ip: "not-an-ip", prefixLen: 24, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			got, err := ContainingCIDR(tt.ip, tt.prefixLen)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("ContainingCIDR(%q, %d) = %q, expected %q", tt.ip, tt.prefixLen, got, tt.expected)
+			}
+		})
+	}
+}